@@ -0,0 +1,107 @@
+package app
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"time"
+
+	"github.com/opensourceways/defect-manager/auth/domain"
+	"github.com/opensourceways/defect-manager/auth/domain/oidc"
+	"github.com/opensourceways/defect-manager/auth/domain/repository"
+)
+
+const tokenPrefix = "dm_"
+
+var (
+	ErrInvalidToken = errors.New("invalid token")
+	ErrOIDCDisabled = errors.New("oidc authentication is not enabled")
+)
+
+type AuthService interface {
+	AuthenticateByToken(token string) (domain.Principal, error)
+	AuthenticateByJWT(jwt string) (domain.Principal, error)
+	CreateToken(CmdToCreateToken) (TokenDTO, error)
+	RevokeToken(name string) error
+	AddAuditLog(CmdToAddAuditLog) error
+}
+
+func NewAuthService(
+	t repository.TokenRepository,
+	a repository.AuditRepository,
+	v oidc.Verifier,
+) *authService {
+	return &authService{
+		token:    t,
+		audit:    a,
+		verifier: v,
+	}
+}
+
+type authService struct {
+	token    repository.TokenRepository
+	audit    repository.AuditRepository
+	verifier oidc.Verifier
+}
+
+func (s authService) AuthenticateByToken(token string) (domain.Principal, error) {
+	t, err := s.token.FindToken(domain.HashToken(token))
+	if err != nil {
+		return domain.Principal{}, err
+	}
+
+	if !t.IsValid() {
+		return domain.Principal{}, ErrInvalidToken
+	}
+
+	return t.ToPrincipal(), nil
+}
+
+func (s authService) AuthenticateByJWT(jwt string) (domain.Principal, error) {
+	if s.verifier == nil {
+		return domain.Principal{}, ErrOIDCDisabled
+	}
+
+	return s.verifier.Verify(jwt)
+}
+
+func (s authService) CreateToken(cmd CmdToCreateToken) (dto TokenDTO, err error) {
+	b := make([]byte, 32)
+	if _, err = rand.Read(b); err != nil {
+		return
+	}
+
+	raw := tokenPrefix + hex.EncodeToString(b)
+
+	t := domain.Token{
+		Name:   cmd.Name,
+		Owner:  cmd.Owner,
+		Hash:   domain.HashToken(raw),
+		Scopes: cmd.Scopes,
+	}
+	if cmd.ValidDays > 0 {
+		t.ExpiresAt = time.Now().AddDate(0, 0, cmd.ValidDays)
+	}
+
+	if err = s.token.AddToken(&t); err != nil {
+		return
+	}
+
+	return toTokenDTO(&t, raw), nil
+}
+
+func (s authService) RevokeToken(name string) error {
+	return s.token.RevokeToken(name)
+}
+
+func (s authService) AddAuditLog(cmd CmdToAddAuditLog) error {
+	log := domain.AuditLog{
+		Operator:  cmd.Operator.Name,
+		Source:    cmd.Operator.Source,
+		Action:    cmd.Action,
+		Target:    cmd.Target,
+		CreatedAt: time.Now(),
+	}
+
+	return s.audit.AddAuditLog(&log)
+}
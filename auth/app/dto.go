@@ -0,0 +1,49 @@
+package app
+
+import (
+	"time"
+
+	"github.com/opensourceways/defect-manager/auth/domain"
+	"github.com/opensourceways/defect-manager/auth/domain/dp"
+)
+
+type CmdToCreateToken struct {
+	Name      string
+	Owner     string
+	Scopes    []dp.Scope
+	ValidDays int
+}
+
+type CmdToAddAuditLog struct {
+	Operator domain.Principal
+	Action   string
+	Target   string
+}
+
+type TokenDTO struct {
+	Name      string   `json:"name"`
+	Owner     string   `json:"owner"`
+	Token     string   `json:"token"`
+	Scopes    []string `json:"scopes"`
+	ExpiresAt string   `json:"expires_at"`
+}
+
+func toTokenDTO(t *domain.Token, raw string) TokenDTO {
+	scopes := make([]string, len(t.Scopes))
+	for k, v := range t.Scopes {
+		scopes[k] = v.String()
+	}
+
+	var expiresAt string
+	if !t.ExpiresAt.IsZero() {
+		expiresAt = t.ExpiresAt.Format(time.RFC3339)
+	}
+
+	return TokenDTO{
+		Name:      t.Name,
+		Owner:     t.Owner,
+		Token:     raw,
+		Scopes:    scopes,
+		ExpiresAt: expiresAt,
+	}
+}
@@ -0,0 +1,115 @@
+package controller
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/opensourceways/server-common-lib/controller"
+	"github.com/sirupsen/logrus"
+
+	"github.com/opensourceways/defect-manager/auth/app"
+	"github.com/opensourceways/defect-manager/auth/domain"
+	"github.com/opensourceways/defect-manager/auth/domain/dp"
+)
+
+const (
+	headerPrivateToken  = "PRIVATE-TOKEN"
+	headerAuthorization = "Authorization"
+	bearerPrefix        = "Bearer "
+
+	keyPrincipal = "principal"
+
+	errorUnauthorized = "unauthorized"
+	errorForbidden    = "forbidden"
+)
+
+type AuthMiddleware struct {
+	service app.AuthService
+}
+
+func NewAuthMiddleware(s app.AuthService) *AuthMiddleware {
+	return &AuthMiddleware{
+		service: s,
+	}
+}
+
+// Require authenticates the caller by the PRIVATE-TOKEN header or a bearer jwt,
+// and rejects the request if the caller does not have the scope
+func (m *AuthMiddleware) Require(scope dp.Scope) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		principal, err := m.authenticate(ctx)
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusUnauthorized, controller.ResponseData{
+				Code: errorUnauthorized,
+				Msg:  err.Error(),
+			})
+
+			return
+		}
+
+		if !principal.HasScope(scope) {
+			ctx.AbortWithStatusJSON(http.StatusForbidden, controller.ResponseData{
+				Code: errorForbidden,
+				Msg:  fmt.Sprintf("scope %s is required", scope.String()),
+			})
+
+			return
+		}
+
+		ctx.Set(keyPrincipal, principal)
+
+		ctx.Next()
+	}
+}
+
+// Audit records the operation of the caller authenticated by Require
+func (m *AuthMiddleware) Audit(ctx *gin.Context, action, target string) {
+	principal, ok := GetPrincipal(ctx)
+	if !ok {
+		logrus.Errorf("audit %s of %s without principal", action, target)
+
+		return
+	}
+
+	cmd := app.CmdToAddAuditLog{
+		Operator: principal,
+		Action:   action,
+		Target:   target,
+	}
+	if err := m.service.AddAuditLog(cmd); err != nil {
+		logrus.Errorf("add audit log of %s %s by %s error: %s", action, target, principal.Name, err.Error())
+	}
+}
+
+func (m *AuthMiddleware) authenticate(ctx *gin.Context) (domain.Principal, error) {
+	if token := ctx.GetHeader(headerPrivateToken); token != "" {
+		principal, err := m.service.AuthenticateByToken(token)
+		if err != nil {
+			logrus.Debugf("authenticate by token error: %s", err.Error())
+
+			return principal, app.ErrInvalidToken
+		}
+
+		return principal, nil
+	}
+
+	if v := ctx.GetHeader(headerAuthorization); strings.HasPrefix(v, bearerPrefix) {
+		return m.service.AuthenticateByJWT(strings.TrimPrefix(v, bearerPrefix))
+	}
+
+	return domain.Principal{}, errors.New("missing credential")
+}
+
+func GetPrincipal(ctx *gin.Context) (domain.Principal, bool) {
+	v, ok := ctx.Get(keyPrincipal)
+	if !ok {
+		return domain.Principal{}, false
+	}
+
+	principal, ok := v.(domain.Principal)
+
+	return principal, ok
+}
@@ -0,0 +1,25 @@
+package domain
+
+import "time"
+
+const (
+	AuditActionGenerateBulletin = "generate_bulletin"
+	AuditActionUpdateDefect     = "update_defect"
+	AuditActionDeleteDefect     = "delete_defect"
+	AuditActionAddVersion       = "add_version"
+	AuditActionUpdateVersion    = "update_version"
+	AuditActionRetireVersion    = "retire_version"
+	AuditActionNotifyBulletin   = "notify_bulletin"
+	AuditActionPauseSchedule    = "pause_schedule"
+	AuditActionResumeSchedule   = "resume_schedule"
+	AuditActionSaveMapping      = "save_component_mapping"
+	AuditActionDeleteMapping    = "delete_component_mapping"
+)
+
+type AuditLog struct {
+	Operator  string
+	Source    string
+	Action    string
+	Target    string
+	CreatedAt time.Time
+}
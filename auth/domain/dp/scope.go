@@ -0,0 +1,39 @@
+package dp
+
+import "errors"
+
+const (
+	read     = "read"
+	generate = "generate"
+	admin    = "admin"
+)
+
+var (
+	validScope = map[string]bool{
+		read:     true,
+		generate: true,
+		admin:    true,
+	}
+
+	ScopeRead     = scope(read)
+	ScopeGenerate = scope(generate)
+	ScopeAdmin    = scope(admin)
+)
+
+type scope string
+
+type Scope interface {
+	String() string
+}
+
+func NewScope(s string) (Scope, error) {
+	if !validScope[s] {
+		return nil, errors.New("invalid scope")
+	}
+
+	return scope(s), nil
+}
+
+func (s scope) String() string {
+	return string(s)
+}
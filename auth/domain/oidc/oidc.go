@@ -0,0 +1,7 @@
+package oidc
+
+import "github.com/opensourceways/defect-manager/auth/domain"
+
+type Verifier interface {
+	Verify(jwt string) (domain.Principal, error)
+}
@@ -0,0 +1,7 @@
+package repository
+
+import "github.com/opensourceways/defect-manager/auth/domain"
+
+type AuditRepository interface {
+	AddAuditLog(*domain.AuditLog) error
+}
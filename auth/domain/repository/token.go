@@ -0,0 +1,9 @@
+package repository
+
+import "github.com/opensourceways/defect-manager/auth/domain"
+
+type TokenRepository interface {
+	AddToken(*domain.Token) error
+	FindToken(hash string) (domain.Token, error)
+	RevokeToken(name string) error
+}
@@ -0,0 +1,64 @@
+package domain
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"time"
+
+	"github.com/opensourceways/defect-manager/auth/domain/dp"
+)
+
+const (
+	PrincipalSourceToken = "token"
+	PrincipalSourceOIDC  = "oidc"
+)
+
+// Token is a static api token, only the hash of the token is persisted
+type Token struct {
+	Name      string
+	Owner     string
+	Hash      string
+	Scopes    []dp.Scope
+	ExpiresAt time.Time
+	Revoked   bool
+}
+
+func (t Token) IsValid() bool {
+	if t.Revoked {
+		return false
+	}
+
+	return t.ExpiresAt.IsZero() || time.Now().Before(t.ExpiresAt)
+}
+
+func (t Token) ToPrincipal() Principal {
+	return Principal{
+		Name:   t.Owner,
+		Source: PrincipalSourceToken,
+		Scopes: t.Scopes,
+	}
+}
+
+// Principal is the caller of the api, authenticated by token or oidc
+type Principal struct {
+	Name   string
+	Source string
+	Scopes []dp.Scope
+}
+
+// HasScope admin scope implies all the other scopes
+func (p Principal) HasScope(s dp.Scope) bool {
+	for _, v := range p.Scopes {
+		if v == s || v == dp.ScopeAdmin {
+			return true
+		}
+	}
+
+	return false
+}
+
+func HashToken(token string) string {
+	h := sha256.Sum256([]byte(token))
+
+	return hex.EncodeToString(h[:])
+}
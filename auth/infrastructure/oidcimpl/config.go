@@ -0,0 +1,44 @@
+package oidcimpl
+
+import "errors"
+
+// Config JWKSURL: discovered from the issuer when it is empty, JWKSCacheTTL: the unit is minute
+type Config struct {
+	Issuer        string `json:"issuer"`
+	Audience      string `json:"audience"`
+	JWKSURL       string `json:"jwks_url"`
+	ScopeClaim    string `json:"scope_claim"`
+	ScopePrefix   string `json:"scope_prefix"`
+	UsernameClaim string `json:"username_claim"`
+	JWKSCacheTTL  int    `json:"jwks_cache_ttl"`
+}
+
+func (c *Config) enabled() bool {
+	return c.Issuer != ""
+}
+
+func (c *Config) SetDefault() {
+	if !c.enabled() {
+		return
+	}
+
+	if c.ScopeClaim == "" {
+		c.ScopeClaim = "scope"
+	}
+
+	if c.UsernameClaim == "" {
+		c.UsernameClaim = "preferred_username"
+	}
+
+	if c.JWKSCacheTTL <= 0 {
+		c.JWKSCacheTTL = 60
+	}
+}
+
+func (c *Config) Validate() error {
+	if c.enabled() && c.Audience == "" {
+		return errors.New("missing audience of oidc")
+	}
+
+	return nil
+}
@@ -0,0 +1,283 @@
+package oidcimpl
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash"
+	"math/big"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/opensourceways/server-common-lib/utils"
+	"github.com/sirupsen/logrus"
+
+	"github.com/opensourceways/defect-manager/auth/domain"
+	"github.com/opensourceways/defect-manager/auth/domain/dp"
+	"github.com/opensourceways/defect-manager/auth/domain/oidc"
+)
+
+// refreshing keys for unknown kid is limited to avoid being abused by forged tokens
+const minRefreshInterval = time.Minute
+
+var instance *oidcImpl
+
+func Init(cfg *Config) {
+	if !cfg.enabled() {
+		return
+	}
+
+	instance = &oidcImpl{
+		cfg: cfg,
+		cli: utils.NewHttpClient(3),
+	}
+}
+
+// Instance return nil if the oidc is not configured
+func Instance() oidc.Verifier {
+	if instance == nil {
+		return nil
+	}
+
+	return instance
+}
+
+type oidcImpl struct {
+	cfg *Config
+	cli utils.HttpClient
+
+	lock      sync.RWMutex
+	keys      map[string]crypto.PublicKey
+	fetchedAt time.Time
+	// attemptedAt is when the keys were fetched lastly, whether it succeeded or not
+	attemptedAt time.Time
+}
+
+type header struct {
+	Alg string `json:"alg"`
+	Kid string `json:"kid"`
+}
+
+func (impl *oidcImpl) Verify(token string) (p domain.Principal, err error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		err = errors.New("malformed jwt")
+
+		return
+	}
+
+	var h header
+	if err = decodeSegment(parts[0], &h); err != nil {
+		return
+	}
+
+	key, err := impl.key(h.Kid)
+	if err != nil {
+		return
+	}
+
+	sig, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return
+	}
+
+	if err = verifySignature(h.Alg, key, parts[0]+"."+parts[1], sig); err != nil {
+		return
+	}
+
+	claims := make(map[string]interface{})
+	if err = decodeSegment(parts[1], &claims); err != nil {
+		return
+	}
+
+	if err = impl.validateClaims(claims); err != nil {
+		return
+	}
+
+	return impl.toPrincipal(claims), nil
+}
+
+func (impl *oidcImpl) key(kid string) (crypto.PublicKey, error) {
+	impl.lock.RLock()
+	key, ok := impl.keys[kid]
+	expired := time.Since(impl.fetchedAt) > time.Duration(impl.cfg.JWKSCacheTTL)*time.Minute
+	impl.lock.RUnlock()
+
+	if ok && !expired {
+		return key, nil
+	}
+
+	impl.lock.Lock()
+	defer impl.lock.Unlock()
+
+	if time.Since(impl.attemptedAt) > minRefreshInterval || impl.keys == nil {
+		impl.attemptedAt = time.Now()
+
+		keys, err := impl.fetchKeys()
+		if err != nil {
+			// the cached key is still trusted when the idp is unavailable
+			if key, ok = impl.keys[kid]; ok {
+				logrus.Warnf("refresh jwks error: %s, use the cached key %s", err.Error(), kid)
+
+				return key, nil
+			}
+
+			return nil, err
+		}
+
+		impl.keys = keys
+		impl.fetchedAt = impl.attemptedAt
+	}
+
+	if key, ok = impl.keys[kid]; !ok {
+		return nil, fmt.Errorf("unknown signing key %s", kid)
+	}
+
+	return key, nil
+}
+
+func (impl *oidcImpl) validateClaims(claims map[string]interface{}) error {
+	now := time.Now().Unix()
+
+	exp, ok := claims["exp"].(float64)
+	if !ok || int64(exp) <= now {
+		return errors.New("jwt is expired")
+	}
+
+	if nbf, ok := claims["nbf"].(float64); ok && int64(nbf) > now {
+		return errors.New("jwt is not valid yet")
+	}
+
+	if iss, _ := claims["iss"].(string); iss != impl.cfg.Issuer {
+		return errors.New("invalid issuer of jwt")
+	}
+
+	if !hasAudience(claims["aud"], impl.cfg.Audience) {
+		return errors.New("invalid audience of jwt")
+	}
+
+	return nil
+}
+
+func (impl *oidcImpl) toPrincipal(claims map[string]interface{}) domain.Principal {
+	name, _ := claims[impl.cfg.UsernameClaim].(string)
+	if name == "" {
+		name, _ = claims["sub"].(string)
+	}
+
+	var values []string
+	switch v := claims[impl.cfg.ScopeClaim].(type) {
+	case string:
+		values = strings.Fields(v)
+	case []interface{}:
+		for _, item := range v {
+			if s, ok := item.(string); ok {
+				values = append(values, s)
+			}
+		}
+	}
+
+	var scopes []dp.Scope
+	for _, v := range values {
+		if !strings.HasPrefix(v, impl.cfg.ScopePrefix) {
+			continue
+		}
+
+		if s, err := dp.NewScope(strings.TrimPrefix(v, impl.cfg.ScopePrefix)); err == nil {
+			scopes = append(scopes, s)
+		}
+	}
+
+	return domain.Principal{
+		Name:   name,
+		Source: domain.PrincipalSourceOIDC,
+		Scopes: scopes,
+	}
+}
+
+func hasAudience(aud interface{}, expect string) bool {
+	switch v := aud.(type) {
+	case string:
+		return v == expect
+	case []interface{}:
+		for _, item := range v {
+			if s, ok := item.(string); ok && s == expect {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+func decodeSegment(seg string, v interface{}) error {
+	b, err := base64.RawURLEncoding.DecodeString(seg)
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(b, v)
+}
+
+func verifySignature(alg string, key crypto.PublicKey, signed string, sig []byte) error {
+	if len(alg) != 5 {
+		return fmt.Errorf("unsupported alg %s", alg)
+	}
+
+	var h hash.Hash
+	var hashType crypto.Hash
+
+	switch alg[2:] {
+	case "256":
+		h, hashType = sha256.New(), crypto.SHA256
+	case "384":
+		h, hashType = sha512.New384(), crypto.SHA384
+	case "512":
+		h, hashType = sha512.New(), crypto.SHA512
+	default:
+		return fmt.Errorf("unsupported alg %s", alg)
+	}
+
+	h.Write([]byte(signed))
+	digest := h.Sum(nil)
+
+	switch {
+	case strings.HasPrefix(alg, "RS"):
+		pub, ok := key.(*rsa.PublicKey)
+		if !ok {
+			return errors.New("key type does not match alg")
+		}
+
+		return rsa.VerifyPKCS1v15(pub, hashType, digest, sig)
+
+	case strings.HasPrefix(alg, "ES"):
+		pub, ok := key.(*ecdsa.PublicKey)
+		if !ok {
+			return errors.New("key type does not match alg")
+		}
+
+		// the signature is r and s padded to the size of curve
+		curveBytes := (pub.Curve.Params().BitSize + 7) / 8
+		if len(sig) != 2*curveBytes {
+			return errors.New("invalid signature length")
+		}
+
+		r := new(big.Int).SetBytes(sig[:curveBytes])
+		s := new(big.Int).SetBytes(sig[curveBytes:])
+		if !ecdsa.Verify(pub, digest, r, s) {
+			return errors.New("invalid signature")
+		}
+
+		return nil
+
+	default:
+		return fmt.Errorf("unsupported alg %s", alg)
+	}
+}
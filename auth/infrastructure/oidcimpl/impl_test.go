@@ -0,0 +1,191 @@
+package oidcimpl
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/opensourceways/defect-manager/auth/domain/dp"
+)
+
+const testKid = "test-key"
+
+func newTestVerifier(t *testing.T, key *rsa.PrivateKey) (*oidcImpl, func()) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		set := jwks{Keys: []jwk{{
+			Kid: testKid,
+			Kty: "RSA",
+			Use: "sig",
+			N:   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+			E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+		}}}
+
+		if err := json.NewEncoder(w).Encode(set); err != nil {
+			t.Error(err)
+		}
+	}))
+
+	cfg := &Config{
+		Issuer:      "https://idp.example.com",
+		Audience:    "defect-manager",
+		JWKSURL:     server.URL,
+		ScopePrefix: "defect:",
+	}
+	cfg.SetDefault()
+
+	Init(cfg)
+
+	return instance, server.Close
+}
+
+func signToken(t *testing.T, key *rsa.PrivateKey, claims map[string]interface{}) string {
+	enc := func(v interface{}) string {
+		b, err := json.Marshal(v)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		return base64.RawURLEncoding.EncodeToString(b)
+	}
+
+	signed := enc(map[string]string{"alg": "RS256", "kid": testKid}) + "." + enc(claims)
+	digest := sha256.Sum256([]byte(signed))
+
+	sig, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, digest[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return signed + "." + base64.RawURLEncoding.EncodeToString(sig)
+}
+
+func TestVerify(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	v, closeServer := newTestVerifier(t, key)
+	defer closeServer()
+
+	claims := func(modify func(map[string]interface{})) map[string]interface{} {
+		c := map[string]interface{}{
+			"iss":                "https://idp.example.com",
+			"aud":                []string{"defect-manager"},
+			"exp":                time.Now().Add(time.Hour).Unix(),
+			"sub":                "123",
+			"preferred_username": "alice",
+			"scope":              "openid defect:read defect:generate",
+		}
+		if modify != nil {
+			modify(c)
+		}
+
+		return c
+	}
+
+	p, err := v.Verify(signToken(t, key, claims(nil)))
+	if err != nil {
+		t.Fatalf("verify valid token error: %s", err.Error())
+	}
+
+	if p.Name != "alice" || !p.HasScope(dp.ScopeGenerate) || p.HasScope(dp.ScopeAdmin) {
+		t.Errorf("unexpected principal %+v", p)
+	}
+
+	invalid := map[string]func(map[string]interface{}){
+		"expired": func(c map[string]interface{}) {
+			c["exp"] = time.Now().Add(-time.Minute).Unix()
+		},
+		"issuer": func(c map[string]interface{}) {
+			c["iss"] = "https://other.example.com"
+		},
+		"audience": func(c map[string]interface{}) {
+			c["aud"] = "other"
+		},
+	}
+
+	for name, modify := range invalid {
+		if _, err := v.Verify(signToken(t, key, claims(modify))); err == nil {
+			t.Errorf("token with invalid %s is accepted", name)
+		}
+	}
+
+	other, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := v.Verify(signToken(t, other, claims(nil))); err == nil {
+		t.Error("token signed by unknown key is accepted")
+	}
+}
+
+func TestCachedKeyWhenRefreshFails(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	v, closeServer := newTestVerifier(t, key)
+
+	claims := map[string]interface{}{
+		"iss": "https://idp.example.com",
+		"aud": "defect-manager",
+		"exp": time.Now().Add(time.Hour).Unix(),
+	}
+
+	if _, err := v.Verify(signToken(t, key, claims)); err != nil {
+		t.Fatal(err)
+	}
+
+	closeServer()
+
+	// the cached keys are expired and the idp is down
+	v.fetchedAt = time.Now().Add(-24 * time.Hour)
+	v.attemptedAt = v.fetchedAt
+
+	if _, err := v.Verify(signToken(t, key, claims)); err != nil {
+		t.Errorf("expect the cached key to be used, err: %s", err.Error())
+	}
+}
+
+func TestESSignatureLength(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	signed := "header.payload"
+	digest := sha256.Sum256([]byte(signed))
+
+	r, s, err := ecdsa.Sign(rand.Reader, key, digest[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sig := make([]byte, 64)
+	r.FillBytes(sig[:32])
+	s.FillBytes(sig[32:])
+
+	if err := verifySignature("ES256", &key.PublicKey, signed, sig); err != nil {
+		t.Fatalf("verify valid signature error: %s", err.Error())
+	}
+
+	// the same r and s, but padded to the size larger than the curve
+	padded := append(append([]byte{0}, sig[:32]...), append([]byte{0}, sig[32:]...)...)
+
+	if err := verifySignature("ES256", &key.PublicKey, signed, padded); err == nil {
+		t.Error("signature of invalid length is accepted")
+	}
+}
@@ -0,0 +1,36 @@
+package repositoryimpl
+
+import (
+	"time"
+
+	"github.com/opensourceways/defect-manager/auth/domain"
+)
+
+type auditDO struct {
+	ID        int       `gorm:"column:id;primaryKey;autoIncrement"`
+	Operator  string    `gorm:"column:operator;index"`
+	Source    string    `gorm:"column:source"`
+	Action    string    `gorm:"column:action;index"`
+	Target    string    `gorm:"column:target"`
+	CreatedAt time.Time `gorm:"column:created_at;<-:create;index"`
+}
+
+func (a auditDO) TableName() string {
+	return auditTableName
+}
+
+type auditImpl struct {
+	db dbimpl
+}
+
+func (impl auditImpl) AddAuditLog(log *domain.AuditLog) error {
+	do := auditDO{
+		Operator:  log.Operator,
+		Source:    log.Source,
+		Action:    log.Action,
+		Target:    log.Target,
+		CreatedAt: log.CreatedAt,
+	}
+
+	return impl.db.Insert(&do)
+}
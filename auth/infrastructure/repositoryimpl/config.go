@@ -0,0 +1,20 @@
+package repositoryimpl
+
+type Config struct {
+	Table Table `json:"table"`
+}
+
+type Table struct {
+	Token string `json:"token"`
+	Audit string `json:"audit"`
+}
+
+func (c *Config) SetDefault() {
+	if c.Table.Token == "" {
+		c.Table.Token = "api_token"
+	}
+
+	if c.Table.Audit == "" {
+		c.Table.Audit = "audit_log"
+	}
+}
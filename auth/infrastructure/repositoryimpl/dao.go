@@ -0,0 +1,10 @@
+package repositoryimpl
+
+type dbimpl interface {
+	GetRecord(filter, result interface{}) error
+	Insert(result interface{}) error
+	UpdateRecord(filter, update interface{}) error
+
+	IsRowNotFound(error) bool
+	IsRowExists(error) bool
+}
@@ -0,0 +1,62 @@
+package repositoryimpl
+
+import (
+	postgres "github.com/opensourceways/server-common-lib/postgre"
+
+	"github.com/opensourceways/defect-manager/auth/domain"
+	"github.com/opensourceways/defect-manager/auth/domain/repository"
+)
+
+var (
+	tokenInstance repository.TokenRepository
+	auditInstance repository.AuditRepository
+)
+
+var (
+	tokenTableName string
+	auditTableName string
+)
+
+// Init expects the tables have been created by the migrations
+func Init(cfg *Config) {
+	tokenTableName = cfg.Table.Token
+	auditTableName = cfg.Table.Audit
+
+	tokenInstance = tokenImpl{postgres.NewDBTable(cfg.Table.Token)}
+	auditInstance = auditImpl{postgres.NewDBTable(cfg.Table.Audit)}
+}
+
+func TokenInstance() repository.TokenRepository {
+	return tokenInstance
+}
+
+func AuditInstance() repository.AuditRepository {
+	return auditInstance
+}
+
+type tokenImpl struct {
+	db dbimpl
+}
+
+func (impl tokenImpl) AddToken(t *domain.Token) error {
+	do := toTokenDO(t)
+
+	return impl.db.Insert(&do)
+}
+
+func (impl tokenImpl) FindToken(hash string) (domain.Token, error) {
+	filter := tokenDO{Hash: hash}
+
+	var result tokenDO
+	if err := impl.db.GetRecord(&filter, &result); err != nil {
+		return domain.Token{}, err
+	}
+
+	return result.toToken(), nil
+}
+
+func (impl tokenImpl) RevokeToken(name string) error {
+	filter := tokenDO{Name: name}
+
+	return impl.db.UpdateRecord(&filter, &tokenDO{Revoked: true})
+}
@@ -0,0 +1,61 @@
+package repositoryimpl
+
+import (
+	"time"
+
+	"github.com/lib/pq"
+
+	"github.com/opensourceways/defect-manager/auth/domain"
+	"github.com/opensourceways/defect-manager/auth/domain/dp"
+)
+
+type tokenDO struct {
+	ID        int            `gorm:"column:id;primaryKey;autoIncrement"`
+	Name      string         `gorm:"column:name;uniqueIndex"`
+	Owner     string         `gorm:"column:owner"`
+	Hash      string         `gorm:"column:hash;uniqueIndex"`
+	Scopes    pq.StringArray `gorm:"column:scopes;type:text[];default:'{}'"`
+	ExpiresAt time.Time      `gorm:"column:expires_at"`
+	Revoked   bool           `gorm:"column:revoked"`
+	CreatedAt time.Time      `gorm:"column:created_at;<-:create"`
+	UpdatedAt time.Time      `gorm:"column:updated_at"`
+}
+
+func (t tokenDO) TableName() string {
+	return tokenTableName
+}
+
+func toTokenDO(t *domain.Token) tokenDO {
+	scopes := make(pq.StringArray, len(t.Scopes))
+	for k, v := range t.Scopes {
+		scopes[k] = v.String()
+	}
+
+	return tokenDO{
+		Name:      t.Name,
+		Owner:     t.Owner,
+		Hash:      t.Hash,
+		Scopes:    scopes,
+		ExpiresAt: t.ExpiresAt,
+		Revoked:   t.Revoked,
+	}
+}
+
+func (t tokenDO) toToken() domain.Token {
+	var scopes []dp.Scope
+	for _, v := range t.Scopes {
+		// ignore the scope which is no longer supported
+		if s, err := dp.NewScope(v); err == nil {
+			scopes = append(scopes, s)
+		}
+	}
+
+	return domain.Token{
+		Name:      t.Name,
+		Owner:     t.Owner,
+		Hash:      t.Hash,
+		Scopes:    scopes,
+		ExpiresAt: t.ExpiresAt,
+		Revoked:   t.Revoked,
+	}
+}
@@ -5,13 +5,20 @@ import (
 	"github.com/opensourceways/server-common-lib/postgre"
 	"github.com/opensourceways/server-common-lib/utils"
 
+	"github.com/opensourceways/defect-manager/auth/infrastructure/oidcimpl"
+	authrepositoryimpl "github.com/opensourceways/defect-manager/auth/infrastructure/repositoryimpl"
 	"github.com/opensourceways/defect-manager/defect/infrastructure/backendimpl"
 	"github.com/opensourceways/defect-manager/defect/infrastructure/bulletinimpl"
+	"github.com/opensourceways/defect-manager/defect/infrastructure/notifierimpl"
 	"github.com/opensourceways/defect-manager/defect/infrastructure/obsimpl"
 	"github.com/opensourceways/defect-manager/defect/infrastructure/producttreeimpl"
+	"github.com/opensourceways/defect-manager/defect/infrastructure/publisherimpl"
 	"github.com/opensourceways/defect-manager/defect/infrastructure/repositoryimpl"
+	"github.com/opensourceways/defect-manager/defect/infrastructure/sigimpl"
 	"github.com/opensourceways/defect-manager/issue"
 	messageserver "github.com/opensourceways/defect-manager/message-server"
+	"github.com/opensourceways/defect-manager/migration"
+	"github.com/opensourceways/defect-manager/scheduler"
 )
 
 func LoadConfig(path string) (*Config, error) {
@@ -37,14 +44,21 @@ type configSetDefault interface {
 }
 
 type Config struct {
-	MessageServer messageserver.Config   `json:"message_server" required:"true"`
-	Kafka         kafka.Config           `json:"kafka"          required:"true"`
-	Issue         issue.Config           `json:"issue"          required:"true"`
-	Postgres      postgres.Config        `json:"postgres"       required:"true"`
-	ProductTree   producttreeimpl.Config `json:"product_tree"   required:"true"`
-	Obs           obsimpl.Config         `json:"obs"            required:"true"`
-	Backend       backendimpl.Config     `json:"backend"        required:"true"`
-	Bulletin      bulletinimpl.Config    `json:"bulletin"`
+	MessageServer messageserver.Config      `json:"message_server" required:"true"`
+	Kafka         kafka.Config              `json:"kafka"          required:"true"`
+	Issue         issue.Config              `json:"issue"          required:"true"`
+	Postgres      postgres.Config           `json:"postgres"       required:"true"`
+	ProductTree   producttreeimpl.Config    `json:"product_tree"   required:"true"`
+	Obs           obsimpl.Config            `json:"obs"            required:"true"`
+	Backend       backendimpl.Config        `json:"backend"        required:"true"`
+	Bulletin      bulletinimpl.Config       `json:"bulletin"`
+	SIG           sigimpl.Config            `json:"sig"`
+	Notification  notifierimpl.Config       `json:"notification"`
+	Scheduler     scheduler.Config          `json:"scheduler"`
+	Event         publisherimpl.Config      `json:"event"`
+	Auth          authrepositoryimpl.Config `json:"auth"`
+	OIDC          oidcimpl.Config           `json:"oidc"`
+	Migration     migration.Config          `json:"migration"`
 
 	repositoryimpl.Config
 }
@@ -59,6 +73,14 @@ func (cfg *Config) configItems() []interface{} {
 		&cfg.Obs,
 		&cfg.Backend,
 		&cfg.Bulletin,
+		&cfg.SIG,
+		&cfg.Notification,
+		&cfg.Scheduler,
+		&cfg.Event,
+		&cfg.Auth,
+		&cfg.OIDC,
+		&cfg.Migration,
+		&cfg.Config,
 	}
 }
 
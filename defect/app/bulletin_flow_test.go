@@ -0,0 +1,455 @@
+package app
+
+import (
+	"fmt"
+	"path"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/opensourceways/defect-manager/defect/domain"
+	"github.com/opensourceways/defect-manager/defect/domain/backend"
+	"github.com/opensourceways/defect-manager/defect/domain/dp"
+	"github.com/opensourceways/defect-manager/defect/domain/repository"
+	"github.com/opensourceways/defect-manager/defect/infrastructure/backendimpl"
+	"github.com/opensourceways/defect-manager/defect/infrastructure/backendimpl/backendtest"
+	"github.com/opensourceways/defect-manager/defect/infrastructure/notifierimpl"
+	"github.com/opensourceways/defect-manager/utils"
+)
+
+type flowRepo struct {
+	repository.DefectRepository
+
+	defects domain.Defects
+}
+
+func (r *flowRepo) FindDefects(opt repository.OptToFindDefects) (domain.Defects, error) {
+	var ds domain.Defects
+	for _, d := range r.defects {
+		for i := range opt.Issues {
+			if d.Issue.Key() == opt.Issues[i].Key() {
+				ds = append(ds, d)
+
+				break
+			}
+		}
+	}
+
+	return ds, nil
+}
+
+func (r *flowRepo) SaveReleases(*domain.Issue, []domain.Release) error {
+	return nil
+}
+
+type flowVersions struct {
+	repository.VersionRepository
+
+	versions domain.MaintainedVersions
+}
+
+func (r flowVersions) FindVersions() (domain.MaintainedVersions, error) {
+	return r.versions, nil
+}
+
+type flowBulletins struct {
+	published map[string]string
+}
+
+func (r *flowBulletins) SaveBulletin(sb *domain.SecurityBulletin, events ...domain.DomainEvent) error {
+	for _, d := range sb.Defects {
+		r.published[d.Issue.Key()] = sb.Identification
+	}
+
+	return nil
+}
+
+func (r *flowBulletins) FindPublished(issues []domain.Issue) (map[string]string, error) {
+	m := make(map[string]string)
+	for _, v := range issues {
+		if id, ok := r.published[v.Key()]; ok {
+			m[v.Key()] = id
+		}
+	}
+
+	return m, nil
+}
+
+func (r *flowBulletins) LockGeneration() (func(), error) {
+	return func() {}, nil
+}
+
+type flowMapping struct {
+	repository.ComponentMappingRepository
+}
+
+func (m flowMapping) FindSourcePackage(string, dp.SystemVersion) (string, error) {
+	return "", nil
+}
+
+// flowTree has the fixed package of every component in every version
+type flowTree struct{}
+
+func (t flowTree) GetTree(component string, versions []dp.SystemVersion) (domain.ProductTree, error) {
+	tree := make(domain.ProductTree)
+	for range versions {
+		for _, arch := range []string{"src", "x86_64"} {
+			rpm := fmt.Sprintf("%s-1.0-2.oe2203.%s.rpm", component, arch)
+			n, _ := dp.ParseNEVRA(rpm)
+			tree[dp.NewArch(arch)] = append(tree[dp.NewArch(arch)], domain.Product{ID: component, FullName: rpm, RPM: n})
+		}
+	}
+
+	return tree, nil
+}
+
+func (t flowTree) Components(dp.SystemVersion) ([]string, error) {
+	return nil, nil
+}
+
+type flowGenerator struct{}
+
+func (g flowGenerator) Generate(sb *domain.SecurityBulletin) ([]byte, error) {
+	return []byte(sb.Identification), nil
+}
+
+func (g flowGenerator) GenerateCSAF(sb *domain.SecurityBulletin) ([]byte, error) {
+	return []byte(sb.Identification), nil
+}
+
+// flowOBS fails to upload the files in failed
+type flowOBS struct {
+	files  map[string]string
+	failed map[string]bool
+}
+
+func (o *flowOBS) Upload(fileName string, data []byte) (string, error) {
+	if o.failed[fileName] {
+		return "", fmt.Errorf("obs is unavailable")
+	}
+
+	o.files[fileName] = string(data)
+
+	return "bulletins/" + fileName, nil
+}
+
+type fixedClock struct {
+	now time.Time
+}
+
+func (c fixedClock) Now() time.Time {
+	return c.now
+}
+
+// flowNotifications held is true if another replica is sending
+type flowNotifications struct {
+	repository.NotificationRepository
+
+	items map[string]domain.Notification
+	held  bool
+}
+
+func (r *flowNotifications) LockSender() (func(), bool, error) {
+	if r.held {
+		return nil, false, nil
+	}
+
+	return func() {}, true, nil
+}
+
+func (r *flowNotifications) SaveNotification(n *domain.Notification) error {
+	r.items[n.Identification] = *n
+
+	return nil
+}
+
+func (r *flowNotifications) FindNotifications(status string) ([]domain.Notification, error) {
+	var ns []domain.Notification
+	for _, n := range r.items {
+		if status == "" || n.Status == status {
+			ns = append(ns, n)
+		}
+	}
+
+	return ns, nil
+}
+
+func (r *flowNotifications) FindNotification(id string) (domain.Notification, error) {
+	n, ok := r.items[id]
+	if !ok {
+		return n, repository.ErrNotificationNotFound
+	}
+
+	return n, nil
+}
+
+// flakyNotifier fails the first failures times
+type flakyNotifier struct {
+	failures int
+	notified []string
+}
+
+func (n *flakyNotifier) Notify(v *domain.Notification) error {
+	if n.failures > 0 {
+		n.failures--
+
+		return fmt.Errorf("downstream is unavailable")
+	}
+
+	n.notified = append(n.notified, v.Identification)
+
+	return nil
+}
+
+func newFlowBackend(endpoint string) backend.CveBackend {
+	maxRetries := 1
+
+	return backendimpl.New(&backendimpl.Config{
+		Endpoint: endpoint, Token: "secret", AuthHeader: "Authorization",
+		Timeout: 5, MaxRetries: &maxRetries, RetryInterval: 1,
+	}, utils.SystemClock{})
+}
+
+// TestGenerateBulletinsFlow runs the generation against the fake cve backend
+func TestGenerateBulletinsFlow(t *testing.T) {
+	server := backendtest.NewServer("Authorization", "secret")
+	defer server.Close()
+
+	be := newFlowBackend(server.URL)
+
+	now := time.Now()
+	year := now.Year()
+
+	// I3 has been published by the legacy bulletin which has the number only
+	err := be.PublishBulletin(&backend.Bulletin{
+		Identification: fmt.Sprintf("cvrf-openEuler-BA-%d-1005", year),
+		Component:      "vim",
+		Issues:         []string{"I3"},
+		PublishedAt:    now,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	version, _ := dp.NewSystemVersion("openEuler-22.03-LTS")
+	newDefect := func(component, number string) domain.Defect {
+		return domain.Defect{
+			Component:        component,
+			ComponentVersion: "1.0-1",
+			AffectedVersion:  []dp.SystemVersion{version},
+			Issue: domain.Issue{
+				Org: "src-openeuler", Repo: component, Number: number, Status: dp.IssueStatusClosed,
+			},
+		}
+	}
+
+	repo := &flowRepo{defects: domain.Defects{
+		newDefect("zbar", "I1"), newDefect("curl", "I2"), newDefect("vim", "I3"),
+	}}
+	bulletins := &flowBulletins{published: map[string]string{}}
+	obs := &flowOBS{files: map[string]string{}}
+
+	service := NewDefectService(DefectServiceOptions{
+		Repo: repo, Mapping: flowMapping{}, Versions: flowVersions{versions: domain.MaintainedVersions{{Version: version}}},
+		Bulletins: bulletins, ProductTree: flowTree{}, Bulletin: flowGenerator{}, Backend: be, OBS: obs,
+		Clock: fixedClock{now: now},
+	})
+
+	var issues []domain.Issue
+	for _, s := range []string{"zbar/I1", "curl/I2", "vim/I3", "git/I4"} {
+		issue, _ := domain.NewIssueOfKey("src-openeuler/" + s)
+		issues = append(issues, issue)
+	}
+
+	cmd := CmdToGenerateBulletins{Issues: issues, Grouping: domain.GroupingAuto}
+
+	report, err := service.GenerateBulletins(cmd)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the identifications continue from the max one of backend in the order of component
+	var got []string
+	for _, b := range report.Bulletins {
+		got = append(got, b.Identification+":"+strings.Join(b.Issues, ","))
+	}
+
+	want := fmt.Sprintf(
+		"cvrf-openEuler-BA-%[1]d-1006:src-openeuler/curl/I2 cvrf-openEuler-BA-%[1]d-1007:src-openeuler/zbar/I1", year,
+	)
+	if strings.Join(got, " ") != want {
+		t.Errorf("got bulletins %v, want %s", got, want)
+	}
+
+	excluded := map[string]string{}
+	for _, e := range report.Excluded {
+		excluded[path.Base(e.Issue)] = e.Reason
+	}
+
+	if excluded["I3"] != domain.ExclusionPublished || excluded["I4"] != domain.ExclusionNotFound {
+		t.Errorf("unexpected exclusions: %v", report.Excluded)
+	}
+
+	// the xml and csaf of each bulletin and the index
+	if _, ok := obs.files[uploadedDefect]; !ok || len(obs.files) != 5 {
+		t.Errorf("unexpected uploaded files: %v", obs.files)
+	}
+
+	// the backend does not know I1 and I2, they are rejected by the local records
+	report, err = service.GenerateBulletins(cmd)
+	if err != nil || len(report.Bulletins) != 0 || len(report.Excluded) != 4 {
+		t.Errorf("the published issues must not be re-issued, got %v, %v", report, err)
+	}
+}
+
+// TestIndexUploadFailed the issues are not recorded as published when the index is not uploaded
+func TestIndexUploadFailed(t *testing.T) {
+	server := backendtest.NewServer("Authorization", "secret")
+	defer server.Close()
+
+	be := newFlowBackend(server.URL)
+
+	version, _ := dp.NewSystemVersion("openEuler-22.03-LTS")
+	repo := &flowRepo{defects: domain.Defects{{
+		Component:        "zbar",
+		ComponentVersion: "1.0-1",
+		AffectedVersion:  []dp.SystemVersion{version},
+		Issue: domain.Issue{
+			Org: "src-openeuler", Repo: "zbar", Number: "I1", Status: dp.IssueStatusClosed,
+		},
+	}}}
+
+	bulletins := &flowBulletins{published: map[string]string{}}
+	obs := &flowOBS{files: map[string]string{}, failed: map[string]bool{uploadedDefect: true}}
+
+	service := NewDefectService(DefectServiceOptions{
+		Repo: repo, Mapping: flowMapping{}, Versions: flowVersions{versions: domain.MaintainedVersions{{Version: version}}},
+		Bulletins: bulletins, ProductTree: flowTree{}, Bulletin: flowGenerator{}, Backend: be, OBS: obs,
+	})
+
+	cmd := CmdToGenerateBulletins{Issues: []domain.Issue{repo.defects[0].Issue}, Grouping: domain.GroupingAuto}
+
+	report, err := service.GenerateBulletins(cmd)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(report.Bulletins) != 0 || len(report.Excluded) != 1 ||
+		report.Excluded[0].Reason != domain.ExclusionUploadFailed {
+		t.Errorf("unexpected report: %v", report)
+	}
+
+	if len(bulletins.published) != 0 {
+		t.Errorf("the issues must not be recorded as published: %v", bulletins.published)
+	}
+}
+
+// TestNotifyBulletinsFlow publishes the generated bulletins to the fake cve backend
+func TestNotifyBulletinsFlow(t *testing.T) {
+	server := backendtest.NewServer("Authorization", "secret")
+	defer server.Close()
+
+	be := newFlowBackend(server.URL)
+
+	version, _ := dp.NewSystemVersion("openEuler-22.03-LTS")
+	repo := &flowRepo{defects: domain.Defects{{
+		Component:        "zbar",
+		ComponentVersion: "1.0-1",
+		AffectedVersion:  []dp.SystemVersion{version},
+		Issue: domain.Issue{
+			Org: "src-openeuler", Repo: "zbar", Number: "I1", Status: dp.IssueStatusClosed,
+		},
+	}}}
+
+	notifications := &flowNotifications{items: map[string]domain.Notification{}}
+	notices := NewNotificationService(
+		notifications,
+		notifierimpl.New(&notifierimpl.Config{Target: notifierimpl.TargetBackend}, be), 2, time.Millisecond,
+		nil, nil, nil,
+	)
+
+	service := NewDefectService(DefectServiceOptions{
+		Repo: repo, Mapping: flowMapping{}, Versions: flowVersions{versions: domain.MaintainedVersions{{Version: version}}},
+		Bulletins: &flowBulletins{published: map[string]string{}}, ProductTree: flowTree{}, Bulletin: flowGenerator{},
+		Backend: be, OBS: &flowOBS{files: map[string]string{}}, Notices: notices,
+	})
+
+	cmd := CmdToGenerateBulletins{Issues: []domain.Issue{repo.defects[0].Issue}, Grouping: domain.GroupingAuto}
+
+	report, err := service.GenerateBulletins(cmd)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(report.Bulletins) != 1 || report.Bulletins[0].Notification != domain.NotificationPending {
+		t.Fatalf("unexpected bulletins: %v", report.Bulletins)
+	}
+
+	// it is sent by the worker
+	if got := server.Identifications(); len(got) != 0 {
+		t.Fatalf("the notification must not be sent in the generation, the backend has %v", got)
+	}
+
+	// another replica is sending
+	notifications.held = true
+	notices.sendPending()
+
+	if got := server.Identifications(); len(got) != 0 {
+		t.Fatalf("the round must be skipped when the lock is held, the backend has %v", got)
+	}
+
+	notifications.held = false
+	notices.sendPending()
+
+	id := report.Bulletins[0].Identification
+	if got := server.Identifications(); len(got) != 1 || got[0] != id {
+		t.Errorf("the backend has %v, want %s", got, id)
+	}
+
+	n, err := notices.repo.FindNotification(id)
+	if err != nil || n.File != "bulletins/"+id+".xml" || n.Attempts != 1 || !n.IsSucceeded() {
+		t.Errorf("unexpected notification: %v, %v", n, err)
+	}
+}
+
+func TestRetryNotification(t *testing.T) {
+	flaky := &flakyNotifier{failures: 3}
+	repo := &flowNotifications{items: map[string]domain.Notification{}}
+	notices := NewNotificationService(repo, flaky, 2, time.Minute, nil, nil, nil)
+
+	now := time.Now()
+	notices.clock = fixedClock{now: now}
+
+	n := domain.Notification{Identification: "cvrf-openEuler-BA-2023-1001", Status: domain.NotificationPending}
+	if err := notices.add(&n); err != nil {
+		t.Fatal(err)
+	}
+
+	// it is not retried until the interval passes, and it is failed after 2 attempts
+	for _, d := range []time.Duration{0, time.Second, time.Minute, time.Hour} {
+		notices.clock = fixedClock{now: now.Add(d)}
+		notices.sendPending()
+	}
+
+	if v := repo.items[n.Identification]; v.Status != domain.NotificationFailed || v.Attempts != 2 {
+		t.Fatalf("unexpected notification after failures: %v", v)
+	}
+
+	if v, err := notices.RetryNotification(n.Identification); err != nil || v.Status != domain.NotificationFailed {
+		t.Fatalf("unexpected retry: %v, %v", v, err)
+	}
+
+	v, err := notices.RetryNotification(n.Identification)
+	if err != nil || v.Status != domain.NotificationSucceeded || v.Attempts != 4 {
+		t.Fatalf("unexpected retry: %v, %v", v, err)
+	}
+
+	// a succeeded one is not notified again
+	if _, err = notices.RetryNotification(n.Identification); err != nil || len(flaky.notified) != 1 {
+		t.Errorf("notified %v, err %v", flaky.notified, err)
+	}
+
+	if _, err = notices.RetryNotification("unknown"); err != ErrNotificationNotFound {
+		t.Errorf("expect not found, got %v", err)
+	}
+}
@@ -0,0 +1,220 @@
+package app
+
+import (
+	"errors"
+	"sort"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	"github.com/opensourceways/defect-manager/defect/domain"
+	"github.com/opensourceways/defect-manager/defect/domain/dp"
+	"github.com/opensourceways/defect-manager/defect/domain/producttree"
+	"github.com/opensourceways/defect-manager/utils"
+)
+
+const (
+	maxComponentSuggestions = 5
+
+	// minMatchLength is the min length of the prefix or token shared by the similar components,
+	// so that the short names such as "c" are not similar to everything.
+	minMatchLength = 3
+
+	archSource = "src"
+)
+
+// CheckComponent returns the suggestions of similar components if the component is not found in the version
+func (d defectService) CheckComponent(cmd CmdToCheckComponent) (dto ComponentCheckDTO, err error) {
+	pkg, err := d.sourcePackageOf(cmd.Component, cmd.SystemVersion)
+	if err != nil {
+		return
+	}
+
+	dto.SourcePackage = pkg
+
+	tree, err := d.productTree.GetTree(pkg, []dp.SystemVersion{cmd.SystemVersion})
+	if err == nil {
+		dto.Found = true
+		dto.ShippedVersions = shippedVersions(tree)
+		dto.VersionMatched = cmd.ComponentVersion == "" ||
+			isVersionShipped(cmd.ComponentVersion, dto.ShippedVersions)
+
+		return
+	}
+
+	if !errors.As(err, &producttree.ComponentNotFoundError{}) {
+		return
+	}
+
+	components, err := d.productTree.Components(cmd.SystemVersion)
+	if err != nil {
+		return
+	}
+
+	dto.Suggestions = suggestComponents(cmd.Component, components, maxComponentSuggestions)
+
+	return
+}
+
+// sourceProducts returns the source rpms, or all the rpms if there is no source rpm
+func sourceProducts(tree domain.ProductTree) []domain.Product {
+	products := tree[dp.NewArch(archSource)]
+	if len(products) == 0 {
+		for _, v := range tree {
+			products = append(products, v...)
+		}
+	}
+
+	return products
+}
+
+// shippedVersions returns the versions of the source rpms
+func shippedVersions(tree domain.ProductTree) []string {
+	vs := sets.NewString()
+	for _, p := range sourceProducts(tree) {
+		vs.Insert(p.RPM.Version)
+	}
+
+	r := vs.List()
+	sort.Slice(r, func(i, j int) bool {
+		return dp.CompareRPMVersion(r[i], r[j]) < 0
+	})
+
+	return r
+}
+
+// isVersionShipped returns true if the version is the same as or newer than the oldest shipped one,
+// the version may be followed by the release, such as 0.22-4.
+func isVersionShipped(version string, shipped []string) bool {
+	if len(shipped) == 0 {
+		return false
+	}
+
+	if i := strings.Index(version, "-"); i > 0 {
+		version = version[:i]
+	}
+
+	return dp.CompareRPMVersion(version, shipped[0]) >= 0
+}
+
+// getTreeOfBulletin merges the trees of the components, each of which is in the versions affected by its defects
+func (d defectService) getTreeOfBulletin(b *domain.SecurityBulletin) (domain.ProductTree, error) {
+	tree := make(domain.ProductTree)
+	for _, c := range b.Components() {
+		t, err := d.getTree(c, b.AffectedVersionOf(c))
+		if err != nil {
+			return nil, err
+		}
+
+		for arch, products := range t {
+			tree[arch] = append(tree[arch], products...)
+		}
+	}
+
+	return tree, nil
+}
+
+// getTree gets the product tree of each version by the source package mapped from the component
+func (d defectService) getTree(component string, versions []dp.SystemVersion) (domain.ProductTree, error) {
+	tree := make(domain.ProductTree)
+	for _, v := range versions {
+		pkg, err := d.sourcePackageOf(component, v)
+		if err != nil {
+			return nil, err
+		}
+
+		t, err := d.productTree.GetTree(pkg, []dp.SystemVersion{v})
+		if err != nil {
+			return nil, err
+		}
+
+		for arch, products := range t {
+			tree[arch] = append(tree[arch], products...)
+		}
+	}
+
+	return tree, nil
+}
+
+func (d defectService) sourcePackageOf(component string, version dp.SystemVersion) (string, error) {
+	pkg, err := d.mapping.FindSourcePackage(component, version)
+	if err != nil || pkg == "" {
+		return component, err
+	}
+
+	return pkg, nil
+}
+
+// suggestComponents returns the candidates which share a prefix or token with the component
+// or whose edit distance to it is small, the closest is the first.
+func suggestComponents(component string, candidates []string, n int) []string {
+	type item struct {
+		name     string
+		distance int
+	}
+
+	target := strings.ToLower(component)
+	threshold := len(target) / 3
+	if threshold < 2 {
+		threshold = 2
+	}
+
+	var items []item
+	for _, c := range candidates {
+		name := strings.ToLower(c)
+
+		distance := utils.EditDistance(target, name)
+		if distance > threshold && !isSimilarComponent(target, name) {
+			continue
+		}
+
+		items = append(items, item{name: c, distance: distance})
+	}
+
+	sort.Slice(items, func(i, j int) bool {
+		if items[i].distance != items[j].distance {
+			return items[i].distance < items[j].distance
+		}
+
+		return items[i].name < items[j].name
+	})
+
+	if len(items) > n {
+		items = items[:n]
+	}
+
+	r := make([]string, len(items))
+	for i := range items {
+		r[i] = items[i].name
+	}
+
+	return r
+}
+
+// isSimilarComponent returns true if one is the prefix of the other, or they
+// have a same token split by '-', '_' or '.', such as python-pip and pip.
+func isSimilarComponent(a, b string) bool {
+	short, long := a, b
+	if len(short) > len(long) {
+		short, long = long, short
+	}
+
+	if len(short) >= minMatchLength && strings.HasPrefix(long, short) {
+		return true
+	}
+
+	tokens := sets.NewString(componentTokens(b)...)
+	for _, t := range componentTokens(a) {
+		if len(t) >= minMatchLength && tokens.Has(t) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func componentTokens(s string) []string {
+	return strings.FieldsFunc(s, func(r rune) bool {
+		return r == '-' || r == '_' || r == '.'
+	})
+}
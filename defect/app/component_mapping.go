@@ -0,0 +1,49 @@
+package app
+
+import (
+	"github.com/opensourceways/defect-manager/defect/domain"
+	"github.com/opensourceways/defect-manager/defect/domain/dp"
+	"github.com/opensourceways/defect-manager/defect/domain/repository"
+)
+
+var ErrMappingNotFound = repository.ErrMappingNotFound
+
+// ComponentMappingService maintains the mappings in the table,
+// they take precedence over the ones in config.
+type ComponentMappingService interface {
+	ListMappings() ([]ComponentMappingDTO, error)
+	SaveMapping(CmdToSaveMapping) error
+	DeleteMapping(component string, version dp.SystemVersion) error
+}
+
+func NewComponentMappingService(r repository.ComponentMappingRepository) *componentMappingService {
+	return &componentMappingService{repo: r}
+}
+
+type componentMappingService struct {
+	repo repository.ComponentMappingRepository
+}
+
+func (s componentMappingService) ListMappings() ([]ComponentMappingDTO, error) {
+	ms, err := s.repo.FindMappings()
+	if err != nil {
+		return nil, err
+	}
+
+	r := make([]ComponentMappingDTO, len(ms))
+	for i := range ms {
+		r[i] = toComponentMappingDTO(&ms[i])
+	}
+
+	return r, nil
+}
+
+func (s componentMappingService) SaveMapping(cmd CmdToSaveMapping) error {
+	m := domain.ComponentMapping(cmd)
+
+	return s.repo.SaveMapping(&m)
+}
+
+func (s componentMappingService) DeleteMapping(component string, version dp.SystemVersion) error {
+	return s.repo.DeleteMapping(component, version)
+}
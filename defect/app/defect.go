@@ -1,8 +1,10 @@
 package app
 
 import (
+	"errors"
 	"fmt"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/sirupsen/logrus"
@@ -15,150 +17,588 @@ import (
 	"github.com/opensourceways/defect-manager/defect/domain/obs"
 	"github.com/opensourceways/defect-manager/defect/domain/producttree"
 	"github.com/opensourceways/defect-manager/defect/domain/repository"
+	"github.com/opensourceways/defect-manager/defect/domain/sig"
+	"github.com/opensourceways/defect-manager/metrics"
 	"github.com/opensourceways/defect-manager/utils"
 )
 
-const uploadedDefect = "update_defect.txt"
+const (
+	uploadedDefect = "update_defect.txt"
+
+	// collectPageSize is the count of defects loaded at a time when collecting
+	collectPageSize = 500
+
+	// the reasons of the bulletins failed to be generated
+	bulletinFailureNoProductTree = "no_product_tree"
+	bulletinFailureGenerate      = "generate_failed"
+	bulletinFailureUpload        = "upload_failed"
+	bulletinFailureIndex         = "index_failed"
+)
+
+var ErrDefectNotFound = repository.ErrDefectNotFound
+
+// ErrSeverityOfCVSS the severity level of a defect with CVSS vector is derived from the vector
+var ErrSeverityOfCVSS = errors.New("severity level is derived from the cvss vector, clear the vector to choose it")
+
+// generateLock serializes the generations of the scheduler and the api in the process,
+// otherwise they may assign the same identification. The replicas are serialized by
+// the lock of the bulletin repository.
+var generateLock sync.Mutex
 
 type DefectService interface {
 	IsDefectExist(*domain.Issue) (bool, error)
 	SaveDefects(CmdToSaveDefect) error
-	CollectDefects(time time.Time) ([]CollectDefectsDTO, error)
-	GenerateBulletins([]string) error
+	CollectDefects(CmdToCollectDefects) ([]CollectDefectsDTO, error)
+	GenerateBulletins(CmdToGenerateBulletins) (BulletinsReportDTO, error)
+	ListDefects(CmdToListDefects) (DefectsDTO, error)
+	GetDefect(*domain.Issue) (DefectDTO, error)
+	UpdateDefect(CmdToUpdateDefect) error
+	DeleteDefect(*domain.Issue) error
+	CheckComponent(CmdToCheckComponent) (ComponentCheckDTO, error)
+	CheckRelease(*domain.Issue) ([]ReleaseDTO, error)
+	RejectDefect(issue *domain.Issue, reason string) error
+	ReopenDefect(*domain.Issue) error
 }
 
-func NewDefectService(
-	r repository.DefectRepository,
-	t producttree.ProductTree,
-	b bulletin.Bulletin,
-	be backend.CveBackend,
-	o obs.OBS,
-) *defectService {
+// DefectServiceOptions Notices and Events are nil if they are disabled, Clock is the system one if nil
+type DefectServiceOptions struct {
+	Repo        repository.DefectRepository
+	Mapping     repository.ComponentMappingRepository
+	Versions    repository.VersionRepository
+	Bulletins   repository.BulletinRepository
+	ProductTree producttree.ProductTree
+	Bulletin    bulletin.Bulletin
+	Backend     backend.CveBackend
+	OBS         obs.OBS
+	SIG         sig.SIG
+	Notices     *notificationService
+	Events      *eventOutbox
+	Clock       utils.Clock
+}
+
+func NewDefectService(opts DefectServiceOptions) *defectService {
+	clock := opts.Clock
+	if clock == nil {
+		clock = utils.SystemClock{}
+	}
+
 	return &defectService{
-		repo:        r,
-		productTree: t,
-		bulletin:    b,
-		backend:     be,
-		obs:         o,
+		repo:        opts.Repo,
+		mapping:     opts.Mapping,
+		versions:    opts.Versions,
+		bulletins:   opts.Bulletins,
+		productTree: opts.ProductTree,
+		bulletin:    opts.Bulletin,
+		backend:     opts.Backend,
+		obs:         opts.OBS,
+		sig:         opts.SIG,
+		notices:     opts.Notices,
+		events:      opts.Events,
+		clock:       clock,
 	}
 }
 
 type defectService struct {
 	repo        repository.DefectRepository
+	mapping     repository.ComponentMappingRepository
+	versions    repository.VersionRepository
+	bulletins   repository.BulletinRepository
 	productTree producttree.ProductTree
 	bulletin    bulletin.Bulletin
 	backend     backend.CveBackend
 	obs         obs.OBS
+	sig         sig.SIG
+	notices     *notificationService
+	events      *eventOutbox
+	clock       utils.Clock
 }
 
 func (d defectService) IsDefectExist(issue *domain.Issue) (bool, error) {
 	return d.repo.HasDefect(issue)
 }
 
+// SaveDefects the subscriptions and the outbox are told only when the defect is accepted the first time,
+// that is when it is inserted, a re-approval is not an acceptance.
 func (d defectService) SaveDefects(cmd CmdToSaveDefect) error {
-	has, err := d.repo.HasDefect(&cmd.Issue)
+	now := d.clock.Now()
+
+	events := d.events.events(func() (domain.DomainEvent, error) {
+		return defectEvent(domain.DomainEventDefectAccepted, &cmd, now)
+	})
+
+	accepted, err := d.repo.SaveDefect(&cmd, events...)
 	if err != nil {
 		return err
 	}
 
-	if has {
-		return d.repo.SaveDefect(&cmd)
-	} else {
-		return d.repo.AddDefect(&cmd)
+	if accepted {
+		d.notices.emit(defectAcceptedEvent(&cmd, now))
+	}
+
+	// the baseline is recorded for the affected versions added by the re-approval too
+	if err := d.recordBaselines(&cmd.Issue); err != nil {
+		logrus.Errorf("record the release baselines of issue %s error: %s", cmd.Issue.Key(), err.Error())
 	}
+
+	return nil
+}
+
+// RejectDefect records that the issue is closed without the data of defect, so it is reopened
+func (d defectService) RejectDefect(issue *domain.Issue, reason string) error {
+	return d.events.save(func() (domain.DomainEvent, error) {
+		data := defectEventData(issue)
+		data.Reason = reason
+
+		return newDomainEvent(domain.DomainEventDefectRejected, issue.Key(), data, d.clock.Now())
+	})
 }
 
-func (d defectService) CollectDefects(date time.Time) (dto []CollectDefectsDTO, err error) {
+// ReopenDefect records that the issue of an accepted defect is reopened
+func (d defectService) ReopenDefect(issue *domain.Issue) error {
+	if !d.events.isEnabled() {
+		return nil
+	}
+
+	defect, err := d.repo.FindDefect(issue)
+	if err != nil {
+		return err
+	}
+
+	return d.events.save(func() (domain.DomainEvent, error) {
+		return defectEvent(domain.DomainEventDefectReopened, &defect, d.clock.Now())
+	})
+}
+
+func (d defectService) CollectDefects(cmd CmdToCollectDefects) (dto []CollectDefectsDTO, err error) {
 	opt := repository.OptToFindDefects{
-		BeginTime: date,
-		Status:    dp.IssueStatusClosed,
+		BeginTime:    cmd.BeginTime,
+		EndTime:      cmd.EndTime,
+		Status:       dp.IssueStatusClosed,
+		CountPerPage: collectPageSize,
+		Ascend:       true,
 	}
 
-	defects, err := d.repo.FindDefects(opt)
-	if err != nil || len(defects) == 0 {
-		return
+	var ps *publishedIssues
+	for {
+		page, err := d.repo.FindDefectsPage(opt)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(page.Defects) == 0 {
+			break
+		}
+
+		// fetch the published defects only when there are defects to be filtered
+		if ps == nil {
+			v, err := d.backend.PublishedDefects()
+			if err != nil {
+				return nil, err
+			}
+
+			ps = newPublishedIssues(v)
+		}
+
+		var unpublishedDefects domain.Defects
+		for _, defect := range page.Defects {
+			if !ps.has(&defect.Issue) {
+				unpublishedDefects = append(unpublishedDefects, defect)
+			}
+		}
+
+		dto = append(dto, ToCollectDefectsDTO(unpublishedDefects)...)
+
+		if page.NextCursor == "" {
+			break
+		}
+
+		opt.Cursor = page.NextCursor
 	}
 
-	publishedNum, err := d.backend.PublishedDefects()
+	return
+}
+
+func (d defectService) ListDefects(cmd CmdToListDefects) (dto DefectsDTO, err error) {
+	total, err := d.repo.CountDefects(cmd)
 	if err != nil {
 		return
 	}
 
-	var unpublishedDefects domain.Defects
-	ps := sets.NewString(publishedNum...)
-	for _, defect := range defects {
-		if _, ok := ps[defect.Issue.Number]; !ok {
-			unpublishedDefects = append(unpublishedDefects, defect)
-		}
+	if total == 0 {
+		return toDefectsDTO(0, repository.DefectsPage{}), nil
 	}
 
-	dto = ToCollectDefectsDTO(unpublishedDefects)
+	page, err := d.repo.FindDefectsPage(cmd)
+	if err != nil {
+		return
+	}
 
-	return
+	return toDefectsDTO(total, page), nil
 }
 
-func (d defectService) GenerateBulletins(number []string) error {
-	opt := repository.OptToFindDefects{
-		Number: number,
+func (d defectService) GetDefect(issue *domain.Issue) (dto DefectDTO, err error) {
+	defect, err := d.repo.FindDefect(issue)
+	if err != nil {
+		return
 	}
 
-	defects, err := d.repo.FindDefects(opt)
+	return toDefectDTO(&defect), nil
+}
+
+func (d defectService) UpdateDefect(cmd CmdToUpdateDefect) error {
+	defect, err := d.repo.FindDefect(&cmd.Issue)
 	if err != nil {
 		return err
 	}
 
+	if err = cmd.apply(&defect); err != nil {
+		return err
+	}
+
+	return d.repo.UpdateDefect(&defect)
+}
+
+func (d defectService) DeleteDefect(issue *domain.Issue) error {
+	return d.repo.DeleteDefect(issue)
+}
+
+// GenerateBulletins every requested issue is either in a bulletin or excluded in the report
+func (d defectService) GenerateBulletins(cmd CmdToGenerateBulletins) (report BulletinsReportDTO, err error) {
+	generateLock.Lock()
+	defer generateLock.Unlock()
+
+	unlock, err := d.bulletins.LockGeneration()
+	if err != nil {
+		return
+	}
+
+	defer unlock()
+
+	keys, err := d.resolveNumbers(&cmd)
+	if err != nil {
+		return
+	}
+
+	var defects domain.Defects
+	if len(cmd.Issues) > 0 {
+		if defects, err = d.repo.FindDefects(repository.OptToFindDefects{Issues: cmd.Issues}); err != nil {
+			return
+		}
+	}
+
+	policy, err := d.publishPolicy(defects, cmd.Force)
+	if err != nil {
+		return
+	}
+
 	maxIdentification, err := d.backend.MaxBulletinID()
 	if err != nil {
-		return err
+		return
 	}
 
-	bulletins := defects.GenerateBulletins()
+	maintained, err := maintainedVersions(d.versions)
+	if err != nil {
+		return
+	}
+
+	candidates, excluded := defects.Candidates(keys, policy, maintained)
+
+	// the defect is blocked in the version until the fixed package is released
+	d.checkReleases(candidates)
 
-	d.productTree.InitCache()
-	defer d.productTree.CleanCache()
+	released, notReleased := candidates.Released(maintained)
+	excluded = append(excluded, notReleased...)
+
+	strategy, err := d.groupingStrategy(&cmd, released)
+	if err != nil {
+		return
+	}
+
+	bulletins, err := strategy.Group(released, maintained)
+	if err != nil {
+		return
+	}
 
-	var uploadedFile []string
+	now := d.clock.Now()
+
+	var uploaded []uploadedBulletin
 	for _, b := range bulletins {
-		maxIdentification++
-		b.Identification = fmt.Sprintf("cvrf-openEuler-BA-%d-%d", utils.Year(), maxIdentification)
+		b.Date = now
 
-		b.ProductTree, err = d.productTree.GetTree(b.Component, b.AffectedVersion)
+		// the bulletin is blocked when its product tree is empty or unavailable
+		b.ProductTree, err = d.getTreeOfBulletin(&b)
 		if err != nil {
-			logrus.Errorf("%s, component %s, get productTree error: %s", b.Identification, b.Component, err.Error())
+			logrus.Errorf("component %s, get productTree error: %s", b.Component, err.Error())
+
+			excluded = append(excluded, domain.ExcludeBulletin(&b, domain.ExclusionNoProductTree, err.Error())...)
+			metrics.BulletinsFailed.WithLabelValues(bulletinFailureNoProductTree).Inc()
 
 			continue
 		}
 
+		maxIdentification++
+		b.Identification = fmt.Sprintf("cvrf-openEuler-BA-%d-%d", now.Year(), maxIdentification)
+
 		xmlData, err := d.bulletin.Generate(&b)
 		if err != nil {
 			logrus.Errorf("%s, component: %s, to xml error: %s", b.Identification, b.Component, err.Error())
 
+			excluded = append(excluded, domain.ExcludeBulletin(&b, domain.ExclusionGenerateFailed, err.Error())...)
+			metrics.BulletinsFailed.WithLabelValues(bulletinFailureGenerate).Inc()
+
+			continue
+		}
+
+		csafData, err := d.bulletin.GenerateCSAF(&b)
+		if err != nil {
+			logrus.Errorf("%s, component: %s, to csaf error: %s", b.Identification, b.Component, err.Error())
+
+			excluded = append(excluded, domain.ExcludeBulletin(&b, domain.ExclusionGenerateFailed, err.Error())...)
+			metrics.BulletinsFailed.WithLabelValues(bulletinFailureGenerate).Inc()
+
 			continue
 		}
 
+		// the csaf document is uploaded first, so the bulletin is complete once the xml listed by the index exists
 		fileName := fmt.Sprintf("%s.xml", b.Identification)
-		if err := d.obs.Upload(fileName, xmlData); err != nil {
+		key, err := d.obs.Upload(fmt.Sprintf("%s.json", b.Identification), csafData)
+		if err == nil {
+			key, err = d.obs.Upload(fileName, xmlData)
+		}
+
+		if err != nil {
 			logrus.Errorf("%s, component: %s, upload to obs error: %s", b.Identification, b.Component, err.Error())
 
+			excluded = append(excluded, domain.ExcludeBulletin(&b, domain.ExclusionUploadFailed, err.Error())...)
+			metrics.BulletinsFailed.WithLabelValues(bulletinFailureUpload).Inc()
+
 			continue
 		}
 
-		uploadedFile = append(uploadedFile, fileName)
+		uploaded = append(uploaded, uploadedBulletin{bulletin: b, file: fileName, key: key})
+	}
+
+	// the bulletins are published only when the index lists them, so the issues
+	// are recorded as published after it, otherwise they could never be re-issued.
+	if err := d.uploadIndex(uploaded, now); err != nil {
+		logrus.Errorf("upload %s error: %s", uploadedDefect, err.Error())
+
+		for i := range uploaded {
+			excluded = append(excluded, domain.ExcludeBulletin(
+				&uploaded[i].bulletin, domain.ExclusionUploadFailed, "upload the index error: "+err.Error(),
+			)...)
+			metrics.BulletinsFailed.WithLabelValues(bulletinFailureIndex).Inc()
+		}
+
+		uploaded = nil
 	}
 
-	return d.uploadUploadedFile(uploadedFile)
+	for i := range uploaded {
+		report.Bulletins = append(report.Bulletins, d.publishBulletin(&uploaded[i], &report))
+	}
+
+	report.Excluded = toExclusionsDTO(excluded, report.Bulletins)
+
+	return
+}
+
+// uploadedBulletin key is the key of the bulletin file in OBS
+type uploadedBulletin struct {
+	bulletin domain.SecurityBulletin
+	file     string
+	key      string
 }
 
-func (d defectService) uploadUploadedFile(files []string) error {
-	if len(files) == 0 {
+// publishBulletin records the bulletin listed by the index, the failures are reported
+// since the bulletin has been published and it can't be excluded.
+func (d defectService) publishBulletin(ub *uploadedBulletin, report *BulletinsReportDTO) BulletinDTO {
+	b := &ub.bulletin
+
+	metrics.BulletinsGenerated.Inc()
+
+	// the issues are not re-issued by mistake, even if the cve backend is unavailable.
+	// The events are saved with them, so they are published only if the bulletin exists.
+	events := d.events.events(func() (domain.DomainEvent, error) {
+		return bulletinEvent(domain.DomainEventBulletinGenerated, b, "")
+	})
+	events = append(events, d.events.events(func() (domain.DomainEvent, error) {
+		return bulletinEvent(domain.DomainEventBulletinUploaded, b, ub.key)
+	})...)
+
+	if err := d.bulletins.SaveBulletin(b, events...); err != nil {
+		logrus.Errorf("%s, save the issues of bulletin error: %s", b.Identification, err.Error())
+
+		report.Errors = append(report.Errors, fmt.Sprintf(
+			"%s, record the issues as published error: %s", b.Identification, err.Error(),
+		))
+	}
+
+	d.notices.emit(bulletinGeneratedEvent(b))
+
+	dto := toBulletinDTO(b)
+
+	if d.notices.isEnabled() {
+		n := domain.NewNotification(b, ub.key)
+		if err := d.notices.add(&n); err != nil {
+			logrus.Errorf("%s, save the notification error: %s", b.Identification, err.Error())
+
+			report.Errors = append(report.Errors, fmt.Sprintf(
+				"%s, save the notification error: %s", b.Identification, err.Error(),
+			))
+		} else {
+			dto.Notification = n.Status
+		}
+	}
+
+	return dto
+}
+
+// resolveNumbers adds the issues matching the bare numbers to cmd and returns the keys to check,
+// the numbers matching nothing are kept as they are so that they are reported as not found.
+func (d defectService) resolveNumbers(cmd *CmdToGenerateBulletins) ([]string, error) {
+	if len(cmd.Numbers) == 0 {
+		return keysOf(cmd.Issues), nil
+	}
+
+	matched, err := d.repo.FindDefects(repository.OptToFindDefects{Numbers: cmd.Numbers})
+	if err != nil {
+		return nil, err
+	}
+
+	found := sets.NewString()
+	for i := range matched {
+		cmd.Issues = append(cmd.Issues, matched[i].Issue)
+		found.Insert(matched[i].Issue.Number)
+	}
+
+	keys := keysOf(cmd.Issues)
+	for _, n := range cmd.Numbers {
+		if !found.Has(n) {
+			keys = append(keys, n)
+		}
+	}
+
+	return keys, nil
+}
+
+func keysOf(issues []domain.Issue) []string {
+	r := make([]string, len(issues))
+	for i := range issues {
+		r[i] = issues[i].Key()
+	}
+
+	return r
+}
+
+// publishedIssues is the issues published to the backend, the legacy bulletins
+// have the numbers only, so an issue is published if its number is among them.
+type publishedIssues struct {
+	keys    sets.String
+	numbers sets.String
+}
+
+func newPublishedIssues(v []string) *publishedIssues {
+	p := &publishedIssues{keys: sets.NewString(), numbers: sets.NewString()}
+	for _, s := range v {
+		if strings.Contains(s, "/") {
+			p.keys.Insert(s)
+		} else {
+			p.numbers.Insert(s)
+		}
+	}
+
+	return p
+}
+
+func (p *publishedIssues) has(issue *domain.Issue) bool {
+	return p.keys.Has(issue.Key()) || p.numbers.Has(issue.Number)
+}
+
+// publishPolicy the issues are published if they are in the local records or in the cve backend,
+// only the local records are used when the backend is unavailable.
+func (d defectService) publishPolicy(defects domain.Defects, force bool) (domain.PublishPolicy, error) {
+	issues := make([]domain.Issue, len(defects))
+	for i := range defects {
+		issues[i] = defects[i].Issue
+	}
+
+	published, err := d.bulletins.FindPublished(issues)
+	if err != nil {
+		return domain.PublishPolicy{}, err
+	}
+
+	if published == nil {
+		published = make(map[string]string)
+	}
+
+	if v, err := d.backend.PublishedDefects(); err != nil {
+		logrus.Warnf("get published defects from backend error: %s, use the local records only", err.Error())
+	} else {
+		ps := newPublishedIssues(v)
+		for i := range issues {
+			k := issues[i].Key()
+			if _, ok := published[k]; !ok && ps.has(&issues[i]) {
+				published[k] = ""
+			}
+		}
+	}
+
+	return domain.PublishPolicy{Published: published, Force: force}, nil
+}
+
+func (d defectService) groupingStrategy(cmd *CmdToGenerateBulletins, defects domain.Defects) (
+	domain.GroupingStrategy, error,
+) {
+	switch cmd.Grouping {
+	case domain.GroupingComponent:
+		return domain.NewComponentGrouping(), nil
+
+	case domain.GroupingVersionSet:
+		return domain.NewVersionSetGrouping(), nil
+
+	case domain.GroupingManual:
+		groups := make([][]string, len(cmd.Groups))
+		for i, g := range cmd.Groups {
+			groups[i] = keysOf(g)
+		}
+
+		return domain.NewManualGrouping(groups), nil
+
+	case domain.GroupingSIG:
+		sigs := make(map[string]string)
+		for i := range defects {
+			repo := defects[i].Issue.RepoPath()
+			if _, ok := sigs[repo]; ok {
+				continue
+			}
+
+			v, err := d.sig.SIGOfRepo(repo)
+			if err != nil {
+				return nil, err
+			}
+
+			sigs[repo] = v
+		}
+
+		return domain.NewSIGGrouping(sigs), nil
+
+	default:
+		return domain.NewAutoGrouping(), nil
+	}
+}
+
+func (d defectService) uploadIndex(bs []uploadedBulletin, now time.Time) error {
+	if len(bs) == 0 {
 		return nil
 	}
 
 	var uploadedFileWithPrefix []string
-	for _, v := range files {
-		t := fmt.Sprintf("%d/%s", time.Now().Year(), v)
+	for i := range bs {
+		t := fmt.Sprintf("%d/%s", now.Year(), bs[i].file)
 		uploadedFileWithPrefix = append(uploadedFileWithPrefix, t)
 	}
 
-	return d.obs.Upload(uploadedDefect, []byte(strings.Join(uploadedFileWithPrefix, "\n")))
+	_, err := d.obs.Upload(uploadedDefect, []byte(strings.Join(uploadedFileWithPrefix, "\n")))
+
+	return err
 }
@@ -0,0 +1,212 @@
+package app
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/opensourceways/defect-manager/defect/domain"
+	"github.com/opensourceways/defect-manager/defect/domain/backend"
+	"github.com/opensourceways/defect-manager/defect/domain/dp"
+	"github.com/opensourceways/defect-manager/defect/domain/repository"
+)
+
+type repoTest struct {
+	repository.DefectRepository
+
+	defects domain.Defects
+	pages   int
+}
+
+func (r *repoTest) FindDefectsPage(opt repository.OptToFindDefects) (repository.DefectsPage, error) {
+	r.pages++
+
+	start := 0
+	if opt.Cursor != "" {
+		start, _ = strconv.Atoi(opt.Cursor)
+	}
+
+	end := start + opt.CountPerPage
+	if end >= len(r.defects) {
+		return repository.DefectsPage{Defects: r.defects[start:]}, nil
+	}
+
+	return repository.DefectsPage{Defects: r.defects[start:end], NextCursor: strconv.Itoa(end)}, nil
+}
+
+type backendTest struct {
+	backend.CveBackend
+
+	published []string
+}
+
+func (b backendTest) MaxBulletinID() (int, error) {
+	return 1000, nil
+}
+
+func (b backendTest) PublishedDefects() ([]string, error) {
+	return b.published, nil
+}
+
+func TestCollectDefects(t *testing.T) {
+	level, _ := dp.NewSeverityLevel("Low")
+
+	repo := new(repoTest)
+	for i := 0; i < collectPageSize*2+1; i++ {
+		repo.defects = append(repo.defects, domain.Defect{
+			SeverityLevel: level,
+			Issue: domain.Issue{
+				Number: strconv.Itoa(i),
+				Status: dp.IssueStatusClosed,
+			},
+		})
+	}
+
+	service := NewDefectService(DefectServiceOptions{
+		Repo: repo, Backend: backendTest{published: []string{"0", "600"}},
+	})
+
+	dto, err := service.CollectDefects(CmdToCollectDefects{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if repo.pages != 3 {
+		t.Errorf("expect 3 pages, got %d", repo.pages)
+	}
+
+	if len(dto) != len(repo.defects)-2 {
+		t.Errorf("expect %d unpublished defects, got %d", len(repo.defects)-2, len(dto))
+	}
+}
+
+type numbersRepo struct {
+	repository.DefectRepository
+
+	defects domain.Defects
+}
+
+func (r numbersRepo) FindDefects(opt repository.OptToFindDefects) (domain.Defects, error) {
+	var ds domain.Defects
+	for _, d := range r.defects {
+		for _, n := range opt.Numbers {
+			if d.Issue.Number == n {
+				ds = append(ds, d)
+			}
+		}
+	}
+
+	return ds, nil
+}
+
+func TestResolveNumbers(t *testing.T) {
+	repo := numbersRepo{defects: domain.Defects{
+		{Issue: domain.Issue{Org: "src-openeuler", Repo: "zbar", Number: "I1"}},
+		{Issue: domain.Issue{Org: "src-openeuler", Repo: "kernel", Number: "I2"}},
+	}}
+
+	service := NewDefectService(DefectServiceOptions{Repo: repo})
+
+	cmd := CmdToGenerateBulletins{
+		Issues:  []domain.Issue{{Org: "openeuler", Repo: "kernel", Number: "I3"}},
+		Numbers: []string{"I1", "I4"},
+	}
+
+	keys, err := service.resolveNumbers(&cmd)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := "openeuler/kernel/I3,src-openeuler/zbar/I1,I4"
+	if got := strings.Join(keys, ","); got != want {
+		t.Errorf("got keys %s, want %s", got, want)
+	}
+
+	if len(cmd.Issues) != 2 {
+		t.Errorf("expect the matched issue to be added, got %v", cmd.Issues)
+	}
+}
+
+func TestSuggestComponents(t *testing.T) {
+	candidates := []string{"python-pip", "python-pyyaml", "zbar", "openssl", "openssh"}
+
+	got := suggestComponents("python3-pip", candidates, 3)
+	if len(got) == 0 || got[0] != "python-pip" {
+		t.Errorf("unexpected suggestions: %v", got)
+	}
+
+	if got = suggestComponents("kernel", candidates, 3); len(got) != 0 {
+		t.Errorf("unexpected suggestions: %v", got)
+	}
+
+	// the short component is not similar to every candidate containing it
+	if got = suggestComponents("ssl", []string{"openssl", "nss-softokn"}, 3); len(got) != 0 {
+		t.Errorf("unexpected suggestions: %v", got)
+	}
+
+	cases := map[string]string{"pyyaml": "python-pyyaml", "opens": "openssh"}
+	for component, want := range cases {
+		if got = suggestComponents(component, candidates, 3); len(got) == 0 || got[0] != want {
+			t.Errorf("%s, unexpected suggestions: %v", component, got)
+		}
+	}
+}
+
+func TestIsVersionShipped(t *testing.T) {
+	shipped := []string{"0.22", "0.23"}
+
+	for v, want := range map[string]bool{"0.22": true, "0.22-4": true, "0.23.1": true, "0.21": false} {
+		if got := isVersionShipped(v, shipped); got != want {
+			t.Errorf("version %s, got %v, want %v", v, got, want)
+		}
+	}
+}
+
+func TestNoMaintainedVersion(t *testing.T) {
+	version, _ := dp.NewSystemVersion("openEuler-20.03-LTS")
+	retired := flowVersions{versions: domain.MaintainedVersions{{Version: version, Retired: true}}}
+
+	for _, r := range []flowVersions{{}, retired} {
+		if _, err := maintainedVersions(r); !errors.Is(err, ErrNoMaintainedVersion) {
+			t.Errorf("expect no maintained version, got %v", err)
+		}
+	}
+}
+
+func TestUpdateSeverityOfCVSS(t *testing.T) {
+	vector, _ := dp.NewCVSS("CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H")
+	low, _ := dp.NewSeverityLevel("Low")
+
+	d := domain.Defect{CVSS: vector, SeverityLevel: vector.SeverityLevel()}
+	if err := (&CmdToUpdateDefect{SeverityLevel: low}).apply(&d); !errors.Is(err, ErrSeverityOfCVSS) {
+		t.Fatalf("expect the severity level to be rejected, got %v", err)
+	}
+
+	if err := (&CmdToUpdateDefect{SeverityLevel: low, ClearCVSS: true}).apply(&d); err != nil {
+		t.Fatal(err)
+	}
+
+	if d.CVSS != nil || d.SeverityLevel.String() != low.String() {
+		t.Errorf("unexpected defect after clearing the vector: %v %v", d.CVSS, d.SeverityLevel)
+	}
+}
+
+func TestExclusionsPartial(t *testing.T) {
+	v2, _ := dp.NewSystemVersion("openEuler-22.03-LTS-SP1")
+
+	es := []domain.Exclusion{
+		{Issue: "src-openeuler/zbar/I1", Reason: domain.ExclusionNotReleased, Versions: []dp.SystemVersion{v2}},
+		{Issue: "src-openeuler/zbar/I2", Reason: domain.ExclusionNoProductTree, Versions: []dp.SystemVersion{v2}},
+	}
+	bulletins := []BulletinDTO{{Issues: []string{"src-openeuler/zbar/I1"}}}
+
+	dto := toExclusionsDTO(es, bulletins)
+	if !dto[0].Partial || len(dto[0].AffectedVersion) != 1 || dto[0].AffectedVersion[0] != v2.String() {
+		t.Errorf("I1 is published in the other versions, got %v", dto[0])
+	}
+
+	if dto[1].Partial {
+		t.Errorf("I2 is not published, got %v", dto[1])
+	}
+}
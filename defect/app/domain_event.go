@@ -0,0 +1,316 @@
+package app
+
+import (
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+
+	"github.com/opensourceways/defect-manager/defect/domain"
+	"github.com/opensourceways/defect-manager/defect/domain/publisher"
+	"github.com/opensourceways/defect-manager/defect/domain/repository"
+	"github.com/opensourceways/defect-manager/utils"
+)
+
+const (
+	// eventSchemaVersion is changed only when the schema is changed incompatibly,
+	// the new fields can be added without changing it.
+	eventSchemaVersion = "1"
+	eventSource        = "defect-manager"
+)
+
+// EventEnvelope is the schema of the domain events published to kafka,
+// Data is DefectEventData or BulletinEventData according to Type.
+type EventEnvelope struct {
+	SchemaVersion string      `json:"schema_version"`
+	ID            string      `json:"id"`
+	Type          string      `json:"type"`
+	Source        string      `json:"source"`
+	OccurredAt    string      `json:"occurred_at"`
+	Data          interface{} `json:"data"`
+}
+
+// DefectEventData Reason is why the defect is rejected
+type DefectEventData struct {
+	Org             string   `json:"org"`
+	Repo            string   `json:"repo"`
+	Number          string   `json:"number"`
+	IssueURL        string   `json:"issue_url"`
+	Component       string   `json:"component,omitempty"`
+	SeverityLevel   string   `json:"severity_level,omitempty"`
+	CVSSVector      string   `json:"cvss_vector,omitempty"`
+	AffectedVersion []string `json:"affected_version,omitempty"`
+	Reason          string   `json:"reason,omitempty"`
+}
+
+// BulletinEventData Issues is the keys(org/repo/number) of the issues,
+// File is the key of the bulletin in OBS, it is set only when the bulletin is uploaded
+type BulletinEventData struct {
+	Identification  string   `json:"identification"`
+	Component       string   `json:"component"`
+	AffectedVersion []string `json:"affected_version"`
+	Issues          []string `json:"issues"`
+	File            string   `json:"file,omitempty"`
+	Date            string   `json:"date"`
+}
+
+func newDomainEvent(typ, key string, data interface{}, now time.Time) (domain.DomainEvent, error) {
+	id, err := uuid.NewRandom()
+	if err != nil {
+		return domain.DomainEvent{}, err
+	}
+
+	e := domain.DomainEvent{
+		ID:         id.String(),
+		Type:       typ,
+		Version:    eventSchemaVersion,
+		Key:        key,
+		OccurredAt: now,
+	}
+
+	body, err := json.Marshal(EventEnvelope{
+		SchemaVersion: e.Version,
+		ID:            e.ID,
+		Type:          typ,
+		Source:        eventSource,
+		OccurredAt:    now.Format(time.RFC3339),
+		Data:          data,
+	})
+	if err != nil {
+		return e, err
+	}
+
+	e.Body = body
+
+	return e, nil
+}
+
+func defectEventData(issue *domain.Issue) DefectEventData {
+	return DefectEventData{
+		Org:      issue.Org,
+		Repo:     issue.Repo,
+		Number:   issue.Number,
+		IssueURL: issueURL(issue),
+	}
+}
+
+func defectEvent(typ string, d *domain.Defect, now time.Time) (domain.DomainEvent, error) {
+	data := defectEventData(&d.Issue)
+	data.Component = d.Component
+	data.AffectedVersion = make([]string, len(d.AffectedVersion))
+
+	for i, v := range d.AffectedVersion {
+		data.AffectedVersion[i] = v.String()
+	}
+
+	if d.SeverityLevel != nil {
+		data.SeverityLevel = d.SeverityLevel.String()
+	}
+
+	if d.CVSS != nil {
+		data.CVSSVector = d.CVSS.Vector()
+	}
+
+	return newDomainEvent(typ, d.Issue.Key(), data, now)
+}
+
+func bulletinEvent(typ string, sb *domain.SecurityBulletin, file string) (domain.DomainEvent, error) {
+	dto := toBulletinDTO(sb)
+
+	return newDomainEvent(typ, sb.Identification, BulletinEventData{
+		Identification:  dto.Identification,
+		Component:       dto.Component,
+		AffectedVersion: dto.AffectedVersion,
+		Issues:          dto.Issues,
+		File:            file,
+		Date:            sb.Date.Format(time.RFC3339),
+	}, sb.Date)
+}
+
+// NewEventOutbox p is nil if the domain events are disabled, the pending events are published every interval,
+// at most batchSize at a time, and the published ones are deleted after retention. An event is parked
+// after maxAttempts failures, so it does not block the later ones any more.
+func NewEventOutbox(
+	r repository.OutboxRepository,
+	p publisher.Publisher,
+	batchSize int,
+	maxAttempts int,
+	interval time.Duration,
+	retention time.Duration,
+) *eventOutbox {
+	return &eventOutbox{
+		repo:        r,
+		publisher:   p,
+		batchSize:   batchSize,
+		maxAttempts: maxAttempts,
+		interval:    interval,
+		retention:   retention,
+		clock:       utils.SystemClock{},
+	}
+}
+
+// eventOutbox publishes the events after they are saved with the changes of state,
+// so an event is published at least once if and only if the change is committed.
+type eventOutbox struct {
+	repo        repository.OutboxRepository
+	publisher   publisher.Publisher
+	batchSize   int
+	maxAttempts int
+	interval    time.Duration
+	retention   time.Duration
+	clock       utils.Clock
+
+	stop chan struct{}
+	wg   sync.WaitGroup
+}
+
+func (o *eventOutbox) isEnabled() bool {
+	return o != nil && o.publisher != nil
+}
+
+// events returns the events to be saved with the change of state, it is empty if disabled
+func (o *eventOutbox) events(build func() (domain.DomainEvent, error)) []domain.DomainEvent {
+	if !o.isEnabled() {
+		return nil
+	}
+
+	e, err := build()
+	if err != nil {
+		logrus.Errorf("build domain event error: %s", err.Error())
+
+		return nil
+	}
+
+	return []domain.DomainEvent{e}
+}
+
+// save saves the events which are not along with any change of state
+func (o *eventOutbox) save(build func() (domain.DomainEvent, error)) error {
+	es := o.events(build)
+	if len(es) == 0 {
+		return nil
+	}
+
+	return o.repo.SaveEvents(es...)
+}
+
+// Start does nothing if it is disabled
+func (o *eventOutbox) Start() {
+	if !o.isEnabled() || o.stop != nil {
+		return
+	}
+
+	o.stop = make(chan struct{})
+	o.wg.Add(1)
+
+	go func() {
+		defer o.wg.Done()
+
+		o.loop()
+	}()
+}
+
+// Stop publishes the pending events once more before returning
+func (o *eventOutbox) Stop() {
+	if o.stop == nil {
+		return
+	}
+
+	close(o.stop)
+	o.wg.Wait()
+}
+
+func (o *eventOutbox) loop() {
+	ticker := time.NewTicker(o.interval)
+	defer ticker.Stop()
+
+	for {
+		o.relay()
+
+		select {
+		case <-o.stop:
+			o.relay()
+
+			return
+
+		case <-ticker.C:
+		}
+	}
+}
+
+// relay publishes the pending events in order until there is none or one fails,
+// the failed one is retried in the next round so that the order is kept. Only one
+// replica relays at a time, the others skip the round.
+func (o *eventOutbox) relay() {
+	unlock, locked, err := o.repo.LockRelay()
+	if err != nil {
+		logrus.Errorf("lock the relay of domain events error: %s", err.Error())
+
+		return
+	}
+
+	if !locked {
+		return
+	}
+
+	defer unlock()
+
+	for {
+		es, err := o.repo.FindPendingEvents(o.batchSize)
+		if err != nil {
+			logrus.Errorf("find pending domain events error: %s", err.Error())
+
+			return
+		}
+
+		for i := range es {
+			if !o.publish(&es[i]) {
+				return
+			}
+		}
+
+		if len(es) < o.batchSize {
+			break
+		}
+	}
+
+	if err := o.repo.DeletePublished(o.clock.Now().Add(-o.retention)); err != nil {
+		logrus.Errorf("delete published domain events error: %s", err.Error())
+	}
+}
+
+// publish returns false if the later events should wait for the next round
+func (o *eventOutbox) publish(e *domain.DomainEvent) bool {
+	if err := o.publisher.Publish(e); err != nil {
+		logrus.Errorf("publish domain event %s of %s error: %s", e.Type, e.ID, err.Error())
+
+		if e.Attempts+1 >= o.maxAttempts {
+			logrus.Errorf("park domain event %s of %s after %d attempts", e.Type, e.ID, e.Attempts+1)
+
+			if err := o.repo.ParkEvent(e.ID, err.Error(), o.clock.Now()); err != nil {
+				logrus.Errorf("park domain event %s error: %s", e.ID, err.Error())
+
+				return false
+			}
+
+			return true
+		}
+
+		if err := o.repo.RecordFailure(e.ID, err.Error()); err != nil {
+			logrus.Errorf("record the failure of domain event %s error: %s", e.ID, err.Error())
+		}
+
+		return false
+	}
+
+	if err := o.repo.MarkPublished(e.ID, o.clock.Now()); err != nil {
+		// it will be published again, the consumers deduplicate it by id
+		logrus.Errorf("mark domain event %s published error: %s", e.ID, err.Error())
+
+		return false
+	}
+
+	return true
+}
@@ -0,0 +1,215 @@
+package app
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/opensourceways/defect-manager/defect/domain"
+	"github.com/opensourceways/defect-manager/defect/domain/dp"
+	"github.com/opensourceways/defect-manager/defect/domain/repository"
+)
+
+type outboxRepo struct {
+	repository.DefectRepository
+
+	saved     map[string]bool
+	events    []domain.DomainEvent
+	published map[string]bool
+	parked    map[string]bool
+	attempts  map[string]int
+}
+
+func (r *outboxRepo) SaveDefect(d *domain.Defect, events ...domain.DomainEvent) (bool, error) {
+	key := d.Issue.Key()
+	if r.saved[key] {
+		return false, nil
+	}
+
+	r.saved[key] = true
+
+	return true, r.SaveEvents(events...)
+}
+
+func (r *outboxRepo) SaveEvents(events ...domain.DomainEvent) error {
+	r.events = append(r.events, events...)
+
+	return nil
+}
+
+func (r *outboxRepo) FindPendingEvents(limit int) ([]domain.DomainEvent, error) {
+	var es []domain.DomainEvent
+	for _, e := range r.events {
+		if !r.published[e.ID] && !r.parked[e.ID] && len(es) < limit {
+			e.Attempts = r.attempts[e.ID]
+			es = append(es, e)
+		}
+	}
+
+	return es, nil
+}
+
+func (r *outboxRepo) MarkPublished(id string, at time.Time) error {
+	r.published[id] = true
+
+	return nil
+}
+
+func (r *outboxRepo) RecordFailure(id string, reason string) error {
+	r.attempts[id]++
+
+	return nil
+}
+
+func (r *outboxRepo) ParkEvent(id string, reason string, at time.Time) error {
+	r.attempts[id]++
+	r.parked[id] = true
+
+	return nil
+}
+
+func (r *outboxRepo) LockRelay() (func(), bool, error) {
+	return func() {}, true, nil
+}
+
+func (r *outboxRepo) DeletePublished(before time.Time) error {
+	return nil
+}
+
+func newOutboxRepo() *outboxRepo {
+	return &outboxRepo{
+		saved:     map[string]bool{},
+		published: map[string]bool{},
+		parked:    map[string]bool{},
+		attempts:  map[string]int{},
+	}
+}
+
+// flakyPublisher fails when the event of failOn is published
+type flakyPublisher struct {
+	failOn string
+	topics []string
+}
+
+func (p *flakyPublisher) Publish(e *domain.DomainEvent) error {
+	if e.Key == p.failOn {
+		return errors.New("kafka is unavailable")
+	}
+
+	p.topics = append(p.topics, e.Type+":"+e.Key)
+
+	return nil
+}
+
+func TestEventOutbox(t *testing.T) {
+	repo := newOutboxRepo()
+	p := &flakyPublisher{failOn: "src-openeuler/curl/I2"}
+	outbox := NewEventOutbox(repo, p, 2, 3, time.Second, time.Hour)
+
+	service := NewDefectService(DefectServiceOptions{Repo: repo, Events: outbox})
+
+	high, _ := dp.NewSeverityLevel("High")
+	for _, v := range []string{"zbar/I1", "curl/I2", "vim/I3"} {
+		items := strings.Split(v, "/")
+		err := service.SaveDefects(domain.Defect{
+			Component:     items[0],
+			SeverityLevel: high,
+			Issue:         domain.Issue{Org: "src-openeuler", Repo: items[0], Number: items[1]},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// the re-approval is not an acceptance
+	err := service.SaveDefects(domain.Defect{
+		Component:     "zbar",
+		SeverityLevel: high,
+		Issue:         domain.Issue{Org: "src-openeuler", Repo: "zbar", Number: "I1"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	issue := domain.Issue{Org: "src-openeuler", Repo: "zbar", Number: "I4"}
+	if err := service.RejectDefect(&issue, "not collected"); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(repo.events) != 4 {
+		t.Fatalf("got %d events", len(repo.events))
+	}
+
+	// the events after the failed one are kept for the next round
+	outbox.relay()
+	if got := strings.Join(p.topics, " "); got != "defect.accepted:src-openeuler/zbar/I1" {
+		t.Errorf("published %s", got)
+	}
+
+	p.failOn = ""
+	outbox.relay()
+
+	want := "defect.accepted:src-openeuler/zbar/I1 defect.accepted:src-openeuler/curl/I2 " +
+		"defect.accepted:src-openeuler/vim/I3 defect.rejected:src-openeuler/zbar/I4"
+	if got := strings.Join(p.topics, " "); got != want {
+		t.Errorf("published %s, want %s", got, want)
+	}
+
+	var envelope struct {
+		EventEnvelope
+
+		Data DefectEventData `json:"data"`
+	}
+
+	e := repo.events[3]
+	if err := json.Unmarshal(e.Body, &envelope); err != nil {
+		t.Fatal(err)
+	}
+
+	if envelope.SchemaVersion != eventSchemaVersion || envelope.ID != e.ID || envelope.Type != e.Type ||
+		envelope.Data.Number != "I4" || envelope.Data.Reason != "not collected" {
+		t.Errorf("unexpected envelope: %s", e.Body)
+	}
+}
+
+func TestParkEvent(t *testing.T) {
+	repo := newOutboxRepo()
+	p := &flakyPublisher{failOn: "I1"}
+	outbox := NewEventOutbox(repo, p, 10, 3, time.Second, time.Hour)
+
+	for _, key := range []string{"I1", "I2"} {
+		if err := outbox.save(func() (domain.DomainEvent, error) {
+			return newDomainEvent(domain.DomainEventDefectRejected, key, nil, time.Now())
+		}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// the poison event blocks the later ones until it is parked
+	for i := 0; i < 3; i++ {
+		if len(p.topics) != 0 {
+			t.Fatalf("the later events must wait, published %v", p.topics)
+		}
+
+		outbox.relay()
+	}
+
+	if e := repo.events[0]; !repo.parked[e.ID] || repo.attempts[e.ID] != 3 {
+		t.Errorf("the poison event must be parked after 3 attempts, got %d", repo.attempts[e.ID])
+	}
+
+	if got := strings.Join(p.topics, " "); got != "defect.rejected:I2" {
+		t.Errorf("published %s", got)
+	}
+}
+
+func TestEventOutboxDisabled(t *testing.T) {
+	repo := newOutboxRepo()
+	service := NewDefectService(DefectServiceOptions{Repo: repo, Events: NewEventOutbox(repo, nil, 2, 3, 0, 0)})
+
+	if err := service.SaveDefects(domain.Defect{Component: "zbar"}); err != nil || len(repo.events) != 0 {
+		t.Errorf("no event is expected when disabled, got %v, %v", repo.events, err)
+	}
+}
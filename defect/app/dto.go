@@ -1,9 +1,15 @@
 package app
 
 import (
+	"encoding/json"
 	"fmt"
+	"strconv"
+	"time"
 
 	"github.com/opensourceways/defect-manager/defect/domain"
+	"github.com/opensourceways/defect-manager/defect/domain/dp"
+	"github.com/opensourceways/defect-manager/defect/domain/repository"
+	"k8s.io/apimachinery/pkg/util/sets"
 )
 
 const (
@@ -12,14 +18,139 @@ const (
 
 type CmdToSaveDefect = domain.Defect
 
+type CmdToListDefects = repository.OptToFindDefects
+
+// CmdToCollectDefects EndTime is exclusive and the zero value means no limit
+type CmdToCollectDefects struct {
+	BeginTime time.Time
+	EndTime   time.Time
+}
+
+// CmdToGenerateBulletins Grouping is one of the domain.GroupingXXX, Groups is the issues of
+// each bulletin when it is manual. Force re-issues the published issues.
+// Numbers is the bare issue numbers of the legacy requests, they match the defects of any repo.
+type CmdToGenerateBulletins struct {
+	Issues   []domain.Issue
+	Numbers  []string
+	Grouping string
+	Groups   [][]domain.Issue
+	Force    bool
+}
+
+// CmdToUpdateDefect nil field means the field is not changed, ClearCVSS removes the vector.
+// The severity level is derived from CVSS if it is set, so it can't be chosen while the defect has a vector.
+type CmdToUpdateDefect struct {
+	Issue            domain.Issue
+	Title            *string
+	Status           dp.IssueStatus
+	Kernel           *string
+	Component        *string
+	ComponentVersion *string
+	FixedVersion     *string
+	SystemVersion    dp.SystemVersion
+	Description      *string
+	ReferenceURL     dp.URL
+	GuidanceURL      dp.URL
+	Influence        *string
+	SeverityLevel    dp.SeverityLevel
+	CVSS             dp.CVSS
+	ClearCVSS        bool
+	AffectedVersion  []dp.SystemVersion
+	ABI              *string
+	References       []dp.Reference
+}
+
+func (cmd *CmdToUpdateDefect) apply(d *domain.Defect) error {
+	setString := func(dst *string, v *string) {
+		if v != nil {
+			*dst = *v
+		}
+	}
+
+	setString(&d.Issue.Title, cmd.Title)
+	setString(&d.Kernel, cmd.Kernel)
+	setString(&d.Component, cmd.Component)
+	setString(&d.ComponentVersion, cmd.ComponentVersion)
+	setString(&d.FixedVersion, cmd.FixedVersion)
+	setString(&d.Description, cmd.Description)
+	setString(&d.Influence, cmd.Influence)
+	setString(&d.ABI, cmd.ABI)
+
+	if cmd.Status != nil {
+		d.Issue.Status = cmd.Status
+	}
+
+	if cmd.SystemVersion != nil {
+		d.SystemVersion = cmd.SystemVersion
+	}
+
+	if cmd.ReferenceURL != nil {
+		d.ReferenceURL = cmd.ReferenceURL
+	}
+
+	if cmd.GuidanceURL != nil {
+		d.GuidanceURL = cmd.GuidanceURL
+	}
+
+	if cmd.ClearCVSS {
+		d.CVSS = nil
+	}
+
+	if cmd.CVSS != nil {
+		d.CVSS = cmd.CVSS
+		d.SeverityLevel = cmd.CVSS.SeverityLevel()
+	}
+
+	if cmd.SeverityLevel != nil {
+		if d.CVSS != nil {
+			return ErrSeverityOfCVSS
+		}
+
+		d.SeverityLevel = cmd.SeverityLevel
+	}
+
+	if cmd.AffectedVersion != nil {
+		d.AffectedVersion = cmd.AffectedVersion
+	}
+
+	if cmd.References != nil {
+		d.References = cmd.References
+	}
+
+	return nil
+}
+
+// CmdToCheckComponent ComponentVersion is not checked if it is empty
+type CmdToCheckComponent struct {
+	Component        string
+	ComponentVersion string
+	SystemVersion    dp.SystemVersion
+}
+
+// ComponentCheckDTO SourcePackage is the one mapped from the component,
+// Suggestions are the similar components in the version when it is not found.
+// VersionMatched is true if the component version is the same as or newer than a shipped version.
+type ComponentCheckDTO struct {
+	Found           bool
+	SourcePackage   string
+	Suggestions     []string
+	VersionMatched  bool
+	ShippedVersions []string
+}
+
+// CollectDefectsDTO Score is the CVSS base score, or the severity level if there is no CVSS vector
 type CollectDefectsDTO struct {
-	Title     string `json:"title"`
-	Number    string `json:"issue_id"`
-	IssueUrl  string `json:"issue_url"`
-	Component string `json:"component"`
-	Status    string `json:"status"`
-	Score     string `json:"score"`
-	Version   string `json:"version"`
+	Title         string `json:"title"`
+	Number        string `json:"issue_id"`
+	Org           string `json:"org"`
+	Repo          string `json:"repo"`
+	IssueUrl      string `json:"issue_url"`
+	Component     string `json:"component"`
+	Status        string `json:"status"`
+	Score         string `json:"score"`
+	SeverityLevel string `json:"severity_level"`
+	CVSSVector    string `json:"cvss_vector"`
+	Version       string `json:"version"`
 }
 
 func ToCollectDefectsDTO(defects domain.Defects) []CollectDefectsDTO {
@@ -27,15 +158,431 @@ func ToCollectDefectsDTO(defects domain.Defects) []CollectDefectsDTO {
 	for _, d := range defects {
 		url := fmt.Sprintf("%s/%s/%s/issues/%s", giteeUrl, d.Issue.Org, d.Issue.Repo, d.Issue.Number)
 
-		dto = append(dto, CollectDefectsDTO{
-			Title:     d.Issue.Title,
-			Number:    d.Issue.Number,
-			IssueUrl:  url,
-			Component: d.Component,
-			Status:    d.Issue.Status.String(),
-			Score:     d.SeverityLevel.String(),
-			Version:   d.ComponentVersion,
-		})
+		item := CollectDefectsDTO{
+			Title:         d.Issue.Title,
+			Number:        d.Issue.Number,
+			Org:           d.Issue.Org,
+			Repo:          d.Issue.Repo,
+			IssueUrl:      url,
+			Component:     d.Component,
+			Status:        d.Issue.Status.String(),
+			Score:         d.SeverityLevel.String(),
+			SeverityLevel: d.SeverityLevel.String(),
+			Version:       d.ComponentVersion,
+		}
+
+		if d.CVSS != nil {
+			item.Score = formatScore(d.CVSS.Score())
+			item.CVSSVector = d.CVSS.Vector()
+		}
+
+		dto = append(dto, item)
+	}
+
+	return dto
+}
+
+type DefectDTO struct {
+	Title            string         `json:"title"`
+	Number           string         `json:"issue_id"`
+	Org              string         `json:"org"`
+	Repo             string         `json:"repo"`
+	IssueUrl         string         `json:"issue_url"`
+	Status           string         `json:"status"`
+	Kernel           string         `json:"kernel"`
+	Component        string         `json:"component"`
+	ComponentVersion string         `json:"component_version"`
+	FixedVersion     string         `json:"fixed_version"`
+	SystemVersion    string         `json:"system_version"`
+	Description      string         `json:"description"`
+	ReferenceURL     string         `json:"reference_url"`
+	GuidanceURL      string         `json:"guidance_url"`
+	Influence        string         `json:"influence"`
+	SeverityLevel    string         `json:"severity_level"`
+	CVSSVector       string         `json:"cvss_vector"`
+	CVSSScore        string         `json:"cvss_score"`
+	AffectedVersion  []string       `json:"affected_version"`
+	ABI              string         `json:"abi"`
+	References       []ReferenceDTO `json:"references"`
+	Releases         []ReleaseDTO   `json:"releases"`
+	CreatedAt        string         `json:"created_at"`
+	UpdatedAt        string         `json:"updated_at"`
+}
+
+// ReferenceDTO Type is one of cve, cwe, upstream_commit and upstream_bug
+type ReferenceDTO struct {
+	Type string `json:"type"`
+	ID   string `json:"id"`
+}
+
+func toReferencesDTO(refs []dp.Reference) []ReferenceDTO {
+	dto := make([]ReferenceDTO, len(refs))
+	for k, v := range refs {
+		dto[k] = ReferenceDTO{
+			Type: v.Type().String(),
+			ID:   v.ID(),
+		}
+	}
+
+	return dto
+}
+
+// ReleaseDTO Status is one of released, pending and not_found,
+// Package is the newest rpm of the component in the version.
+type ReleaseDTO struct {
+	Version   string `json:"version"`
+	Package   string `json:"package"`
+	Status    string `json:"status"`
+	Baseline  string `json:"baseline"`
+	CheckedAt string `json:"checked_at"`
+}
+
+func toReleasesDTO(rs []domain.Release) []ReleaseDTO {
+	dto := make([]ReleaseDTO, len(rs))
+	for k := range rs {
+		dto[k] = ReleaseDTO{
+			Version:   rs[k].Version.String(),
+			Package:   rs[k].Package,
+			Status:    rs[k].Status,
+			Baseline:  rs[k].Baseline,
+			CheckedAt: rs[k].CheckedAt.Format(time.RFC3339),
+		}
+	}
+
+	return dto
+}
+
+// BulletinsReportDTO every requested issue is either in a bulletin or excluded for the reason,
+// Errors are the failures of recording the published bulletins, which need to be fixed manually.
+type BulletinsReportDTO struct {
+	Bulletins []BulletinDTO  `json:"bulletins"`
+	Excluded  []ExclusionDTO `json:"excluded"`
+	Errors    []string       `json:"errors,omitempty"`
+}
+
+// BulletinDTO Issues is the keys(org/repo/number) of the issues,
+// Notification is pending if the downstream will be notified in background, it is empty if the notification is disabled
+type BulletinDTO struct {
+	Identification  string   `json:"identification"`
+	Component       string   `json:"component"`
+	AffectedVersion []string `json:"affected_version"`
+	Issues          []string `json:"issues"`
+	Notification    string   `json:"notification"`
+}
+
+// ExclusionDTO Reason is one of not_found, not_closed, already_published, no_affected_version,
+// not_released, missing_product_tree, generate_failed and upload_failed
+// Issue is org/repo/number of the issue, or the requested value if it is not found.
+// AffectedVersion is the excluded versions if the issue is excluded only in some of them,
+// Partial is true if the issue is published in the other versions by the bulletins of the report.
+type ExclusionDTO struct {
+	Issue           string   `json:"issue"`
+	Reason          string   `json:"reason"`
+	Detail          string   `json:"detail"`
+	AffectedVersion []string `json:"affected_version,omitempty"`
+	Partial         bool     `json:"partial"`
+}
+
+func toBulletinDTO(sb *domain.SecurityBulletin) BulletinDTO {
+	dto := BulletinDTO{
+		Identification:  sb.Identification,
+		Component:       sb.Component,
+		AffectedVersion: make([]string, len(sb.AffectedVersion)),
+		Issues:          make([]string, len(sb.Defects)),
+	}
+
+	for k, v := range sb.AffectedVersion {
+		dto.AffectedVersion[k] = v.String()
+	}
+
+	for k := range sb.Defects {
+		dto.Issues[k] = sb.Defects[k].Issue.Key()
+	}
+
+	return dto
+}
+
+// NotificationDTO Status is one of pending, succeeded and failed, File is the key of the bulletin in OBS
+type NotificationDTO struct {
+	Identification  string   `json:"identification"`
+	Component       string   `json:"component"`
+	AffectedVersion []string `json:"affected_version"`
+	Issues          []string `json:"issues"`
+	File            string   `json:"file"`
+	Status          string   `json:"status"`
+	Attempts        int      `json:"attempts"`
+	LastError       string   `json:"last_error"`
+	UpdatedAt       string   `json:"updated_at"`
+}
+
+func toNotificationDTO(n *domain.Notification) NotificationDTO {
+	dto := NotificationDTO{
+		Identification:  n.Identification,
+		Component:       n.Component,
+		AffectedVersion: make([]string, len(n.AffectedVersion)),
+		Issues:          n.Issues,
+		File:            n.File,
+		Status:          n.Status,
+		Attempts:        n.Attempts,
+		LastError:       n.LastError,
+		UpdatedAt:       n.UpdatedAt.Format(time.RFC3339),
+	}
+
+	for k, v := range n.AffectedVersion {
+		dto.AffectedVersion[k] = v.String()
+	}
+
+	return dto
+}
+
+// GenerationDTO Status is one of running, succeeded and failed,
+// Report is set if it succeeds and Error is why it fails.
+type GenerationDTO struct {
+	ID        string              `json:"id"`
+	Issues    []string            `json:"issues"`
+	Status    string              `json:"status"`
+	Report    *BulletinsReportDTO `json:"report,omitempty"`
+	Error     string              `json:"error,omitempty"`
+	CreatedAt string              `json:"created_at"`
+	UpdatedAt string              `json:"updated_at"`
+}
+
+func toGenerationDTO(g *domain.Generation) GenerationDTO {
+	dto := GenerationDTO{
+		ID:        g.ID,
+		Issues:    g.Issues,
+		Status:    g.Status,
+		Error:     g.Error,
+		CreatedAt: g.CreatedAt.Format(time.RFC3339),
+		UpdatedAt: g.UpdatedAt.Format(time.RFC3339),
+	}
+
+	if len(g.Report) > 0 {
+		var report BulletinsReportDTO
+		if err := json.Unmarshal(g.Report, &report); err == nil {
+			dto.Report = &report
+		}
+	}
+
+	return dto
+}
+
+func toExclusionsDTO(es []domain.Exclusion, bulletins []BulletinDTO) []ExclusionDTO {
+	published := sets.NewString()
+	for i := range bulletins {
+		published.Insert(bulletins[i].Issues...)
+	}
+
+	dto := make([]ExclusionDTO, len(es))
+	for k, v := range es {
+		dto[k] = ExclusionDTO{
+			Issue:   v.Issue,
+			Reason:  v.Reason,
+			Detail:  v.Detail,
+			Partial: len(v.Versions) > 0 && published.Has(v.Issue),
+		}
+
+		for _, version := range v.Versions {
+			dto[k].AffectedVersion = append(dto[k].AffectedVersion, version.String())
+		}
+	}
+
+	return dto
+}
+
+func formatScore(score float64) string {
+	return strconv.FormatFloat(score, 'f', 1, 64)
+}
+
+type DefectsDTO struct {
+	Total      int         `json:"total"`
+	Defects    []DefectDTO `json:"defects"`
+	NextCursor string      `json:"next_cursor"`
+}
+
+func toDefectDTO(d *domain.Defect) DefectDTO {
+	toString := func(v interface{ String() string }) string {
+		if v == nil {
+			return ""
+		}
+
+		return v.String()
+	}
+
+	toURL := func(v dp.URL) string {
+		if v == nil {
+			return ""
+		}
+
+		return v.URL()
+	}
+
+	var cvssVector, cvssScore string
+	if d.CVSS != nil {
+		cvssVector = d.CVSS.Vector()
+		cvssScore = formatScore(d.CVSS.Score())
+	}
+
+	affectedVersion := make([]string, len(d.AffectedVersion))
+	for k, v := range d.AffectedVersion {
+		affectedVersion[k] = toString(v)
+	}
+
+	return DefectDTO{
+		Title:            d.Issue.Title,
+		Number:           d.Issue.Number,
+		Org:              d.Issue.Org,
+		Repo:             d.Issue.Repo,
+		IssueUrl:         fmt.Sprintf("%s/%s/%s/issues/%s", giteeUrl, d.Issue.Org, d.Issue.Repo, d.Issue.Number),
+		Status:           toString(d.Issue.Status),
+		Kernel:           d.Kernel,
+		Component:        d.Component,
+		ComponentVersion: d.ComponentVersion,
+		FixedVersion:     d.FixedVersion,
+		SystemVersion:    toString(d.SystemVersion),
+		Description:      d.Description,
+		ReferenceURL:     toURL(d.ReferenceURL),
+		GuidanceURL:      toURL(d.GuidanceURL),
+		Influence:        d.Influence,
+		SeverityLevel:    toString(d.SeverityLevel),
+		CVSSVector:       cvssVector,
+		CVSSScore:        cvssScore,
+		AffectedVersion:  affectedVersion,
+		ABI:              d.ABI,
+		References:       toReferencesDTO(d.References),
+		Releases:         toReleasesDTO(d.Releases),
+		CreatedAt:        d.CreatedAt.Format(time.RFC3339),
+		UpdatedAt:        d.UpdatedAt.Format(time.RFC3339),
+	}
+}
+
+func toDefectsDTO(total int, page repository.DefectsPage) DefectsDTO {
+	dto := DefectsDTO{
+		Total:      total,
+		Defects:    make([]DefectDTO, len(page.Defects)),
+		NextCursor: page.NextCursor,
+	}
+
+	for k := range page.Defects {
+		dto.Defects[k] = toDefectDTO(&page.Defects[k])
+	}
+
+	return dto
+}
+
+// CmdToAddVersion Branch is the same as Version if it is empty
+type CmdToAddVersion struct {
+	Version dp.SystemVersion
+	Branch  string
+	LTS     bool
+	SP      bool
+	GADate  time.Time
+	EOLDate time.Time
+}
+
+func (cmd *CmdToAddVersion) toVersion() domain.MaintainedVersion {
+	branch := cmd.Branch
+	if branch == "" {
+		branch = cmd.Version.String()
+	}
+
+	return domain.MaintainedVersion{
+		Version: cmd.Version,
+		Branch:  branch,
+		LTS:     cmd.LTS,
+		SP:      cmd.SP,
+		GADate:  cmd.GADate,
+		EOLDate: cmd.EOLDate,
+	}
+}
+
+// CmdToUpdateVersion nil field means the field is not changed, zero time clears the date
+type CmdToUpdateVersion struct {
+	Version dp.SystemVersion
+	Branch  *string
+	LTS     *bool
+	SP      *bool
+	GADate  *time.Time
+	EOLDate *time.Time
+	Retired *bool
+}
+
+func (cmd *CmdToUpdateVersion) apply(v *domain.MaintainedVersion) {
+	if cmd.Branch != nil {
+		v.Branch = *cmd.Branch
+	}
+
+	if cmd.LTS != nil {
+		v.LTS = *cmd.LTS
+	}
+
+	if cmd.SP != nil {
+		v.SP = *cmd.SP
+	}
+
+	if cmd.GADate != nil {
+		v.GADate = *cmd.GADate
+	}
+
+	if cmd.EOLDate != nil {
+		v.EOLDate = *cmd.EOLDate
+	}
+
+	if cmd.Retired != nil {
+		v.Retired = *cmd.Retired
+	}
+}
+
+type VersionDTO struct {
+	Version    string `json:"version"`
+	Branch     string `json:"branch"`
+	LTS        bool   `json:"lts"`
+	SP         bool   `json:"sp"`
+	GADate     string `json:"ga_date"`
+	EOLDate    string `json:"eol_date"`
+	Retired    bool   `json:"retired"`
+	Maintained bool   `json:"maintained"`
+}
+
+func toVersionDTO(v *domain.MaintainedVersion, now time.Time) VersionDTO {
+	toDate := func(t time.Time) string {
+		if t.IsZero() {
+			return ""
+		}
+
+		return t.Format("2006-01-02")
+	}
+
+	return VersionDTO{
+		Version:    v.Version.String(),
+		Branch:     v.Branch,
+		LTS:        v.LTS,
+		SP:         v.SP,
+		GADate:     toDate(v.GADate),
+		EOLDate:    toDate(v.EOLDate),
+		Retired:    v.Retired,
+		Maintained: v.IsMaintained(now),
+	}
+}
+
+// CmdToSaveMapping SystemVersion is nil if the mapping applies to all the versions
+type CmdToSaveMapping domain.ComponentMapping
+
+// ComponentMappingDTO SystemVersion is empty if the mapping applies to all the versions
+type ComponentMappingDTO struct {
+	Component     string `json:"component"`
+	SystemVersion string `json:"system_version"`
+	SourcePackage string `json:"source_package"`
+}
+
+func toComponentMappingDTO(m *domain.ComponentMapping) ComponentMappingDTO {
+	dto := ComponentMappingDTO{
+		Component:     m.Component,
+		SourcePackage: m.SourcePackage,
+	}
+
+	if m.SystemVersion != nil {
+		dto.SystemVersion = m.SystemVersion.String()
 	}
 
 	return dto
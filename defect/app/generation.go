@@ -0,0 +1,174 @@
+package app
+
+import (
+	"encoding/json"
+	"errors"
+	"sync"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+
+	"github.com/opensourceways/defect-manager/defect/domain"
+	"github.com/opensourceways/defect-manager/defect/domain/repository"
+	"github.com/opensourceways/defect-manager/utils"
+)
+
+var (
+	ErrGenerationNotFound = repository.ErrGenerationNotFound
+	ErrTooManyGenerations = errors.New("too many generations are waiting, try later")
+)
+
+const (
+	// generationQueueSize is the max number of generations waiting for the running one
+	generationQueueSize = 16
+
+	errGenerationStopped = "the service stopped before the generation ran"
+)
+
+// GenerationService runs the generations requested by the api in the background,
+// the result is persisted so it can be queried after the request returns.
+type GenerationService interface {
+	SubmitGeneration(CmdToGenerateBulletins) (GenerationDTO, error)
+	GetGeneration(id string) (GenerationDTO, error)
+}
+
+type generationTask struct {
+	generation domain.Generation
+	cmd        CmdToGenerateBulletins
+}
+
+// NewGenerationService the generations are run one by one because they are serialized anyway
+func NewGenerationService(r repository.GenerationRepository, s DefectService, clock utils.Clock) *generationService {
+	return &generationService{
+		repo:    r,
+		service: s,
+		queue:   make(chan generationTask, generationQueueSize),
+		clock:   clock,
+	}
+}
+
+type generationService struct {
+	repo    repository.GenerationRepository
+	service DefectService
+	queue   chan generationTask
+	clock   utils.Clock
+
+	stop chan struct{}
+	wg   sync.WaitGroup
+}
+
+func (s *generationService) SubmitGeneration(cmd CmdToGenerateBulletins) (GenerationDTO, error) {
+	id, err := uuid.NewRandom()
+	if err != nil {
+		return GenerationDTO{}, err
+	}
+
+	g := domain.NewGeneration(id.String(), append(keysOf(cmd.Issues), cmd.Numbers...), s.clock.Now())
+
+	if len(s.queue) == cap(s.queue) {
+		return GenerationDTO{}, ErrTooManyGenerations
+	}
+
+	if err := s.repo.SaveGeneration(&g); err != nil {
+		return GenerationDTO{}, err
+	}
+
+	select {
+	case s.queue <- generationTask{generation: g, cmd: cmd}:
+	default:
+		s.finish(&g, nil, ErrTooManyGenerations)
+
+		return GenerationDTO{}, ErrTooManyGenerations
+	}
+
+	return toGenerationDTO(&g), nil
+}
+
+func (s *generationService) GetGeneration(id string) (GenerationDTO, error) {
+	g, err := s.repo.FindGeneration(id)
+	if err != nil {
+		return GenerationDTO{}, err
+	}
+
+	return toGenerationDTO(&g), nil
+}
+
+func (s *generationService) Start() {
+	if s.stop != nil {
+		return
+	}
+
+	s.stop = make(chan struct{})
+	s.wg.Add(1)
+
+	go func() {
+		defer s.wg.Done()
+
+		s.loop()
+	}()
+}
+
+// Stop waits for the running generation, the waiting ones are recorded as failed
+func (s *generationService) Stop() {
+	if s.stop == nil {
+		return
+	}
+
+	close(s.stop)
+	s.wg.Wait()
+
+	for {
+		select {
+		case t := <-s.queue:
+			s.finish(&t.generation, nil, errors.New(errGenerationStopped))
+
+		default:
+			return
+		}
+	}
+}
+
+func (s *generationService) loop() {
+	for {
+		select {
+		case <-s.stop:
+			return
+
+		case t := <-s.queue:
+			s.run(&t)
+		}
+	}
+}
+
+func (s *generationService) run(t *generationTask) {
+	report, err := s.service.GenerateBulletins(t.cmd)
+	if err != nil {
+		logrus.Errorf("generation %s error: %s", t.generation.ID, err.Error())
+
+		s.finish(&t.generation, nil, err)
+
+		return
+	}
+
+	logrus.Infof(
+		"generation %s, %d bulletins, %d excluded",
+		t.generation.ID, len(report.Bulletins), len(report.Excluded),
+	)
+
+	s.finish(&t.generation, &report, nil)
+}
+
+func (s *generationService) finish(g *domain.Generation, report *BulletinsReportDTO, err error) {
+	var data []byte
+	if err == nil {
+		if data, err = json.Marshal(report); err != nil {
+			err = errors.New("marshal the report error: " + err.Error())
+		}
+	}
+
+	g.Done(data, err, s.clock.Now())
+
+	if err := s.repo.SaveGeneration(g); err != nil {
+		logrus.Errorf("save the result of generation %s error: %s", g.ID, err.Error())
+	}
+}
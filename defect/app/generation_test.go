@@ -0,0 +1,143 @@
+package app
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/opensourceways/defect-manager/defect/domain"
+	"github.com/opensourceways/defect-manager/defect/domain/repository"
+	"github.com/opensourceways/defect-manager/utils"
+)
+
+type fakeGenerations struct {
+	lock  sync.Mutex
+	items map[string]domain.Generation
+}
+
+func (r *fakeGenerations) SaveGeneration(g *domain.Generation) error {
+	r.lock.Lock()
+	r.items[g.ID] = *g
+	r.lock.Unlock()
+
+	return nil
+}
+
+func (r *fakeGenerations) FindGeneration(id string) (domain.Generation, error) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	g, ok := r.items[id]
+	if !ok {
+		return g, repository.ErrGenerationNotFound
+	}
+
+	return g, nil
+}
+
+// blockedService blocks the generation until release is closed
+type blockedService struct {
+	DefectService
+
+	release chan struct{}
+}
+
+func (s blockedService) GenerateBulletins(cmd CmdToGenerateBulletins) (BulletinsReportDTO, error) {
+	<-s.release
+
+	if len(cmd.Issues) == 0 {
+		return BulletinsReportDTO{}, errors.New("no issue")
+	}
+
+	return BulletinsReportDTO{
+		Bulletins: []BulletinDTO{{Identification: "cvrf-openEuler-BA-2023-1001", Issues: keysOf(cmd.Issues)}},
+	}, nil
+}
+
+func waitGeneration(t *testing.T, s *generationService, id, status string) GenerationDTO {
+	for i := 0; i < 100; i++ {
+		v, err := s.GetGeneration(id)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if v.Status == status {
+			return v
+		}
+
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	t.Fatalf("generation %s is not %s", id, status)
+
+	return GenerationDTO{}
+}
+
+func TestGeneration(t *testing.T) {
+	release := make(chan struct{})
+	s := NewGenerationService(
+		&fakeGenerations{items: map[string]domain.Generation{}}, blockedService{release: release}, utils.SystemClock{},
+	)
+
+	s.Start()
+
+	issue := domain.Issue{Org: "src-openeuler", Repo: "zbar", Number: "I1"}
+
+	ok, err := s.SubmitGeneration(CmdToGenerateBulletins{Issues: []domain.Issue{issue}})
+	if err != nil || ok.Status != domain.GenerationRunning {
+		t.Fatalf("submit, got %v, %v", ok, err)
+	}
+
+	failed, err := s.SubmitGeneration(CmdToGenerateBulletins{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	close(release)
+
+	if v := waitGeneration(t, s, ok.ID, domain.GenerationSucceeded); v.Report == nil || len(v.Report.Bulletins) != 1 {
+		t.Errorf("unexpected report: %v", v)
+	}
+
+	if v := waitGeneration(t, s, failed.ID, domain.GenerationFailed); v.Error != "no issue" {
+		t.Errorf("unexpected error: %s", v.Error)
+	}
+
+	s.Stop()
+
+	if _, err := s.GetGeneration("unknown"); !errors.Is(err, ErrGenerationNotFound) {
+		t.Errorf("expect not found, got %v", err)
+	}
+}
+
+func TestGenerationQueueFull(t *testing.T) {
+	s := NewGenerationService(
+		&fakeGenerations{items: map[string]domain.Generation{}}, blockedService{}, utils.SystemClock{},
+	)
+
+	// it is not started, so the generations wait in the queue
+	var ids []string
+	for i := 0; i < generationQueueSize; i++ {
+		v, err := s.SubmitGeneration(CmdToGenerateBulletins{})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		ids = append(ids, v.ID)
+	}
+
+	if _, err := s.SubmitGeneration(CmdToGenerateBulletins{}); !errors.Is(err, ErrTooManyGenerations) {
+		t.Fatalf("expect too many generations, got %v", err)
+	}
+
+	// stop it as if the worker had exited
+	s.stop = make(chan struct{})
+	s.Stop()
+
+	for _, id := range ids {
+		if v, _ := s.GetGeneration(id); v.Status != domain.GenerationFailed || v.Error != errGenerationStopped {
+			t.Errorf("the waiting generation must fail when stopped, got %v", v)
+		}
+	}
+}
@@ -0,0 +1,394 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	"github.com/opensourceways/defect-manager/defect/domain"
+	"github.com/opensourceways/defect-manager/defect/domain/notifier"
+	"github.com/opensourceways/defect-manager/defect/domain/repository"
+	"github.com/opensourceways/defect-manager/defect/domain/sig"
+	"github.com/opensourceways/defect-manager/utils"
+)
+
+// eventQueueSize is the max number of events waiting to be sent, the later ones are dropped
+const eventQueueSize = 64
+
+var (
+	ErrNotificationNotFound = repository.ErrNotificationNotFound
+	ErrNotificationDisabled = errors.New("notification is disabled")
+)
+
+type NotificationService interface {
+	ListNotifications(status string) ([]NotificationDTO, error)
+	RetryNotification(identification string) (NotificationDTO, error)
+}
+
+// NewNotificationService n is nil if the notification is disabled. The pending notifications are
+// sent by a background worker, a notification is tried at most maxAttempts times by it and the interval
+// is doubled after each failure. The events are sent to the subscriptions via the channels by name,
+// s is used to match the sigs.
+func NewNotificationService(
+	r repository.NotificationRepository,
+	n notifier.Notifier,
+	maxAttempts int,
+	interval time.Duration,
+	subs []domain.Subscription,
+	channels map[string]notifier.Channel,
+	s sig.SIG,
+) *notificationService {
+	return &notificationService{
+		repo:          r,
+		notifier:      n,
+		maxAttempts:   maxAttempts,
+		interval:      interval,
+		subscriptions: subs,
+		channels:      channels,
+		sig:           s,
+		clock:         utils.SystemClock{},
+		wake:          make(chan struct{}, 1),
+		events:        make(chan domain.Event, eventQueueSize),
+	}
+}
+
+type notificationService struct {
+	repo          repository.NotificationRepository
+	notifier      notifier.Notifier
+	maxAttempts   int
+	interval      time.Duration
+	subscriptions []domain.Subscription
+	channels      map[string]notifier.Channel
+	sig           sig.SIG
+	clock         utils.Clock
+
+	wake   chan struct{}
+	events chan domain.Event
+	stop   chan struct{}
+	wg     sync.WaitGroup
+}
+
+func (s *notificationService) isEnabled() bool {
+	return s != nil && s.notifier != nil
+}
+
+// Start runs the worker which sends the pending notifications if the notification is enabled,
+// and the one which sends the events if there are subscriptions.
+func (s *notificationService) Start() {
+	if s.stop != nil {
+		return
+	}
+
+	s.stop = make(chan struct{})
+
+	if s.isEnabled() {
+		s.wg.Add(1)
+
+		go func() {
+			defer s.wg.Done()
+
+			s.loop()
+		}()
+	}
+
+	if len(s.subscriptions) > 0 {
+		s.wg.Add(1)
+
+		go func() {
+			defer s.wg.Done()
+
+			s.eventLoop()
+		}()
+	}
+}
+
+// Stop waits for the notification being sent and the queued events, the pending
+// notifications are sent after restarting.
+func (s *notificationService) Stop() {
+	if s == nil || s.stop == nil {
+		return
+	}
+
+	close(s.stop)
+	s.wg.Wait()
+}
+
+func (s *notificationService) loop() {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.stop:
+			return
+
+		case <-ticker.C:
+		case <-s.wake:
+		}
+
+		s.sendPending()
+	}
+}
+
+// add saves the notification as pending and wakes the worker up,
+// it must be called after the bulletin is listed by the index.
+func (s *notificationService) add(n *domain.Notification) error {
+	if err := s.repo.SaveNotification(n); err != nil {
+		return err
+	}
+
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+
+	return nil
+}
+
+// sendPending tries each pending notification which is due once. Only one replica
+// sends at a time, the others skip the round. The downstream must still ignore
+// a bulletin it has since a manual retry may send the same one.
+func (s *notificationService) sendPending() {
+	unlock, locked, err := s.repo.LockSender()
+	if err != nil {
+		logrus.Errorf("lock the sender of notifications error: %s", err.Error())
+
+		return
+	}
+
+	if !locked {
+		return
+	}
+
+	defer unlock()
+
+	ns, err := s.repo.FindNotifications(domain.NotificationPending)
+	if err != nil {
+		logrus.Errorf("find the pending notifications error: %s", err.Error())
+
+		return
+	}
+
+	now := s.clock.Now()
+	for i := range ns {
+		if ns[i].IsDue(now, s.interval) {
+			s.send(&ns[i])
+		}
+	}
+}
+
+// send tries once and saves the status
+func (s *notificationService) send(n *domain.Notification) {
+	err := s.notifier.Notify(n)
+	n.Record(err, s.clock.Now(), s.maxAttempts)
+
+	if err != nil {
+		logrus.Errorf("notify bulletin %s the %d time error: %s", n.Identification, n.Attempts, err.Error())
+	}
+
+	if err := s.repo.SaveNotification(n); err != nil {
+		logrus.Errorf("save notification of %s error: %s", n.Identification, err.Error())
+	}
+}
+
+func (s *notificationService) ListNotifications(status string) ([]NotificationDTO, error) {
+	ns, err := s.repo.FindNotifications(status)
+	if err != nil {
+		return nil, err
+	}
+
+	dto := make([]NotificationDTO, len(ns))
+	for i := range ns {
+		dto[i] = toNotificationDTO(&ns[i])
+	}
+
+	return dto, nil
+}
+
+// RetryNotification tries once immediately, even if the attempts have run out
+func (s *notificationService) RetryNotification(identification string) (NotificationDTO, error) {
+	if !s.isEnabled() {
+		return NotificationDTO{}, ErrNotificationDisabled
+	}
+
+	n, err := s.repo.FindNotification(identification)
+	if err != nil {
+		return NotificationDTO{}, err
+	}
+
+	if !n.IsSucceeded() {
+		s.send(&n)
+	}
+
+	return toNotificationDTO(&n), nil
+}
+
+func (s *notificationService) hasSubscriptions() bool {
+	return s != nil && len(s.subscriptions) > 0
+}
+
+// emit queues the event for the worker, so the caller is not blocked by the channels
+func (s *notificationService) emit(e domain.Event) {
+	if !s.hasSubscriptions() {
+		return
+	}
+
+	select {
+	case s.events <- e:
+	default:
+		logrus.Warnf("too many events are waiting, drop %s: %s", e.Type, e.Title)
+	}
+}
+
+// eventLoop sends the queued events before it exits
+func (s *notificationService) eventLoop() {
+	for {
+		select {
+		case e := <-s.events:
+			s.publish(&e)
+
+		case <-s.stop:
+			for {
+				select {
+				case e := <-s.events:
+					s.publish(&e)
+
+				default:
+					return
+				}
+			}
+		}
+	}
+}
+
+// publish sends the event to each matched subscription once, the failures are only logged
+func (s *notificationService) publish(e *domain.Event) {
+	var sigs []string
+	if s.needSIG() {
+		sigs = s.sigsOf(e.Repos)
+	}
+
+	for i := range s.subscriptions {
+		sub := &s.subscriptions[i]
+		if !sub.Match(e, sigs) {
+			continue
+		}
+
+		ch, ok := s.channels[sub.Channel]
+		if !ok {
+			logrus.Errorf("subscription %s, unknown channel %s", sub.Name, sub.Channel)
+
+			continue
+		}
+
+		if err := ch.Send(e, sub.Recipients); err != nil {
+			logrus.Errorf("send %s to subscription %s error: %s", e.Type, sub.Name, err.Error())
+		}
+	}
+}
+
+func (s *notificationService) needSIG() bool {
+	if s.sig == nil {
+		return false
+	}
+
+	for i := range s.subscriptions {
+		if len(s.subscriptions[i].SIGs) > 0 {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (s *notificationService) sigsOf(repos []string) []string {
+	var sigs []string
+	for _, repo := range repos {
+		v, err := s.sig.SIGOfRepo(repo)
+		if err != nil {
+			logrus.Errorf("get sig of %s error: %s", repo, err.Error())
+
+			continue
+		}
+
+		if v != "" {
+			sigs = append(sigs, v)
+		}
+	}
+
+	return sigs
+}
+
+func issueURL(i *domain.Issue) string {
+	return fmt.Sprintf("%s/%s/%s/issues/%s", giteeUrl, i.Org, i.Repo, i.Number)
+}
+
+func defectAcceptedEvent(d *domain.Defect, now time.Time) domain.Event {
+	versions := make([]string, len(d.AffectedVersion))
+	for i, v := range d.AffectedVersion {
+		versions[i] = v.String()
+	}
+
+	var severity []string
+	if d.SeverityLevel != nil {
+		severity = []string{d.SeverityLevel.String()}
+	}
+
+	lines := []string{
+		"Component: " + d.Component,
+		"Severity: " + strings.Join(severity, ""),
+		"Affected versions: " + strings.Join(versions, ", "),
+		"Issue: " + issueURL(&d.Issue),
+	}
+
+	return domain.Event{
+		Type:       domain.EventDefectAccepted,
+		Title:      fmt.Sprintf("Defect %s of %s is accepted", d.Issue.Number, d.Component),
+		Content:    strings.Join(lines, "\n"),
+		Components: []string{d.Component},
+		Repos:      []string{d.Issue.RepoPath()},
+		Severity:   severity,
+		OccurredAt: now,
+	}
+}
+
+func bulletinGeneratedEvent(sb *domain.SecurityBulletin) domain.Event {
+	versions := make([]string, len(sb.AffectedVersion))
+	for i, v := range sb.AffectedVersion {
+		versions[i] = v.String()
+	}
+
+	repos, severity := sets.NewString(), sets.NewString()
+	issues := make([]string, len(sb.Defects))
+	for i := range sb.Defects {
+		d := &sb.Defects[i]
+
+		repos.Insert(d.Issue.RepoPath())
+		if d.SeverityLevel != nil {
+			severity.Insert(d.SeverityLevel.String())
+		}
+
+		issues[i] = issueURL(&d.Issue)
+	}
+
+	lines := []string{
+		"Component: " + sb.Component,
+		"Affected versions: " + strings.Join(versions, ", "),
+		"Issues:",
+	}
+
+	return domain.Event{
+		Type:       domain.EventBulletinGenerated,
+		Title:      fmt.Sprintf("Bulletin %s of %s is generated", sb.Identification, sb.Component),
+		Content:    strings.Join(append(lines, issues...), "\n"),
+		Components: sb.Components(),
+		Repos:      repos.List(),
+		Severity:   severity.List(),
+		OccurredAt: sb.Date,
+	}
+}
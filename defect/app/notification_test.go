@@ -0,0 +1,95 @@
+package app
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/opensourceways/defect-manager/defect/domain"
+	"github.com/opensourceways/defect-manager/defect/domain/dp"
+	"github.com/opensourceways/defect-manager/defect/domain/notifier"
+)
+
+type recordChannel struct {
+	sent []string
+}
+
+func (ch *recordChannel) Send(e *domain.Event, recipients []string) error {
+	ch.sent = append(ch.sent, e.Type+":"+strings.Join(recipients, ","))
+
+	return nil
+}
+
+type sigOfRepo map[string]string
+
+func (s sigOfRepo) SIGOfRepo(repo string) (string, error) {
+	return s[repo], nil
+}
+
+func TestPublishEvent(t *testing.T) {
+	mail, im := new(recordChannel), new(recordChannel)
+
+	subs := []domain.Subscription{
+		{Name: "security", Channel: "mail", Severity: []string{"Critical", "High"}, Recipients: []string{"sec@x"}},
+		{Name: "sig-a", Channel: "im", SIGs: []string{"sig-a"}, Events: []string{domain.EventBulletinGenerated}},
+		{Name: "curl", Channel: "mail", Components: []string{"curl"}, Recipients: []string{"curl@x"}},
+	}
+
+	s := NewNotificationService(
+		nil, nil, 0, 0, subs,
+		map[string]notifier.Channel{"mail": mail, "im": im},
+		sigOfRepo{"src-openeuler/zbar": "sig-a"},
+	)
+
+	high, _ := dp.NewSeverityLevel("High")
+	version, _ := dp.NewSystemVersion("openEuler-22.03-LTS")
+	d := domain.Defect{
+		Component:       "zbar",
+		SeverityLevel:   high,
+		AffectedVersion: []dp.SystemVersion{version},
+		Issue:           domain.Issue{Org: "src-openeuler", Repo: "zbar", Number: "I1"},
+	}
+
+	e := defectAcceptedEvent(&d, time.Now())
+	s.publish(&e)
+
+	sb := domain.SecurityBulletin{
+		Identification:  "cvrf-openEuler-BA-2023-1001",
+		Component:       "zbar",
+		AffectedVersion: []dp.SystemVersion{version},
+		Defects:         domain.Defects{d},
+	}
+
+	e = bulletinGeneratedEvent(&sb)
+	s.publish(&e)
+
+	want := "defect_accepted:sec@x bulletin_generated:sec@x"
+	if got := strings.Join(mail.sent, " "); got != want {
+		t.Errorf("mail got %s, want %s", got, want)
+	}
+
+	if got := strings.Join(im.sent, " "); got != "bulletin_generated:" {
+		t.Errorf("im got %s", got)
+	}
+
+	if !strings.Contains(e.Content, "https://gitee.com/src-openeuler/zbar/issues/I1") {
+		t.Errorf("unexpected content: %s", e.Content)
+	}
+}
+
+func TestEmitEvents(t *testing.T) {
+	mail := new(recordChannel)
+	subs := []domain.Subscription{{Name: "all", Channel: "mail", Recipients: []string{"all@x"}}}
+	s := NewNotificationService(nil, nil, 0, 0, subs, map[string]notifier.Channel{"mail": mail}, nil)
+
+	// the queued events are sent before it stops
+	s.emit(domain.Event{Type: domain.EventDefectAccepted})
+	s.emit(domain.Event{Type: domain.EventBulletinGenerated})
+
+	s.Start()
+	s.Stop()
+
+	if got := strings.Join(mail.sent, " "); got != "defect_accepted:all@x bulletin_generated:all@x" {
+		t.Errorf("got %s", got)
+	}
+}
@@ -0,0 +1,133 @@
+package app
+
+import (
+	"errors"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/opensourceways/defect-manager/defect/domain"
+	"github.com/opensourceways/defect-manager/defect/domain/dp"
+	"github.com/opensourceways/defect-manager/defect/domain/producttree"
+)
+
+// CheckRelease checks and records whether the fixed package is released in each affected version
+func (d defectService) CheckRelease(issue *domain.Issue) ([]ReleaseDTO, error) {
+	defect, err := d.repo.FindDefect(issue)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = d.checkRelease(&defect, make(map[string]*dp.NEVRA)); err != nil {
+		return nil, err
+	}
+
+	return toReleasesDTO(defect.Releases), nil
+}
+
+// checkReleases the previous release states of a defect are kept if its product tree is unavailable
+func (d defectService) checkReleases(ds domain.Defects) {
+	latest := make(map[string]*dp.NEVRA)
+	for i := range ds {
+		defect := &ds[i]
+
+		if err := d.checkRelease(defect, latest); err != nil {
+			logrus.Errorf("check release of issue %s error: %s", defect.Issue.Number, err.Error())
+
+			continue
+		}
+
+		for _, r := range defect.Releases {
+			if !r.IsReleased() {
+				logrus.Infof("issue %s is blocked in %s, the package is %s", defect.Issue.Number, r.Version.String(), r.Status)
+			}
+		}
+	}
+}
+
+func (d defectService) checkRelease(defect *domain.Defect, latest map[string]*dp.NEVRA) error {
+	now := d.clock.Now()
+	rs := make([]domain.Release, 0, len(defect.AffectedVersion))
+	for _, v := range defect.AffectedVersion {
+		rpm, err := d.latestRPM(defect.Component, v, latest)
+		if err != nil {
+			return err
+		}
+
+		rs = append(rs, defect.CheckRelease(v, rpm, now))
+	}
+
+	if err := d.repo.SaveReleases(&defect.Issue, rs); err != nil {
+		return err
+	}
+
+	defect.Releases = rs
+
+	return nil
+}
+
+// recordBaselines records the newest rpm of each affected version which has no baseline,
+// the fixed package is released once a newer rpm is published. Nothing is recorded without
+// the product tree, the release is checked by ComponentVersion then.
+func (d defectService) recordBaselines(issue *domain.Issue) error {
+	if d.productTree == nil {
+		return nil
+	}
+
+	defect, err := d.repo.FindDefect(issue)
+	if err != nil {
+		return err
+	}
+
+	now := d.clock.Now()
+	latest := make(map[string]*dp.NEVRA)
+	rs := make([]domain.Release, 0, len(defect.AffectedVersion))
+	changed := false
+	for _, v := range defect.AffectedVersion {
+		if r := defect.ReleaseOf(v); r != nil && r.Baseline != "" {
+			rs = append(rs, *r)
+			continue
+		}
+
+		rpm, err := d.latestRPM(defect.Component, v, latest)
+		if err != nil {
+			return err
+		}
+
+		rs = append(rs, defect.BaselineRelease(v, rpm, now))
+		changed = true
+	}
+
+	if !changed {
+		return nil
+	}
+
+	return d.repo.SaveReleases(issue, rs)
+}
+
+// latestRPM returns the newest rpm of the component listed by the product tree,
+// it is nil if the component is not found in the version. cache is keyed by component and version.
+func (d defectService) latestRPM(component string, version dp.SystemVersion, cache map[string]*dp.NEVRA) (
+	*dp.NEVRA, error,
+) {
+	key := component + "/" + version.String()
+	if v, ok := cache[key]; ok {
+		return v, nil
+	}
+
+	tree, err := d.getTree(component, []dp.SystemVersion{version})
+	if err != nil && !errors.As(err, &producttree.ComponentNotFoundError{}) {
+		return nil, err
+	}
+
+	var r *dp.NEVRA
+	products := sourceProducts(tree)
+	for i := range products {
+		if rpm := &products[i].RPM; r == nil || dp.CompareEVR(rpm.EVR(), r.EVR()) > 0 {
+			r = rpm
+		}
+	}
+
+	cache[key] = r
+
+	return r, nil
+}
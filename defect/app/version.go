@@ -0,0 +1,126 @@
+package app
+
+import (
+	"errors"
+	"time"
+
+	"github.com/opensourceways/defect-manager/defect/domain/dp"
+	"github.com/opensourceways/defect-manager/defect/domain/repository"
+)
+
+var (
+	ErrVersionNotFound = repository.ErrVersionNotFound
+	ErrVersionExists   = repository.ErrVersionExists
+	// ErrNoMaintainedVersion means the registry is empty or all the versions are retired,
+	// it is an error because every defect would be filtered out silently otherwise.
+	ErrNoMaintainedVersion = errors.New("no version is maintained, add the versions first")
+)
+
+type VersionService interface {
+	InitVersions([]string) error
+	ListVersions() ([]VersionDTO, error)
+	AddVersion(CmdToAddVersion) error
+	UpdateVersion(CmdToUpdateVersion) error
+	RetireVersion(dp.SystemVersion) error
+	MaintainedVersions() ([]dp.SystemVersion, error)
+}
+
+func NewVersionService(r repository.VersionRepository) *versionService {
+	return &versionService{repo: r}
+}
+
+type versionService struct {
+	repo repository.VersionRepository
+}
+
+// InitVersions adds the versions only when there is no version, it migrates the versions in config
+func (s versionService) InitVersions(versions []string) error {
+	vs, err := s.repo.FindVersions()
+	if err != nil || len(vs) > 0 {
+		return err
+	}
+
+	for _, v := range versions {
+		version, err := dp.NewSystemVersion(v)
+		if err != nil {
+			return err
+		}
+
+		err = s.AddVersion(CmdToAddVersion{Version: version})
+		if err != nil && !errors.Is(err, ErrVersionExists) {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (s versionService) ListVersions() ([]VersionDTO, error) {
+	vs, err := s.repo.FindVersions()
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+
+	r := make([]VersionDTO, len(vs))
+	for i := range vs {
+		r[i] = toVersionDTO(&vs[i], now)
+	}
+
+	return r, nil
+}
+
+func (s versionService) AddVersion(cmd CmdToAddVersion) error {
+	v := cmd.toVersion()
+
+	now := time.Now()
+	v.CreatedAt = now
+	v.UpdatedAt = now
+
+	return s.repo.AddVersion(&v)
+}
+
+func (s versionService) UpdateVersion(cmd CmdToUpdateVersion) error {
+	v, err := s.repo.FindVersion(cmd.Version)
+	if err != nil {
+		return err
+	}
+
+	cmd.apply(&v)
+	v.UpdatedAt = time.Now()
+
+	return s.repo.SaveVersion(&v)
+}
+
+func (s versionService) RetireVersion(version dp.SystemVersion) error {
+	v, err := s.repo.FindVersion(version)
+	if err != nil {
+		return err
+	}
+
+	v.Retired = true
+	v.UpdatedAt = time.Now()
+
+	return s.repo.SaveVersion(&v)
+}
+
+// MaintainedVersions excludes the retired versions and the ones after EOL,
+// it returns ErrNoMaintainedVersion if there is none.
+func (s versionService) MaintainedVersions() ([]dp.SystemVersion, error) {
+	return maintainedVersions(s.repo)
+}
+
+func maintainedVersions(repo repository.VersionRepository) ([]dp.SystemVersion, error) {
+	vs, err := repo.FindVersions()
+	if err != nil {
+		return nil, err
+	}
+
+	r := vs.Maintained(time.Now())
+	if len(r) == 0 {
+		return nil, ErrNoMaintainedVersion
+	}
+
+	return r, nil
+}
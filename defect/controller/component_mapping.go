@@ -0,0 +1,159 @@
+package controller
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/opensourceways/server-common-lib/controller"
+
+	authcontroller "github.com/opensourceways/defect-manager/auth/controller"
+	authdomain "github.com/opensourceways/defect-manager/auth/domain"
+	authdp "github.com/opensourceways/defect-manager/auth/domain/dp"
+	"github.com/opensourceways/defect-manager/defect/app"
+	"github.com/opensourceways/defect-manager/defect/domain/dp"
+)
+
+// mappingRequest system_version is empty if the mapping applies to all the versions
+type mappingRequest struct {
+	Component     string `json:"component"       binding:"required"`
+	SystemVersion string `json:"system_version"`
+	SourcePackage string `json:"source_package"  binding:"required"`
+}
+
+func (req *mappingRequest) toCmd() (cmd app.CmdToSaveMapping, err error) {
+	if cmd.SystemVersion, err = optionalSystemVersion(req.SystemVersion); err != nil {
+		return
+	}
+
+	cmd.Component = req.Component
+	cmd.SourcePackage = req.SourcePackage
+
+	return
+}
+
+func optionalSystemVersion(s string) (dp.SystemVersion, error) {
+	if s == "" {
+		return nil, nil
+	}
+
+	return dp.NewSystemVersion(s)
+}
+
+type ComponentMappingController struct {
+	service app.ComponentMappingService
+	auth    *authcontroller.AuthMiddleware
+}
+
+func AddRouteForComponentMappingController(
+	r *gin.RouterGroup, s app.ComponentMappingService, auth *authcontroller.AuthMiddleware,
+) {
+	ctl := ComponentMappingController{
+		service: s,
+		auth:    auth,
+	}
+
+	r.GET("/v1/component-mappings", auth.Require(authdp.ScopeRead), ctl.List)
+	r.PUT("/v1/component-mappings", auth.Require(authdp.ScopeAdmin), ctl.Save)
+	r.DELETE("/v1/component-mappings/:component", auth.Require(authdp.ScopeAdmin), ctl.Delete)
+}
+
+// List
+// @Summary list component mappings
+// @Description list the mappings from component to source package in the table, the ones in config are not included
+// @Tags  ComponentMapping
+// @Accept json
+// @Security PrivateToken
+// @Success 200 {object} []app.ComponentMappingDTO
+// @Failure 401 {object} string
+// @Failure 403 {object} string
+// @Router /v1/component-mappings [get]
+func (ctl ComponentMappingController) List(ctx *gin.Context) {
+	if v, err := ctl.service.ListMappings(); err != nil {
+		controller.SendFailedResp(ctx, "", err)
+	} else {
+		controller.SendRespOfGet(ctx, v)
+	}
+}
+
+// Save
+// @Summary save a component mapping
+// @Description add the mapping or update its source package, only for admin
+// @Tags  ComponentMapping
+// @Accept json
+// @Param	param  body  mappingRequest  true  "body of mapping"
+// @Security PrivateToken
+// @Success 202 {object} string
+// @Failure 400 {object} string
+// @Failure 401 {object} string
+// @Failure 403 {object} string
+// @Router /v1/component-mappings [put]
+func (ctl ComponentMappingController) Save(ctx *gin.Context) {
+	var req mappingRequest
+	if err := ctx.ShouldBindBodyWith(&req, binding.JSON); err != nil {
+		controller.SendBadRequestBody(ctx, err)
+
+		return
+	}
+
+	cmd, err := req.toCmd()
+	if err != nil {
+		controller.SendBadRequestBody(ctx, err)
+
+		return
+	}
+
+	ctl.auth.Audit(
+		ctx, authdomain.AuditActionSaveMapping,
+		req.Component+"@"+req.SystemVersion+" -> "+req.SourcePackage,
+	)
+
+	if err := ctl.service.SaveMapping(cmd); err != nil {
+		controller.SendFailedResp(ctx, "", err)
+	} else {
+		controller.SendRespOfPut(ctx)
+	}
+}
+
+// Delete
+// @Summary delete a component mapping
+// @Description delete the mapping of the component in the version, only for admin
+// @Tags  ComponentMapping
+// @Accept json
+// @Param	component       path   string  true   "component"
+// @Param	system_version  query  string  false  "the mapping of all the versions if empty"
+// @Security PrivateToken
+// @Success 204
+// @Failure 400 {object} string
+// @Failure 401 {object} string
+// @Failure 403 {object} string
+// @Failure 404 {object} string
+// @Router /v1/component-mappings/{component} [delete]
+func (ctl ComponentMappingController) Delete(ctx *gin.Context) {
+	version, err := optionalSystemVersion(ctx.Query("system_version"))
+	if err != nil {
+		controller.SendBadRequestParam(ctx, err)
+
+		return
+	}
+
+	component := ctx.Param("component")
+
+	ctl.auth.Audit(ctx, authdomain.AuditActionDeleteMapping, component+"@"+ctx.Query("system_version"))
+
+	err = ctl.service.DeleteMapping(component, version)
+	switch {
+	case err == nil:
+		ctx.Status(http.StatusNoContent)
+
+	case errors.Is(err, app.ErrMappingNotFound):
+		ctx.JSON(http.StatusNotFound, controller.ResponseData{
+			Code: errorNotFound,
+			Msg:  err.Error(),
+		})
+
+	default:
+		controller.SendFailedResp(ctx, "", err)
+	}
+}
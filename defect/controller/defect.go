@@ -1,6 +1,9 @@
 package controller
 
 import (
+	"errors"
+	"net/http"
+	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
@@ -8,20 +11,42 @@ import (
 	"github.com/opensourceways/server-common-lib/controller"
 	"github.com/sirupsen/logrus"
 
+	authcontroller "github.com/opensourceways/defect-manager/auth/controller"
+	authdomain "github.com/opensourceways/defect-manager/auth/domain"
+	authdp "github.com/opensourceways/defect-manager/auth/domain/dp"
 	"github.com/opensourceways/defect-manager/defect/app"
+	"github.com/opensourceways/defect-manager/defect/domain"
+)
+
+const (
+	errorNotFound        = "not_found"
+	errorTooManyRequests = "too_many_requests"
 )
 
 type DefectController struct {
-	service app.DefectService
+	service     app.DefectService
+	generations app.GenerationService
+	auth        *authcontroller.AuthMiddleware
 }
 
-func AddRouteForDefectController(r *gin.RouterGroup, s app.DefectService) {
+func AddRouteForDefectController(
+	r *gin.RouterGroup, s app.DefectService, g app.GenerationService, auth *authcontroller.AuthMiddleware,
+) {
 	ctl := DefectController{
-		service: s,
+		service:     s,
+		generations: g,
+		auth:        auth,
 	}
 
-	r.GET("/v1/defect", ctl.Collect)
-	r.POST("/v1/defect/bulletin", ctl.GenerateBulletin)
+	r.GET("/v1/defect", auth.Require(authdp.ScopeRead), ctl.Collect)
+	r.POST("/v1/defect/bulletin", auth.Require(authdp.ScopeGenerate), ctl.GenerateBulletin)
+	r.GET("/v1/defect/bulletin/generations/:id", auth.Require(authdp.ScopeRead), ctl.GetGeneration)
+
+	r.GET("/v1/defects", auth.Require(authdp.ScopeRead), ctl.List)
+	r.GET("/v1/defects/:org/:repo/:number", auth.Require(authdp.ScopeRead), ctl.Get)
+	r.PATCH("/v1/defects/:org/:repo/:number", auth.Require(authdp.ScopeAdmin), ctl.Update)
+	r.DELETE("/v1/defects/:org/:repo/:number", auth.Require(authdp.ScopeAdmin), ctl.Delete)
+	r.POST("/v1/defects/:org/:repo/:number/release", auth.Require(authdp.ScopeGenerate), ctl.CheckRelease)
 }
 
 // Collect
@@ -29,19 +54,35 @@ func AddRouteForDefectController(r *gin.RouterGroup, s app.DefectService) {
 // @Description collect information of some defects
 // @Tags  Defect
 // @Accept json
-// @Param	date  query string	 true	"collect defects after the date"
+// @Param	date      query string	 true	"collect defects after the date"
+// @Param	end_date  query string	 false	"collect defects until the date, inclusive"
+// @Security PrivateToken
 // @Success 200 {object} []app.CollectDefectsDTO
 // @Failure 400 {object} string
+// @Failure 401 {object} string
+// @Failure 403 {object} string
 // @Router /v1/defect [get]
 func (ctl DefectController) Collect(ctx *gin.Context) {
-	date, err := time.Parse("2006-01-02", ctx.Query("date"))
-	if err != nil {
+	var cmd app.CmdToCollectDefects
+	var err error
+
+	if cmd.BeginTime, err = time.Parse(dateLayout, ctx.Query("date")); err != nil {
 		controller.SendBadRequestBody(ctx, err)
 
 		return
 	}
 
-	if v, err := ctl.service.CollectDefects(date); err != nil {
+	if v := ctx.Query("end_date"); v != "" {
+		if cmd.EndTime, err = time.Parse(dateLayout, v); err != nil {
+			controller.SendBadRequestBody(ctx, err)
+
+			return
+		}
+
+		cmd.EndTime = cmd.EndTime.AddDate(0, 0, 1)
+	}
+
+	if v, err := ctl.service.CollectDefects(cmd); err != nil {
 		controller.SendFailedResp(ctx, "", err)
 	} else {
 		controller.SendRespOfGet(ctx, v)
@@ -50,12 +91,17 @@ func (ctl DefectController) Collect(ctx *gin.Context) {
 
 // GenerateBulletin
 // @Summary generate security bulletin for some defects
-// @Description generate security bulletin for some defects
+// @Description generate security bulletin for some defects in the background, the issues not in any bulletin
+// @Description are reported with the reason, get the report by the id of the returned generation
 // @Tags  Defect
 // @Accept json
-// @Param	param  body	 bulletinRequest	 true	"body of some issue number"
-// @Success 201 {object} string
+// @Param	param  body	 bulletinRequest	 true	"body of some issues"
+// @Security PrivateToken
+// @Success 202 {object} app.GenerationDTO
 // @Failure 400 {object} string
+// @Failure 401 {object} string
+// @Failure 403 {object} string
+// @Failure 429 {object} string
 // @Router /v1/defect/bulletin [post]
 func (ctl DefectController) GenerateBulletin(ctx *gin.Context) {
 	var req bulletinRequest
@@ -65,15 +111,258 @@ func (ctl DefectController) GenerateBulletin(ctx *gin.Context) {
 		return
 	}
 
-	go func() {
-		logrus.Infof("generate bulletin processing of %v", req.IssueNumber)
+	cmd, err := req.toCmd()
+	if err != nil {
+		controller.SendBadRequestParam(ctx, err)
+
+		return
+	}
+
+	issues := strings.Join(append(req.Issues, req.IssueNumber...), ",")
+
+	detail := issues
+	if cmd.Force {
+		detail += " (force)"
+	}
+
+	ctl.auth.Audit(ctx, authdomain.AuditActionGenerateBulletin, detail)
+
+	v, err := ctl.generations.SubmitGeneration(cmd)
+	switch {
+	case err == nil:
+		logrus.Infof("generation %s of %s is submitted, grouping: %s", v.ID, issues, cmd.Grouping)
+
+		ctx.JSON(http.StatusAccepted, controller.ResponseData{Data: v})
+
+	case errors.Is(err, app.ErrTooManyGenerations):
+		ctx.JSON(http.StatusTooManyRequests, controller.ResponseData{
+			Code: errorTooManyRequests,
+			Msg:  err.Error(),
+		})
+
+	default:
+		logrus.Errorf("submit the generation of %s err: %s", issues, err.Error())
+
+		controller.SendFailedResp(ctx, "", err)
+	}
+}
+
+// GetGeneration
+// @Summary get the generation of bulletins
+// @Description get the status of the generation and the report when it is done
+// @Tags  Defect
+// @Accept json
+// @Param	id  path  string  true  "id of generation"
+// @Security PrivateToken
+// @Success 200 {object} app.GenerationDTO
+// @Failure 401 {object} string
+// @Failure 403 {object} string
+// @Failure 404 {object} string
+// @Router /v1/defect/bulletin/generations/{id} [get]
+func (ctl DefectController) GetGeneration(ctx *gin.Context) {
+	v, err := ctl.generations.GetGeneration(ctx.Param("id"))
+	switch {
+	case err == nil:
+		controller.SendRespOfGet(ctx, v)
+
+	case errors.Is(err, app.ErrGenerationNotFound):
+		ctx.JSON(http.StatusNotFound, controller.ResponseData{
+			Code: errorNotFound,
+			Msg:  err.Error(),
+		})
+
+	default:
+		controller.SendFailedResp(ctx, "", err)
+	}
+}
+
+// List
+// @Summary list defects
+// @Description list defects with filters, pagination and sorting
+// @Tags  Defect
+// @Accept json
+// @Param	org               query string false "org of the issue"
+// @Param	repo              query string false "repo of the issue"
+// @Param	component         query string false "component"
+// @Param	status            query string false "status of the issue"
+// @Param	severity_level    query string false "severity level"
+// @Param	system_version    query string false "system version"
+// @Param	affected_version  query string false "affected version"
+// @Param	keyword           query string false "keyword in the description"
+// @Param	reference         query string false "id of cve or cwe, or url of upstream commit or bug"
+// @Param	begin_date        query string false "defects created since the date, format: 2006-01-02"
+// @Param	end_date          query string false "defects created until the date, format: 2006-01-02"
+// @Param	page_num          query int    false "page num which starts from 1"
+// @Param	count_per_page    query int    false "count per page, max is 100"
+// @Param	sort_by           query string false "created_at, updated_at, number, component or severity_level"
+// @Param	direction         query string false "asc or desc"
+// @Param	cursor            query string false "next_cursor of the previous page, page_num is ignored when it is set"
+// @Security PrivateToken
+// @Success 200 {object} app.DefectsDTO
+// @Failure 400 {object} string
+// @Failure 401 {object} string
+// @Failure 403 {object} string
+// @Router /v1/defects [get]
+func (ctl DefectController) List(ctx *gin.Context) {
+	var req listDefectsRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		controller.SendBadRequestParam(ctx, err)
+
+		return
+	}
+
+	cmd, err := req.toCmd()
+	if err != nil {
+		controller.SendBadRequestParam(ctx, err)
 
-		if err := ctl.service.GenerateBulletins(req.IssueNumber); err != nil {
-			logrus.Errorf("generate bulletin of %v err: %s", req.IssueNumber, err.Error())
+		return
+	}
+
+	if v, err := ctl.service.ListDefects(cmd); err != nil {
+		controller.SendFailedResp(ctx, "", err)
+	} else {
+		controller.SendRespOfGet(ctx, v)
+	}
+}
+
+// Get
+// @Summary get a defect
+// @Description get a defect by the org, repo and number of the issue
+// @Tags  Defect
+// @Accept json
+// @Param	org     path string true "org of the issue"
+// @Param	repo    path string true "repo of the issue"
+// @Param	number  path string true "number of the issue"
+// @Security PrivateToken
+// @Success 200 {object} app.DefectDTO
+// @Failure 401 {object} string
+// @Failure 403 {object} string
+// @Failure 404 {object} string
+// @Router /v1/defects/{org}/{repo}/{number} [get]
+func (ctl DefectController) Get(ctx *gin.Context) {
+	if v, err := ctl.service.GetDefect(issueOfPath(ctx)); err != nil {
+		sendFailedResp(ctx, err)
+	} else {
+		controller.SendRespOfGet(ctx, v)
+	}
+}
+
+// Update
+// @Summary update a defect
+// @Description update some fields of a defect, only for admin
+// @Tags  Defect
+// @Accept json
+// @Param	org     path string              true "org of the issue"
+// @Param	repo    path string              true "repo of the issue"
+// @Param	number  path string              true "number of the issue"
+// @Param	param   body updateDefectRequest true "fields to update"
+// @Security PrivateToken
+// @Success 202 {object} string
+// @Failure 400 {object} string
+// @Failure 401 {object} string
+// @Failure 403 {object} string
+// @Failure 404 {object} string
+// @Router /v1/defects/{org}/{repo}/{number} [patch]
+func (ctl DefectController) Update(ctx *gin.Context) {
+	var req updateDefectRequest
+	if err := ctx.ShouldBindBodyWith(&req, binding.JSON); err != nil {
+		controller.SendBadRequestBody(ctx, err)
+
+		return
+	}
+
+	issue := issueOfPath(ctx)
+
+	cmd, err := req.toCmd(*issue)
+	if err != nil {
+		controller.SendBadRequestBody(ctx, err)
+
+		return
+	}
+
+	ctl.auth.Audit(ctx, authdomain.AuditActionUpdateDefect, issueTarget(issue))
+
+	if err := ctl.service.UpdateDefect(cmd); err != nil {
+		if errors.Is(err, app.ErrSeverityOfCVSS) {
+			controller.SendBadRequestBody(ctx, err)
 		} else {
-			logrus.Infof("generate bulletin success of %v", req.IssueNumber)
+			sendFailedResp(ctx, err)
 		}
-	}()
+	} else {
+		controller.SendRespOfPut(ctx)
+	}
+}
+
+// Delete
+// @Summary delete a defect
+// @Description delete a defect, only for admin
+// @Tags  Defect
+// @Accept json
+// @Param	org     path string true "org of the issue"
+// @Param	repo    path string true "repo of the issue"
+// @Param	number  path string true "number of the issue"
+// @Security PrivateToken
+// @Success 204
+// @Failure 401 {object} string
+// @Failure 403 {object} string
+// @Failure 404 {object} string
+// @Router /v1/defects/{org}/{repo}/{number} [delete]
+func (ctl DefectController) Delete(ctx *gin.Context) {
+	issue := issueOfPath(ctx)
+
+	ctl.auth.Audit(ctx, authdomain.AuditActionDeleteDefect, issueTarget(issue))
+
+	if err := ctl.service.DeleteDefect(issue); err != nil {
+		sendFailedResp(ctx, err)
+	} else {
+		ctx.Status(http.StatusNoContent)
+	}
+}
+
+// CheckRelease
+// @Summary check the release of a defect
+// @Description check whether the fixed package is released in each affected version,
+// @Description the defect is not included in the bulletin of the version until it is released
+// @Tags  Defect
+// @Accept json
+// @Param	org     path string true "org of the issue"
+// @Param	repo    path string true "repo of the issue"
+// @Param	number  path string true "number of the issue"
+// @Security PrivateToken
+// @Success 201 {object} []app.ReleaseDTO
+// @Failure 401 {object} string
+// @Failure 403 {object} string
+// @Failure 404 {object} string
+// @Router /v1/defects/{org}/{repo}/{number}/release [post]
+func (ctl DefectController) CheckRelease(ctx *gin.Context) {
+	if v, err := ctl.service.CheckRelease(issueOfPath(ctx)); err != nil {
+		sendFailedResp(ctx, err)
+	} else {
+		controller.SendRespOfPost(ctx, v)
+	}
+}
+
+func issueOfPath(ctx *gin.Context) *domain.Issue {
+	return &domain.Issue{
+		Org:    ctx.Param("org"),
+		Repo:   ctx.Param("repo"),
+		Number: ctx.Param("number"),
+	}
+}
+
+func issueTarget(issue *domain.Issue) string {
+	return issue.Org + "/" + issue.Repo + "/" + issue.Number
+}
+
+func sendFailedResp(ctx *gin.Context, err error) {
+	if errors.Is(err, app.ErrDefectNotFound) {
+		ctx.JSON(http.StatusNotFound, controller.ResponseData{
+			Code: errorNotFound,
+			Msg:  err.Error(),
+		})
+
+		return
+	}
 
-	controller.SendRespOfPost(ctx, "Processing: Data is being prepared, please wait patiently\n")
+	controller.SendFailedResp(ctx, "", err)
 }
@@ -1,5 +1,316 @@
 package controller
 
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/opensourceways/defect-manager/defect/app"
+	"github.com/opensourceways/defect-manager/defect/domain"
+	"github.com/opensourceways/defect-manager/defect/domain/dp"
+	"github.com/opensourceways/defect-manager/defect/domain/repository"
+)
+
+// bulletinRequest grouping is auto, component, version_set, sig or manual, the default is auto.
+// issues is the issues in org/repo/number, groups is the issues of each bulletin when grouping is manual,
+// they are added to issues. force re-issues the issues which have been published.
+// issue_number is the bare numbers accepted as before, they match the issues of any repo.
 type bulletinRequest struct {
-	IssueNumber []string `json:"issue_number" binding:"required"`
+	Issues      []string   `json:"issues"`
+	IssueNumber []string   `json:"issue_number"`
+	Grouping    string     `json:"grouping"`
+	Groups      [][]string `json:"groups"`
+	Force       bool       `json:"force"`
+}
+
+func (req *bulletinRequest) toCmd() (cmd app.CmdToGenerateBulletins, err error) {
+	cmd.Force = req.Force
+	cmd.Grouping = req.Grouping
+	if cmd.Grouping == "" {
+		cmd.Grouping = domain.GroupingAuto
+	}
+
+	if !domain.IsValidGrouping(cmd.Grouping) {
+		err = fmt.Errorf("invalid grouping: %s", req.Grouping)
+
+		return
+	}
+
+	if cmd.Grouping == domain.GroupingManual {
+		if len(req.Groups) == 0 {
+			err = errors.New("groups is required when grouping is manual")
+
+			return
+		}
+
+		cmd.Groups = make([][]domain.Issue, len(req.Groups))
+		for i, g := range req.Groups {
+			if cmd.Groups[i], err = toIssues(g); err != nil {
+				return
+			}
+
+			// the issues of groups are added to the request ones
+			req.Issues = append(req.Issues, g...)
+		}
+	}
+
+	for _, n := range req.IssueNumber {
+		if n == "" || strings.Contains(n, "/") {
+			err = fmt.Errorf("invalid issue number %s", n)
+
+			return
+		}
+	}
+
+	if len(req.Issues) == 0 && len(req.IssueNumber) == 0 {
+		err = errors.New("issues or issue_number is required")
+
+		return
+	}
+
+	cmd.Numbers = req.IssueNumber
+	cmd.Issues, err = toIssues(req.Issues)
+
+	return
+}
+
+func toIssues(keys []string) ([]domain.Issue, error) {
+	r := make([]domain.Issue, len(keys))
+	for i, k := range keys {
+		v, err := domain.NewIssueOfKey(k)
+		if err != nil {
+			return nil, err
+		}
+
+		r[i] = v
+	}
+
+	return r, nil
+}
+
+const (
+	dateLayout = "2006-01-02"
+
+	directionAsc  = "asc"
+	directionDesc = "desc"
+
+	defaultCountPerPage = 20
+	maxCountPerPage     = 100
+)
+
+var sortBy = map[string]bool{
+	repository.SortByCreatedAt:     true,
+	repository.SortByUpdatedAt:     true,
+	repository.SortByNumber:        true,
+	repository.SortByComponent:     true,
+	repository.SortBySeverityLevel: true,
+}
+
+type listDefectsRequest struct {
+	Org             string `form:"org"`
+	Repo            string `form:"repo"`
+	Component       string `form:"component"`
+	Status          string `form:"status"`
+	SeverityLevel   string `form:"severity_level"`
+	SystemVersion   string `form:"system_version"`
+	AffectedVersion string `form:"affected_version"`
+	Keyword         string `form:"keyword"`
+	Reference       string `form:"reference"`
+	BeginDate       string `form:"begin_date"`
+	EndDate         string `form:"end_date"`
+	PageNum         int    `form:"page_num"`
+	CountPerPage    int    `form:"count_per_page"`
+	SortBy          string `form:"sort_by"`
+	Direction       string `form:"direction"`
+	Cursor          string `form:"cursor"`
+}
+
+func (req *listDefectsRequest) toCmd() (cmd app.CmdToListDefects, err error) {
+	cmd.Org = req.Org
+	cmd.Repo = req.Repo
+	cmd.Component = req.Component
+	cmd.Keyword = req.Keyword
+	cmd.Reference = req.Reference
+
+	if req.Status != "" {
+		if cmd.Status, err = dp.NewIssueStatus(req.Status); err != nil {
+			return
+		}
+	}
+
+	if req.SeverityLevel != "" {
+		if cmd.SeverityLevel, err = dp.NewSeverityLevel(req.SeverityLevel); err != nil {
+			return
+		}
+	}
+
+	if req.SystemVersion != "" {
+		if cmd.SystemVersion, err = dp.NewSystemVersion(req.SystemVersion); err != nil {
+			return
+		}
+	}
+
+	if req.AffectedVersion != "" {
+		if cmd.AffectedVersion, err = dp.NewSystemVersion(req.AffectedVersion); err != nil {
+			return
+		}
+	}
+
+	if req.BeginDate != "" {
+		if cmd.BeginTime, err = time.Parse(dateLayout, req.BeginDate); err != nil {
+			return
+		}
+	}
+
+	// the end date is inclusive
+	if req.EndDate != "" {
+		if cmd.EndTime, err = time.Parse(dateLayout, req.EndDate); err != nil {
+			return
+		}
+
+		cmd.EndTime = cmd.EndTime.AddDate(0, 0, 1)
+	}
+
+	if req.SortBy != "" && !sortBy[req.SortBy] {
+		err = errors.New("invalid sort_by")
+
+		return
+	}
+	cmd.SortBy = req.SortBy
+
+	if req.Cursor != "" && req.SortBy != "" && req.SortBy != repository.SortByCreatedAt {
+		err = errors.New("cursor can only be used when sorting by created_at")
+
+		return
+	}
+	cmd.Cursor = req.Cursor
+
+	switch req.Direction {
+	case directionAsc:
+		cmd.Ascend = true
+	case directionDesc, "":
+	default:
+		err = errors.New("invalid direction")
+
+		return
+	}
+
+	cmd.PageNum = req.PageNum
+	if cmd.PageNum <= 0 {
+		cmd.PageNum = 1
+	}
+
+	cmd.CountPerPage = req.CountPerPage
+	if cmd.CountPerPage <= 0 {
+		cmd.CountPerPage = defaultCountPerPage
+	}
+
+	if cmd.CountPerPage > maxCountPerPage {
+		err = fmt.Errorf("count_per_page should not be more than %d", maxCountPerPage)
+	}
+
+	return
+}
+
+// updateDefectRequest an empty cvss_vector clears the vector,
+// severity_level can be set only if the defect has no vector after the update.
+type updateDefectRequest struct {
+	Title            *string            `json:"title"`
+	Status           *string            `json:"status"`
+	Kernel           *string            `json:"kernel"`
+	Component        *string            `json:"component"`
+	ComponentVersion *string            `json:"component_version"`
+	FixedVersion     *string            `json:"fixed_version"`
+	SystemVersion    *string            `json:"system_version"`
+	Description      *string            `json:"description"`
+	ReferenceURL     *string            `json:"reference_url"`
+	GuidanceURL      *string            `json:"guidance_url"`
+	Influence        *string            `json:"influence"`
+	SeverityLevel    *string            `json:"severity_level"`
+	CVSSVector       *string            `json:"cvss_vector"`
+	AffectedVersion  []string           `json:"affected_version"`
+	ABI              *string            `json:"abi"`
+	References       []referenceRequest `json:"references"`
+}
+
+// referenceRequest Type is one of cve, cwe, upstream_commit and upstream_bug
+type referenceRequest struct {
+	Type string `json:"type"`
+	ID   string `json:"id"`
+}
+
+func (req *updateDefectRequest) toCmd(issue domain.Issue) (cmd app.CmdToUpdateDefect, err error) {
+	cmd.Issue = issue
+	cmd.Title = req.Title
+	cmd.Kernel = req.Kernel
+	cmd.Component = req.Component
+	cmd.ComponentVersion = req.ComponentVersion
+	cmd.FixedVersion = req.FixedVersion
+	cmd.Description = req.Description
+	cmd.Influence = req.Influence
+	cmd.ABI = req.ABI
+
+	if req.Status != nil {
+		if cmd.Status, err = dp.NewIssueStatus(*req.Status); err != nil {
+			return
+		}
+	}
+
+	if req.SystemVersion != nil {
+		if cmd.SystemVersion, err = dp.NewSystemVersion(*req.SystemVersion); err != nil {
+			return
+		}
+	}
+
+	if req.ReferenceURL != nil {
+		if cmd.ReferenceURL, err = dp.NewURL(*req.ReferenceURL); err != nil {
+			return
+		}
+	}
+
+	if req.GuidanceURL != nil {
+		if cmd.GuidanceURL, err = dp.NewURL(*req.GuidanceURL); err != nil {
+			return
+		}
+	}
+
+	if req.SeverityLevel != nil {
+		if cmd.SeverityLevel, err = dp.NewSeverityLevel(*req.SeverityLevel); err != nil {
+			return
+		}
+	}
+
+	if req.CVSSVector != nil {
+		if *req.CVSSVector == "" {
+			cmd.ClearCVSS = true
+		} else if cmd.CVSS, err = dp.NewCVSS(*req.CVSSVector); err != nil {
+			return
+		}
+	}
+
+	if req.AffectedVersion != nil {
+		cmd.AffectedVersion = make([]dp.SystemVersion, len(req.AffectedVersion))
+		for k, v := range req.AffectedVersion {
+			if cmd.AffectedVersion[k], err = dp.NewSystemVersion(v); err != nil {
+				return
+			}
+		}
+	}
+
+	if req.References != nil {
+		cmd.References = make([]dp.Reference, len(req.References))
+		for k, v := range req.References {
+			var t dp.ReferenceType
+			if t, err = dp.NewReferenceType(v.Type); err != nil {
+				return
+			}
+
+			if cmd.References[k], err = dp.NewReference(t, v.ID); err != nil {
+				return
+			}
+		}
+	}
+
+	return
 }
@@ -0,0 +1,100 @@
+package controller
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/opensourceways/server-common-lib/controller"
+
+	authcontroller "github.com/opensourceways/defect-manager/auth/controller"
+	authdomain "github.com/opensourceways/defect-manager/auth/domain"
+	authdp "github.com/opensourceways/defect-manager/auth/domain/dp"
+	"github.com/opensourceways/defect-manager/defect/app"
+	"github.com/opensourceways/defect-manager/defect/domain"
+)
+
+type NotificationController struct {
+	service app.NotificationService
+	auth    *authcontroller.AuthMiddleware
+}
+
+func AddRouteForNotificationController(
+	r *gin.RouterGroup, s app.NotificationService, auth *authcontroller.AuthMiddleware,
+) {
+	ctl := NotificationController{
+		service: s,
+		auth:    auth,
+	}
+
+	r.GET("/v1/defect/bulletin/notifications", auth.Require(authdp.ScopeRead), ctl.List)
+	r.POST(
+		"/v1/defect/bulletin/notifications/:identification/retry",
+		auth.Require(authdp.ScopeGenerate), ctl.Retry,
+	)
+}
+
+// List
+// @Summary list notifications of bulletins
+// @Description list the notifications to the downstream after the bulletins are uploaded, latest first
+// @Tags  Notification
+// @Accept json
+// @Param	status  query  string  false  "pending, succeeded or failed, all if empty"
+// @Security PrivateToken
+// @Success 200 {object} []app.NotificationDTO
+// @Failure 400 {object} string
+// @Failure 401 {object} string
+// @Failure 403 {object} string
+// @Router /v1/defect/bulletin/notifications [get]
+func (ctl NotificationController) List(ctx *gin.Context) {
+	status := ctx.Query("status")
+	if status != "" && !domain.IsValidNotificationStatus(status) {
+		controller.SendBadRequestParam(ctx, fmt.Errorf("invalid status %s", status))
+
+		return
+	}
+
+	if v, err := ctl.service.ListNotifications(status); err != nil {
+		controller.SendFailedResp(ctx, "", err)
+	} else {
+		controller.SendRespOfGet(ctx, v)
+	}
+}
+
+// Retry
+// @Summary retry a notification
+// @Description notify the downstream of the bulletin again, nothing is done if it has succeeded
+// @Tags  Notification
+// @Accept json
+// @Param	identification  path  string  true  "identification of bulletin"
+// @Security PrivateToken
+// @Success 201 {object} app.NotificationDTO
+// @Failure 400 {object} string
+// @Failure 401 {object} string
+// @Failure 403 {object} string
+// @Failure 404 {object} string
+// @Router /v1/defect/bulletin/notifications/{identification}/retry [post]
+func (ctl NotificationController) Retry(ctx *gin.Context) {
+	id := ctx.Param("identification")
+
+	ctl.auth.Audit(ctx, authdomain.AuditActionNotifyBulletin, id)
+
+	v, err := ctl.service.RetryNotification(id)
+	switch {
+	case err == nil:
+		controller.SendRespOfPost(ctx, v)
+
+	case errors.Is(err, app.ErrNotificationNotFound):
+		ctx.JSON(http.StatusNotFound, controller.ResponseData{
+			Code: errorNotFound,
+			Msg:  err.Error(),
+		})
+
+	case errors.Is(err, app.ErrNotificationDisabled):
+		controller.SendBadRequestParam(ctx, err)
+
+	default:
+		controller.SendFailedResp(ctx, "", err)
+	}
+}
@@ -0,0 +1,101 @@
+package controller
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/opensourceways/server-common-lib/controller"
+
+	authcontroller "github.com/opensourceways/defect-manager/auth/controller"
+	authdomain "github.com/opensourceways/defect-manager/auth/domain"
+	authdp "github.com/opensourceways/defect-manager/auth/domain/dp"
+	"github.com/opensourceways/defect-manager/scheduler"
+)
+
+// BulletinScheduler generates the bulletins periodically, it can be paused as a kill switch
+type BulletinScheduler interface {
+	Status() (scheduler.StatusDTO, error)
+	Pause() error
+	Resume() error
+}
+
+type ScheduleController struct {
+	scheduler BulletinScheduler
+	auth      *authcontroller.AuthMiddleware
+}
+
+func AddRouteForScheduleController(
+	r *gin.RouterGroup, s BulletinScheduler, auth *authcontroller.AuthMiddleware,
+) {
+	ctl := ScheduleController{
+		scheduler: s,
+		auth:      auth,
+	}
+
+	r.GET("/v1/defect/bulletin/schedule", auth.Require(authdp.ScopeRead), ctl.Status)
+	r.POST("/v1/defect/bulletin/schedule/pause", auth.Require(authdp.ScopeAdmin), ctl.Pause)
+	r.POST("/v1/defect/bulletin/schedule/resume", auth.Require(authdp.ScopeAdmin), ctl.Resume)
+}
+
+// Status
+// @Summary status of the scheduled generation
+// @Description the jobs with the next run and the summary of the last run
+// @Tags  Schedule
+// @Accept json
+// @Security PrivateToken
+// @Success 200 {object} scheduler.StatusDTO
+// @Failure 401 {object} string
+// @Failure 403 {object} string
+// @Router /v1/defect/bulletin/schedule [get]
+func (ctl ScheduleController) Status(ctx *gin.Context) {
+	v, err := ctl.scheduler.Status()
+	if err != nil {
+		controller.SendFailedResp(ctx, "", err)
+
+		return
+	}
+
+	controller.SendRespOfGet(ctx, v)
+}
+
+// Pause
+// @Summary pause the scheduled generation
+// @Description the runs of all the replicas are skipped until it is resumed, only for admin
+// @Tags  Schedule
+// @Accept json
+// @Security PrivateToken
+// @Success 202 {object} string
+// @Failure 401 {object} string
+// @Failure 403 {object} string
+// @Router /v1/defect/bulletin/schedule/pause [post]
+func (ctl ScheduleController) Pause(ctx *gin.Context) {
+	ctl.auth.Audit(ctx, authdomain.AuditActionPauseSchedule, "")
+
+	if err := ctl.scheduler.Pause(); err != nil {
+		controller.SendFailedResp(ctx, "", err)
+
+		return
+	}
+
+	controller.SendRespOfPut(ctx)
+}
+
+// Resume
+// @Summary resume the scheduled generation
+// @Description resume the paused scheduled generation, only for admin
+// @Tags  Schedule
+// @Accept json
+// @Security PrivateToken
+// @Success 202 {object} string
+// @Failure 401 {object} string
+// @Failure 403 {object} string
+// @Router /v1/defect/bulletin/schedule/resume [post]
+func (ctl ScheduleController) Resume(ctx *gin.Context) {
+	ctl.auth.Audit(ctx, authdomain.AuditActionResumeSchedule, "")
+
+	if err := ctl.scheduler.Resume(); err != nil {
+		controller.SendFailedResp(ctx, "", err)
+
+		return
+	}
+
+	controller.SendRespOfPut(ctx)
+}
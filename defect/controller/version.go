@@ -0,0 +1,179 @@
+package controller
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/opensourceways/server-common-lib/controller"
+
+	authcontroller "github.com/opensourceways/defect-manager/auth/controller"
+	authdomain "github.com/opensourceways/defect-manager/auth/domain"
+	authdp "github.com/opensourceways/defect-manager/auth/domain/dp"
+	"github.com/opensourceways/defect-manager/defect/app"
+	"github.com/opensourceways/defect-manager/defect/domain/dp"
+)
+
+const errorVersionExists = "version_exists"
+
+type VersionController struct {
+	service app.VersionService
+	auth    *authcontroller.AuthMiddleware
+}
+
+func AddRouteForVersionController(
+	r *gin.RouterGroup, s app.VersionService, auth *authcontroller.AuthMiddleware,
+) {
+	ctl := VersionController{
+		service: s,
+		auth:    auth,
+	}
+
+	r.GET("/v1/versions", auth.Require(authdp.ScopeRead), ctl.List)
+	r.POST("/v1/versions", auth.Require(authdp.ScopeAdmin), ctl.Add)
+	r.PATCH("/v1/versions/:version", auth.Require(authdp.ScopeAdmin), ctl.Update)
+	r.POST("/v1/versions/:version/retire", auth.Require(authdp.ScopeAdmin), ctl.Retire)
+}
+
+// List
+// @Summary list versions
+// @Description list all the versions with lifecycle, including the retired ones
+// @Tags  Version
+// @Accept json
+// @Security PrivateToken
+// @Success 200 {object} []app.VersionDTO
+// @Failure 401 {object} string
+// @Failure 403 {object} string
+// @Router /v1/versions [get]
+func (ctl VersionController) List(ctx *gin.Context) {
+	if v, err := ctl.service.ListVersions(); err != nil {
+		controller.SendFailedResp(ctx, "", err)
+	} else {
+		controller.SendRespOfGet(ctx, v)
+	}
+}
+
+// Add
+// @Summary add a version
+// @Description add a maintained version, only for admin
+// @Tags  Version
+// @Accept json
+// @Param	param  body  addVersionRequest  true  "body of version"
+// @Security PrivateToken
+// @Success 201 {object} string
+// @Failure 400 {object} string
+// @Failure 401 {object} string
+// @Failure 403 {object} string
+// @Failure 409 {object} string
+// @Router /v1/versions [post]
+func (ctl VersionController) Add(ctx *gin.Context) {
+	var req addVersionRequest
+	if err := ctx.ShouldBindBodyWith(&req, binding.JSON); err != nil {
+		controller.SendBadRequestBody(ctx, err)
+
+		return
+	}
+
+	cmd, err := req.toCmd()
+	if err != nil {
+		controller.SendBadRequestBody(ctx, err)
+
+		return
+	}
+
+	ctl.auth.Audit(ctx, authdomain.AuditActionAddVersion, req.Version)
+
+	if err := ctl.service.AddVersion(cmd); err != nil {
+		sendVersionFailedResp(ctx, err)
+	} else {
+		controller.SendRespOfPost(ctx, "")
+	}
+}
+
+// Update
+// @Summary update a version
+// @Description update the lifecycle of a version, only for admin
+// @Tags  Version
+// @Accept json
+// @Param	version  path  string                true  "name of version"
+// @Param	param    body  updateVersionRequest  true  "fields to update"
+// @Security PrivateToken
+// @Success 202 {object} string
+// @Failure 400 {object} string
+// @Failure 401 {object} string
+// @Failure 403 {object} string
+// @Failure 404 {object} string
+// @Router /v1/versions/{version} [patch]
+func (ctl VersionController) Update(ctx *gin.Context) {
+	var req updateVersionRequest
+	if err := ctx.ShouldBindBodyWith(&req, binding.JSON); err != nil {
+		controller.SendBadRequestBody(ctx, err)
+
+		return
+	}
+
+	cmd, err := req.toCmd(ctx.Param("version"))
+	if err != nil {
+		controller.SendBadRequestBody(ctx, err)
+
+		return
+	}
+
+	ctl.auth.Audit(ctx, authdomain.AuditActionUpdateVersion, ctx.Param("version"))
+
+	if err := ctl.service.UpdateVersion(cmd); err != nil {
+		sendVersionFailedResp(ctx, err)
+	} else {
+		controller.SendRespOfPut(ctx)
+	}
+}
+
+// Retire
+// @Summary retire a version
+// @Description retire a version, it is not maintained any more, only for admin
+// @Tags  Version
+// @Accept json
+// @Param	version  path  string  true  "name of version"
+// @Security PrivateToken
+// @Success 202 {object} string
+// @Failure 400 {object} string
+// @Failure 401 {object} string
+// @Failure 403 {object} string
+// @Failure 404 {object} string
+// @Router /v1/versions/{version}/retire [post]
+func (ctl VersionController) Retire(ctx *gin.Context) {
+	version, err := dp.NewSystemVersion(ctx.Param("version"))
+	if err != nil {
+		controller.SendBadRequestParam(ctx, err)
+
+		return
+	}
+
+	ctl.auth.Audit(ctx, authdomain.AuditActionRetireVersion, version.String())
+
+	if err := ctl.service.RetireVersion(version); err != nil {
+		sendVersionFailedResp(ctx, err)
+	} else {
+		controller.SendRespOfPut(ctx)
+	}
+}
+
+func sendVersionFailedResp(ctx *gin.Context, err error) {
+	switch {
+	case errors.Is(err, app.ErrVersionNotFound):
+		ctx.JSON(http.StatusNotFound, controller.ResponseData{
+			Code: errorNotFound,
+			Msg:  err.Error(),
+		})
+
+	case errors.Is(err, app.ErrVersionExists):
+		ctx.JSON(http.StatusConflict, controller.ResponseData{
+			Code: errorVersionExists,
+			Msg:  err.Error(),
+		})
+
+	default:
+		controller.SendFailedResp(ctx, "", err)
+	}
+}
@@ -0,0 +1,87 @@
+package controller
+
+import (
+	"time"
+
+	"github.com/opensourceways/defect-manager/defect/app"
+	"github.com/opensourceways/defect-manager/defect/domain/dp"
+)
+
+// addVersionRequest the format of date is 2006-01-02, branch is the same as version if it is empty
+type addVersionRequest struct {
+	Version string `json:"version"  binding:"required"`
+	Branch  string `json:"branch"`
+	LTS     bool   `json:"lts"`
+	SP      bool   `json:"sp"`
+	GADate  string `json:"ga_date"`
+	EOLDate string `json:"eol_date"`
+}
+
+func (req *addVersionRequest) toCmd() (cmd app.CmdToAddVersion, err error) {
+	if cmd.Version, err = dp.NewSystemVersion(req.Version); err != nil {
+		return
+	}
+
+	if cmd.GADate, err = parseOptionalDate(req.GADate); err != nil {
+		return
+	}
+
+	if cmd.EOLDate, err = parseOptionalDate(req.EOLDate); err != nil {
+		return
+	}
+
+	cmd.Branch = req.Branch
+	cmd.LTS = req.LTS
+	cmd.SP = req.SP
+
+	return
+}
+
+// updateVersionRequest nil field is not changed, empty date clears it
+type updateVersionRequest struct {
+	Branch  *string `json:"branch"`
+	LTS     *bool   `json:"lts"`
+	SP      *bool   `json:"sp"`
+	GADate  *string `json:"ga_date"`
+	EOLDate *string `json:"eol_date"`
+	Retired *bool   `json:"retired"`
+}
+
+func (req *updateVersionRequest) toCmd(version string) (cmd app.CmdToUpdateVersion, err error) {
+	if cmd.Version, err = dp.NewSystemVersion(version); err != nil {
+		return
+	}
+
+	toDate := func(s *string) (*time.Time, error) {
+		if s == nil {
+			return nil, nil
+		}
+
+		t, err := parseOptionalDate(*s)
+
+		return &t, err
+	}
+
+	if cmd.GADate, err = toDate(req.GADate); err != nil {
+		return
+	}
+
+	if cmd.EOLDate, err = toDate(req.EOLDate); err != nil {
+		return
+	}
+
+	cmd.Branch = req.Branch
+	cmd.LTS = req.LTS
+	cmd.SP = req.SP
+	cmd.Retired = req.Retired
+
+	return
+}
+
+func parseOptionalDate(s string) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, nil
+	}
+
+	return time.Parse(dateLayout, s)
+}
@@ -1,6 +1,31 @@
 package backend
 
+import (
+	"errors"
+	"time"
+)
+
+var ErrBulletinNotFound = errors.New("bulletin not found")
+
+// Bulletin is a bulletin published to the backend, Issues is the keys(org/repo/number) of the issues,
+// File is the path of its cvrf xml in OBS
+type Bulletin struct {
+	Identification  string
+	Component       string
+	AffectedVersion []string
+	Issues          []string
+	File            string
+	PublishedAt     time.Time
+}
+
 type CveBackend interface {
 	MaxBulletinID() (int, error)
+	// PublishedDefects returns the keys of the published issues, the bulletins
+	// published before the keys are used have the issue numbers only.
 	PublishedDefects() ([]string, error)
+	// PublishedDefectsSince returns the keys of the issues published at or after the time
+	PublishedDefectsSince(time.Time) ([]string, error)
+	PublishBulletin(*Bulletin) error
+	// FindBulletin returns ErrBulletinNotFound if the bulletin does not exist
+	FindBulletin(identification string) (Bulletin, error)
 }
@@ -4,4 +4,5 @@ import "github.com/opensourceways/defect-manager/defect/domain"
 
 type Bulletin interface {
 	Generate(*domain.SecurityBulletin) ([]byte, error)
+	GenerateCSAF(*domain.SecurityBulletin) ([]byte, error)
 }
@@ -1,13 +1,16 @@
 package domain
 
 import (
+	"sort"
+	"time"
+
 	"github.com/opensourceways/defect-manager/defect/domain/dp"
 )
 
 type SecurityBulletin struct {
 	AffectedVersion []dp.SystemVersion
 	Identification  string
-	Date            string
+	Date            time.Time
 	Component       string
 	ProductTree     ProductTree
 	Defects         Defects
@@ -15,8 +18,68 @@ type SecurityBulletin struct {
 
 type ProductTree = map[dp.Arch][]Product
 
+// Product is a rpm of the affected system version, ID and RPM are parsed from FullName
 type Product struct {
 	ID       string
 	CPE      string
 	FullName string
+	RPM      dp.NEVRA
+}
+
+// Components returns the components of the defects, a bulletin grouped by sig or manually may have more than one
+func (sb *SecurityBulletin) Components() []string {
+	var r []string
+	for i := range sb.Defects {
+		if !containsString(r, sb.Defects[i].Component) {
+			r = append(r, sb.Defects[i].Component)
+		}
+	}
+
+	return r
+}
+
+// AffectedVersionOf returns the versions of the bulletin affected by the defects of the component
+func (sb *SecurityBulletin) AffectedVersionOf(component string) []dp.SystemVersion {
+	var r []dp.SystemVersion
+	for _, v := range sb.AffectedVersion {
+		for i := range sb.Defects {
+			if sb.Defects[i].Component == component && sb.Defects[i].isAffectVersion(v) {
+				r = append(r, v)
+
+				break
+			}
+		}
+	}
+
+	return r
+}
+
+// Arches returns the arches of the product tree sorted by name
+func (sb *SecurityBulletin) Arches() []dp.Arch {
+	r := make([]dp.Arch, 0, len(sb.ProductTree))
+	for arch := range sb.ProductTree {
+		r = append(r, arch)
+	}
+
+	sort.Slice(r, func(i, j int) bool {
+		return r[i].String() < r[j].String()
+	})
+
+	return r
+}
+
+// ProductsOf returns a copy of the products of the arch sorted by full name
+func (sb *SecurityBulletin) ProductsOf(arch dp.Arch) []Product {
+	r := make([]Product, len(sb.ProductTree[arch]))
+	copy(r, sb.ProductTree[arch])
+
+	sort.SliceStable(r, func(i, j int) bool {
+		if r[i].FullName != r[j].FullName {
+			return r[i].FullName < r[j].FullName
+		}
+
+		return r[i].ID < r[j].ID
+	})
+
+	return r
 }
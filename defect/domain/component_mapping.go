@@ -0,0 +1,11 @@
+package domain
+
+import "github.com/opensourceways/defect-manager/defect/domain/dp"
+
+// ComponentMapping maps the component of issue to the source package in the product tree,
+// SystemVersion is nil if the mapping applies to all the versions.
+type ComponentMapping struct {
+	Component     string
+	SystemVersion dp.SystemVersion
+	SourcePackage string
+}
@@ -1,9 +1,11 @@
 package domain
 
 import (
-	"github.com/opensourceways/defect-manager/defect/domain/dp"
+	"fmt"
+	"strings"
+	"time"
 
-	"github.com/opensourceways/defect-manager/utils"
+	"github.com/opensourceways/defect-manager/defect/domain/dp"
 )
 
 type Defects []Defect
@@ -14,19 +16,29 @@ type DefectsByComponent []Defect
 // DefectsByVersion is group of DefectsByComponent by version
 type DefectsByVersion []Defect
 
+// Defect FixedVersion is the [epoch:]version-release built from the merged pr, it is optional.
+// Releases are the release states of the fixed package in the affected versions.
+// References are the cve, cwe and upstream references parsed from the issue.
+// CVSS is nil if the severity level is chosen without a vector, otherwise the level is derived from it.
 type Defect struct {
 	Kernel           string
 	Component        string
 	ComponentVersion string
+	FixedVersion     string
 	SystemVersion    dp.SystemVersion
 	Description      string
 	ReferenceURL     dp.URL
 	GuidanceURL      dp.URL
 	Influence        string
 	SeverityLevel    dp.SeverityLevel
+	CVSS             dp.CVSS
 	AffectedVersion  []dp.SystemVersion
 	ABI              string
+	References       []dp.Reference
 	Issue            Issue
+	Releases         []Release
+	CreatedAt        time.Time
+	UpdatedAt        time.Time
 }
 
 type Issue struct {
@@ -37,6 +49,26 @@ type Issue struct {
 	Status dp.IssueStatus
 }
 
+// RepoPath is the path of the repo in which the issue is, such as src-openeuler/zbar
+func (i Issue) RepoPath() string {
+	return i.Org + "/" + i.Repo
+}
+
+// Key identifies the issue, the numbers of issues in different repos may be the same
+func (i Issue) Key() string {
+	return i.RepoPath() + "/" + i.Number
+}
+
+// NewIssueOfKey parses the key returned by Key
+func NewIssueOfKey(key string) (Issue, error) {
+	v := strings.Split(key, "/")
+	if len(v) != 3 || v[0] == "" || v[1] == "" || v[2] == "" {
+		return Issue{}, fmt.Errorf("invalid issue %s, it should be org/repo/number", key)
+	}
+
+	return Issue{Org: v[0], Repo: v[1], Number: v[2]}, nil
+}
+
 func (d Defect) isAffectVersion(version dp.SystemVersion) bool {
 	for _, v := range d.AffectedVersion {
 		if v == version {
@@ -47,43 +79,48 @@ func (d Defect) isAffectVersion(version dp.SystemVersion) bool {
 	return false
 }
 
-// GroupByComponent group defects by component
-func (ds Defects) groupByComponent() map[string]DefectsByComponent {
-	group := make(map[string]DefectsByComponent)
-	for _, d := range ds {
-		group[d.Component] = append(group[d.Component], d)
+// ReferencesOf returns the ids of the references of the type
+func (d *Defect) ReferencesOf(t dp.ReferenceType) []string {
+	var r []string
+	for _, v := range d.References {
+		if v.Type().String() == t.String() {
+			r = append(r, v.ID())
+		}
 	}
 
-	return group
+	return r
 }
 
-// GenerateBulletins DefectsByComponent is a component-differentiated set of defects,
-// Bulletins are consolidated into one when all issues of a component affect all versions currently maintained,
-// otherwise they are split into multiple bulletins by version
-func (ds Defects) GenerateBulletins() []SecurityBulletin {
-	var securityBulletins []SecurityBulletin
-
-	for _, dsc := range ds.groupByComponent() {
-		if dsc.isCombined() {
-			securityBulletins = append(securityBulletins, dsc.combinedBulletin())
+// groupByComponent groups the defects by component, the groups are sorted by component
+// and the defects in a group are sorted by issue
+func (ds Defects) groupByComponent() []DefectsByComponent {
+	var group []DefectsByComponent
+	for _, d := range ds.sorted() {
+		if n := len(group); n > 0 && group[n-1][0].Component == d.Component {
+			group[n-1] = append(group[n-1], d)
 		} else {
-			securityBulletins = append(securityBulletins, dsc.separatedBulletins()...)
+			group = append(group, DefectsByComponent{d})
 		}
 	}
 
-	return securityBulletins
+	return group
 }
 
 // IsCombined determine whether multiple defects under the same component
 // need to be combined into a single bulletin
-func (dsc DefectsByComponent) isCombined() bool {
+func (dsc DefectsByComponent) isCombined(maintained []dp.SystemVersion) bool {
+	isMaintained := make(map[dp.SystemVersion]bool, len(maintained))
+	for _, v := range maintained {
+		isMaintained[v] = true
+	}
+
 	for _, d := range dsc {
-		if len(d.AffectedVersion) != len(dp.MaintainVersion) {
+		if len(d.AffectedVersion) != len(isMaintained) {
 			return false
 		}
 
 		for _, version := range d.AffectedVersion {
-			if !dp.MaintainVersion[version] {
+			if !isMaintained[version] {
 				return false
 			}
 		}
@@ -92,44 +129,21 @@ func (dsc DefectsByComponent) isCombined() bool {
 	return true
 }
 
-// CombinedBulletin put all defects in one bulletin
-func (dsc DefectsByComponent) combinedBulletin() SecurityBulletin {
-	return SecurityBulletin{
-		AffectedVersion: dsc[0].AffectedVersion,
-		Date:            utils.Date(),
-		Component:       dsc[0].Component,
-		Defects:         Defects(dsc),
-	}
-}
-
-// SeparatedBulletins split into multiple bulletins by version
-func (dsc DefectsByComponent) separatedBulletins() []SecurityBulletin {
+// SeparatedBulletins split into multiple bulletins by version in the order of maintained versions
+func (dsc DefectsByComponent) separatedBulletins(maintained []dp.SystemVersion) []SecurityBulletin {
 	var sbs []SecurityBulletin
-	for version, ds := range dsc.separateByVersion() {
-		sbs = append(sbs, ds.bulletinByVersion(version))
-	}
-
-	return sbs
-}
-
-func (dsc DefectsByComponent) separateByVersion() map[dp.SystemVersion]DefectsByVersion {
-	classifyByVersion := make(map[dp.SystemVersion]DefectsByVersion)
-	for version := range dp.MaintainVersion {
+	for _, version := range maintained {
+		var dsv DefectsByVersion
 		for _, d := range dsc {
 			if d.isAffectVersion(version) {
-				classifyByVersion[version] = append(classifyByVersion[version], d)
+				dsv = append(dsv, d)
 			}
 		}
-	}
-
-	return classifyByVersion
-}
 
-func (dsv DefectsByVersion) bulletinByVersion(version dp.SystemVersion) SecurityBulletin {
-	return SecurityBulletin{
-		AffectedVersion: []dp.SystemVersion{version},
-		Date:            utils.Date(),
-		Component:       dsv[0].Component,
-		Defects:         Defects(dsv),
+		if len(dsv) > 0 {
+			sbs = append(sbs, newBulletin([]dp.SystemVersion{version}, Defects(dsv)))
+		}
 	}
+
+	return sbs
 }
@@ -0,0 +1,25 @@
+package domain
+
+import "time"
+
+const (
+	DomainEventDefectAccepted    = "defect.accepted"
+	DomainEventDefectRejected    = "defect.rejected"
+	DomainEventDefectReopened    = "defect.reopened"
+	DomainEventBulletinGenerated = "bulletin.generated"
+	DomainEventBulletinUploaded  = "bulletin.uploaded"
+)
+
+// DomainEvent is published to the downstream consumers via the outbox, so it may be delivered more than once
+// and the consumers should deduplicate it by ID. Body is the json of the schema of Version,
+// Key is the issue or the identification of bulletin which the event is about.
+// Attempts is the count of the failed attempts of publishing.
+type DomainEvent struct {
+	ID         string
+	Type       string
+	Version    string
+	Key        string
+	Body       []byte
+	OccurredAt time.Time
+	Attempts   int
+}
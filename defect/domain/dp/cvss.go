@@ -0,0 +1,156 @@
+package dp
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+)
+
+const (
+	CVSSVersion30 = "3.0"
+	CVSSVersion31 = "3.1"
+	CVSSVersion40 = "4.0"
+
+	cvssPrefix = "CVSS:"
+)
+
+// CVSS is a vector of the base metrics, the base score is computed when it is parsed
+type CVSS interface {
+	Vector() string
+	Version() string
+	Score() float64
+	SeverityLevel() SeverityLevel
+}
+
+type cvss struct {
+	vector  string
+	version string
+	score   float64
+}
+
+// NewCVSS parses the vector of CVSS v3.0, v3.1 or v4.0, such as CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H,
+// only the base metrics are accepted. v3.0 has the same base metrics as v3.1 and is scored in the same way.
+func NewCVSS(vector string) (CVSS, error) {
+	vector = strings.TrimSpace(vector)
+
+	items := strings.Split(vector, "/")
+	if !strings.HasPrefix(items[0], cvssPrefix) {
+		return nil, errors.New("invalid cvss vector, missing version")
+	}
+
+	version := strings.TrimPrefix(items[0], cvssPrefix)
+
+	var metrics, order []string
+	switch version {
+	case CVSSVersion30, CVSSVersion31:
+		order = cvss31Metrics
+	case CVSSVersion40:
+		order = cvss40Metrics
+	default:
+		return nil, fmt.Errorf("unsupported cvss version %s", version)
+	}
+
+	values := cvssValues[version]
+	if version == CVSSVersion30 {
+		values = cvssValues[CVSSVersion31]
+	}
+
+	m, err := parseCVSSMetrics(items[1:], order, values)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, k := range order {
+		metrics = append(metrics, k+":"+m[k])
+	}
+
+	r := cvss{
+		vector:  items[0] + "/" + strings.Join(metrics, "/"),
+		version: version,
+	}
+
+	if version != CVSSVersion40 {
+		r.score = cvss31Score(m)
+	} else {
+		r.score = cvss40Score(m)
+	}
+
+	return r, nil
+}
+
+func parseCVSSMetrics(items, order []string, values map[string]string) (map[string]string, error) {
+	m := make(map[string]string, len(items))
+	for _, item := range items {
+		kv := strings.Split(item, ":")
+		if len(kv) != 2 {
+			return nil, fmt.Errorf("invalid cvss metric %s", item)
+		}
+
+		valid, ok := values[kv[0]]
+		if !ok {
+			return nil, fmt.Errorf("unsupported cvss metric %s", kv[0])
+		}
+
+		if _, ok := m[kv[0]]; ok {
+			return nil, fmt.Errorf("duplicate cvss metric %s", kv[0])
+		}
+
+		if kv[1] == "" || !strings.Contains(valid, kv[1]) || len(kv[1]) != 1 {
+			return nil, fmt.Errorf("invalid value of cvss metric %s", item)
+		}
+
+		m[kv[0]] = kv[1]
+	}
+
+	for _, k := range order {
+		if _, ok := m[k]; !ok {
+			return nil, fmt.Errorf("missing cvss metric %s", k)
+		}
+	}
+
+	return m, nil
+}
+
+// Vector is normalized with the metrics in the order of specification
+func (c cvss) Vector() string {
+	return c.vector
+}
+
+func (c cvss) Version() string {
+	return c.version
+}
+
+func (c cvss) Score() float64 {
+	return c.score
+}
+
+// SeverityLevel the level None of CVSS is Low
+func (c cvss) SeverityLevel() SeverityLevel {
+	switch {
+	case c.score >= 9.0:
+		return severityLevel(critical)
+	case c.score >= 7.0:
+		return severityLevel(high)
+	case c.score >= 4.0:
+		return severityLevel(moderate)
+	default:
+		return severityLevel(low)
+	}
+}
+
+// cvssValues is the valid values of each base metric
+var cvssValues = map[string]map[string]string{
+	CVSSVersion31: {
+		"AV": "NALP", "AC": "LH", "PR": "NLH", "UI": "NR", "S": "UC", "C": "HLN", "I": "HLN", "A": "HLN",
+	},
+	CVSSVersion40: {
+		"AV": "NALP", "AC": "LH", "AT": "NP", "PR": "NLH", "UI": "NPA",
+		"VC": "HLN", "VI": "HLN", "VA": "HLN", "SC": "HLN", "SI": "HLN", "SA": "HLN",
+	},
+}
+
+// roundToOneDecimal rounds half away from zero
+func roundToOneDecimal(v float64) float64 {
+	return math.Round(v*10) / 10
+}
@@ -0,0 +1,57 @@
+package dp
+
+import "math"
+
+var cvss31Metrics = []string{"AV", "AC", "PR", "UI", "S", "C", "I", "A"}
+
+var cvss31Weights = map[string]map[string]float64{
+	"AV": {"N": 0.85, "A": 0.62, "L": 0.55, "P": 0.2},
+	"AC": {"L": 0.77, "H": 0.44},
+	"UI": {"N": 0.85, "R": 0.62},
+	"C":  {"H": 0.56, "L": 0.22, "N": 0},
+	"I":  {"H": 0.56, "L": 0.22, "N": 0},
+	"A":  {"H": 0.56, "L": 0.22, "N": 0},
+}
+
+// cvss31Score is the base score in section 7.1 of CVSS v3.1 specification
+func cvss31Score(m map[string]string) float64 {
+	changed := m["S"] == "C"
+
+	pr := map[string]float64{"N": 0.85, "L": 0.62, "H": 0.27}[m["PR"]]
+	if changed {
+		pr = map[string]float64{"N": 0.85, "L": 0.68, "H": 0.5}[m["PR"]]
+	}
+
+	iss := 1 - (1-cvss31Weights["C"][m["C"]])*(1-cvss31Weights["I"][m["I"]])*(1-cvss31Weights["A"][m["A"]])
+
+	var impact float64
+	if changed {
+		impact = 7.52*(iss-0.029) - 3.25*math.Pow(iss-0.02, 15)
+	} else {
+		impact = 6.42 * iss
+	}
+
+	if impact <= 0 {
+		return 0
+	}
+
+	exploitability := 8.22 * cvss31Weights["AV"][m["AV"]] * cvss31Weights["AC"][m["AC"]] * pr *
+		cvss31Weights["UI"][m["UI"]]
+
+	if changed {
+		return cvss31Roundup(math.Min(1.08*(impact+exploitability), 10))
+	}
+
+	return cvss31Roundup(math.Min(impact+exploitability, 10))
+}
+
+// cvss31Roundup is the Roundup in appendix A of CVSS v3.1 specification,
+// it avoids the error of floating point arithmetic.
+func cvss31Roundup(v float64) float64 {
+	i := int(math.Round(v * 100000))
+	if i%10000 == 0 {
+		return float64(i) / 100000
+	}
+
+	return float64(i/10000+1) / 10
+}
@@ -0,0 +1,228 @@
+package dp
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+var cvss40Metrics = []string{"AV", "AC", "AT", "PR", "UI", "VC", "VI", "VA", "SC", "SI", "SA"}
+
+// cvss40Levels is the severity level of each value, the lower the more severe.
+// CR, IR and AR are always H and E is always A because only the base metrics are accepted.
+var cvss40Levels = map[string]map[string]float64{
+	"AV": {"N": 0, "A": 1, "L": 2, "P": 3},
+	"AC": {"L": 0, "H": 1},
+	"AT": {"N": 0, "P": 1},
+	"PR": {"N": 0, "L": 1, "H": 2},
+	"UI": {"N": 0, "P": 1, "A": 2},
+	"VC": {"H": 0, "L": 1, "N": 2},
+	"VI": {"H": 0, "L": 1, "N": 2},
+	"VA": {"H": 0, "L": 1, "N": 2},
+	"SC": {"H": 0, "L": 1, "N": 2},
+	"SI": {"S": 0, "H": 1, "L": 2, "N": 3},
+	"SA": {"S": 0, "H": 1, "L": 2, "N": 3},
+	"CR": {"H": 0, "M": 1, "L": 2},
+	"IR": {"H": 0, "M": 1, "L": 2},
+	"AR": {"H": 0, "M": 1, "L": 2},
+}
+
+// cvss40MaxComposed is the highest severity vectors of each level of the equivalence sets
+var cvss40MaxComposed = map[string]map[string][]string{
+	"eq1": {
+		"0": {"AV:N/PR:N/UI:N"},
+		"1": {"AV:A/PR:N/UI:N", "AV:N/PR:L/UI:N", "AV:N/PR:N/UI:P"},
+		"2": {"AV:P/PR:N/UI:N", "AV:A/PR:L/UI:P"},
+	},
+	"eq2": {
+		"0": {"AC:L/AT:N"},
+		"1": {"AC:H/AT:N", "AC:L/AT:P"},
+	},
+	"eq3eq6": {
+		"00": {"VC:H/VI:H/VA:H/CR:H/IR:H/AR:H"},
+		"01": {"VC:H/VI:H/VA:L/CR:M/IR:M/AR:H", "VC:H/VI:H/VA:H/CR:M/IR:M/AR:M"},
+		"10": {"VC:L/VI:H/VA:H/CR:H/IR:H/AR:H", "VC:H/VI:L/VA:H/CR:H/IR:H/AR:H"},
+		"11": {
+			"VC:L/VI:H/VA:L/CR:H/IR:M/AR:H", "VC:L/VI:H/VA:H/CR:H/IR:M/AR:M", "VC:H/VI:L/VA:H/CR:M/IR:H/AR:M",
+			"VC:H/VI:L/VA:L/CR:M/IR:H/AR:H", "VC:L/VI:L/VA:H/CR:H/IR:H/AR:M",
+		},
+		"21": {"VC:L/VI:L/VA:L/CR:H/IR:H/AR:H"},
+	},
+	"eq4": {
+		"0": {"SC:H/SI:S/SA:S"},
+		"1": {"SC:H/SI:H/SA:H"},
+		"2": {"SC:L/SI:L/SA:L"},
+	},
+}
+
+// cvss40MaxSeverity is the depth of each level of the equivalence sets plus one
+var cvss40MaxSeverity = map[string]map[string]float64{
+	"eq1":    {"0": 1, "1": 4, "2": 5},
+	"eq2":    {"0": 1, "1": 2},
+	"eq3eq6": {"00": 7, "01": 6, "10": 8, "11": 8, "21": 10},
+	"eq4":    {"0": 6, "1": 5, "2": 4},
+}
+
+// cvss40Score is the base score computed as the reference implementation of CVSS v4.0 specification
+func cvss40Score(m map[string]string) float64 {
+	for _, k := range []string{"VC", "VI", "VA", "SC", "SI", "SA"} {
+		if m[k] != "N" {
+			break
+		}
+
+		if k == "SA" {
+			return 0
+		}
+	}
+
+	// the requirements are H when the environmental metrics are not defined
+	m["CR"], m["IR"], m["AR"] = "H", "H", "H"
+
+	eq := cvss40MacroVector(m)
+	value := cvss40MacroVectors[eq]
+
+	lookup := func(i int, level byte) float64 {
+		next := []byte(eq)
+		next[i] = level
+
+		if v, ok := cvss40MacroVectors[string(next)]; ok {
+			return v
+		}
+
+		return math.NaN()
+	}
+
+	eq1, eq2, eq3, eq4, eq6 := eq[0], eq[1], eq[2], eq[3], eq[5]
+
+	// the score of the next lower macro vector of each equivalence set
+	lower1 := lookup(0, eq1+1)
+	lower2 := lookup(1, eq2+1)
+	lower4 := lookup(3, eq4+1)
+	lower5 := lookup(4, eq[4]+1)
+
+	var lower36 float64
+	switch {
+	case eq3 == '0' && eq6 == '0':
+		lower36 = math.Max(lookup(2, eq3+1), lookup(5, eq6+1))
+	case eq3 == '1' && eq6 == '0':
+		lower36 = lookup(5, eq6+1)
+	default:
+		lower36 = lookup(2, eq3+1)
+	}
+
+	maxVector := cvss40MaxVector(m, string(eq1), string(eq2), string(eq3)+string(eq6), string(eq4))
+
+	distance := func(keys ...string) (d float64) {
+		for _, k := range keys {
+			d += cvss40Levels[k][m[k]] - cvss40Levels[k][maxVector[k]]
+		}
+
+		return
+	}
+
+	items := []struct {
+		lower    float64
+		distance float64
+		depth    float64
+	}{
+		{lower1, distance("AV", "PR", "UI"), cvss40MaxSeverity["eq1"][string(eq1)]},
+		{lower2, distance("AC", "AT"), cvss40MaxSeverity["eq2"][string(eq2)]},
+		{lower36, distance("VC", "VI", "VA", "CR", "IR", "AR"), cvss40MaxSeverity["eq3eq6"][string(eq3)+string(eq6)]},
+		{lower4, distance("SC", "SI", "SA"), cvss40MaxSeverity["eq4"][string(eq4)]},
+		// E has only one metric, the distance is always 0
+		{lower5, 0, 1},
+	}
+
+	n, sum := 0, 0.0
+	for _, item := range items {
+		available := value - item.lower
+		if math.IsNaN(available) {
+			continue
+		}
+
+		n++
+		sum += available * item.distance / item.depth
+	}
+
+	if n > 0 {
+		value -= sum / float64(n)
+	}
+
+	return roundToOneDecimal(math.Min(math.Max(value, 0), 10))
+}
+
+// cvss40MacroVector returns the levels of EQ1 to EQ6
+func cvss40MacroVector(m map[string]string) string {
+	eq1 := "2"
+	switch {
+	case m["AV"] == "N" && m["PR"] == "N" && m["UI"] == "N":
+		eq1 = "0"
+	case (m["AV"] == "N" || m["PR"] == "N" || m["UI"] == "N") && m["AV"] != "P":
+		eq1 = "1"
+	}
+
+	eq2 := "1"
+	if m["AC"] == "L" && m["AT"] == "N" {
+		eq2 = "0"
+	}
+
+	eq3 := "2"
+	switch {
+	case m["VC"] == "H" && m["VI"] == "H":
+		eq3 = "0"
+	case m["VC"] == "H" || m["VI"] == "H" || m["VA"] == "H":
+		eq3 = "1"
+	}
+
+	eq4 := "2"
+	switch {
+	case m["SI"] == "S" || m["SA"] == "S":
+		eq4 = "0"
+	case m["SC"] == "H" || m["SI"] == "H" || m["SA"] == "H":
+		eq4 = "1"
+	}
+
+	// E is A
+	eq5 := "0"
+
+	eq6 := "1"
+	if (m["CR"] == "H" && m["VC"] == "H") || (m["IR"] == "H" && m["VI"] == "H") || (m["AR"] == "H" && m["VA"] == "H") {
+		eq6 = "0"
+	}
+
+	return eq1 + eq2 + eq3 + eq4 + eq5 + eq6
+}
+
+// cvss40MaxVector returns the first highest severity vector which is not less severe than the vector
+func cvss40MaxVector(m map[string]string, eq1, eq2, eq36, eq4 string) map[string]string {
+	for _, v1 := range cvss40MaxComposed["eq1"][eq1] {
+		for _, v2 := range cvss40MaxComposed["eq2"][eq2] {
+			for _, v36 := range cvss40MaxComposed["eq3eq6"][eq36] {
+				for _, v4 := range cvss40MaxComposed["eq4"][eq4] {
+					r := make(map[string]string)
+					for _, item := range strings.Split(strings.Join([]string{v1, v2, v36, v4}, "/"), "/") {
+						kv := strings.Split(item, ":")
+						r[kv[0]] = kv[1]
+					}
+
+					if isLessSevere(m, r) {
+						return r
+					}
+				}
+			}
+		}
+	}
+
+	// it never happens, because the vector is in the macro vector
+	panic(fmt.Sprintf("no max vector of %v", m))
+}
+
+func isLessSevere(m, max map[string]string) bool {
+	for k, v := range max {
+		if cvss40Levels[k][m[k]] < cvss40Levels[k][v] {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,254 @@
+package dp
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+)
+
+const rpmSuffix = ".rpm"
+
+// distTagRe matches the dist tag at the end of release, such as oe2203, oe2203sp1, el8_6, fc38
+var distTagRe = regexp.MustCompile(`^(oe|el|fc)[0-9][0-9a-z_]*$`)
+
+// NEVRA is the name, epoch, version, release and arch of a rpm
+type NEVRA struct {
+	Name    string
+	Epoch   string
+	Version string
+	Release string
+	Arch    string
+	DistTag string
+}
+
+// ParseNEVRA parses the file name of rpm, such as zbar-0.22-4.oe2203.src.rpm
+// or name-epoch:version-release.arch, the suffix .rpm is optional.
+func ParseNEVRA(s string) (NEVRA, error) {
+	s = strings.TrimSuffix(strings.TrimSpace(s), rpmSuffix)
+
+	i := strings.LastIndex(s, ".")
+	if i <= 0 || i == len(s)-1 {
+		return NEVRA{}, errors.New("invalid rpm, missing arch")
+	}
+
+	n := NEVRA{Arch: s[i+1:]}
+	s = s[:i]
+
+	i = strings.LastIndex(s, "-")
+	if i <= 0 || i == len(s)-1 {
+		return NEVRA{}, errors.New("invalid rpm, missing release")
+	}
+
+	n.Release = s[i+1:]
+	s = s[:i]
+
+	i = strings.LastIndex(s, "-")
+	if i <= 0 || i == len(s)-1 {
+		return NEVRA{}, errors.New("invalid rpm, missing version")
+	}
+
+	n.Name = s[:i]
+	n.Version = s[i+1:]
+
+	if j := strings.Index(n.Version, ":"); j >= 0 {
+		n.Epoch, n.Version = n.Version[:j], n.Version[j+1:]
+		if n.Epoch == "" || n.Version == "" {
+			return NEVRA{}, errors.New("invalid rpm, invalid epoch")
+		}
+	}
+
+	if j := strings.LastIndex(n.Release, "."); j > 0 && distTagRe.MatchString(n.Release[j+1:]) {
+		n.DistTag = n.Release[j+1:]
+	}
+
+	return n, nil
+}
+
+// EVR is [epoch:]version-release
+func (n NEVRA) EVR() string {
+	s := n.Version + "-" + n.Release
+	if n.Epoch != "" && n.Epoch != "0" {
+		s = n.Epoch + ":" + s
+	}
+
+	return s
+}
+
+// ID is name-[epoch:]version-release without dist tag, such as zbar-0.22-4
+func (n NEVRA) ID() string {
+	return n.Name + "-" + n.BaseEVR()
+}
+
+// BaseEVR is EVR without dist tag, it is comparable with the version reported by user
+func (n NEVRA) BaseEVR() string {
+	if n.DistTag != "" {
+		return strings.TrimSuffix(n.EVR(), "."+n.DistTag)
+	}
+
+	return n.EVR()
+}
+
+// FileName is the file name of rpm, epoch is not included in it
+func (n NEVRA) FileName() string {
+	return n.Name + "-" + n.Version + "-" + n.Release + "." + n.Arch + rpmSuffix
+}
+
+// CompareRPMVersion compares the version or release as rpmvercmp does,
+// it returns 1 if a is newer than b, -1 if older and 0 if equal.
+func CompareRPMVersion(a, b string) int {
+	if a == b {
+		return 0
+	}
+
+	for {
+		a = strings.TrimLeftFunc(a, isVersionSeparator)
+		b = strings.TrimLeftFunc(b, isVersionSeparator)
+
+		// tilde sorts before everything, even the end of version
+		if strings.HasPrefix(a, "~") || strings.HasPrefix(b, "~") {
+			if !strings.HasPrefix(a, "~") {
+				return 1
+			}
+			if !strings.HasPrefix(b, "~") {
+				return -1
+			}
+
+			a, b = a[1:], b[1:]
+
+			continue
+		}
+
+		// caret sorts after the end of version but before anything else
+		if strings.HasPrefix(a, "^") || strings.HasPrefix(b, "^") {
+			if a == "" {
+				return -1
+			}
+			if b == "" {
+				return 1
+			}
+			if !strings.HasPrefix(a, "^") {
+				return 1
+			}
+			if !strings.HasPrefix(b, "^") {
+				return -1
+			}
+
+			a, b = a[1:], b[1:]
+
+			continue
+		}
+
+		if a == "" || b == "" {
+			break
+		}
+
+		isNum := isDigit(rune(a[0]))
+		match := isAlpha
+		if isNum {
+			match = isDigit
+		}
+
+		sa, sb := leadingSegment(a, match), leadingSegment(b, match)
+		a, b = a[len(sa):], b[len(sb):]
+
+		// numeric segment is newer than alpha segment
+		if sb == "" {
+			if isNum {
+				return 1
+			}
+
+			return -1
+		}
+
+		if isNum {
+			sa = strings.TrimLeft(sa, "0")
+			sb = strings.TrimLeft(sb, "0")
+
+			if len(sa) != len(sb) {
+				if len(sa) > len(sb) {
+					return 1
+				}
+
+				return -1
+			}
+		}
+
+		if c := strings.Compare(sa, sb); c != 0 {
+			return c
+		}
+	}
+
+	switch {
+	case a == "" && b == "":
+		return 0
+	case a == "":
+		return -1
+	default:
+		return 1
+	}
+}
+
+// CompareEVR compares [epoch:]version[-release], the missing epoch is 0
+// and the release is compared only when both of them have it.
+func CompareEVR(a, b string) int {
+	ea, va, ra := splitEVR(a)
+	eb, vb, rb := splitEVR(b)
+
+	if r := CompareRPMVersion(ea, eb); r != 0 {
+		return r
+	}
+
+	if r := CompareRPMVersion(va, vb); r != 0 || ra == "" || rb == "" {
+		return r
+	}
+
+	return CompareRPMVersion(ra, rb)
+}
+
+// CompareVR compares version[-release] like CompareEVR but ignores the epochs,
+// the epoch of an rpm is not in the version reported by user.
+func CompareVR(a, b string) int {
+	_, va, ra := splitEVR(a)
+	_, vb, rb := splitEVR(b)
+
+	if r := CompareRPMVersion(va, vb); r != 0 || ra == "" || rb == "" {
+		return r
+	}
+
+	return CompareRPMVersion(ra, rb)
+}
+
+func splitEVR(s string) (epoch, version, release string) {
+	epoch, version = "0", strings.TrimSpace(s)
+
+	if i := strings.Index(version, ":"); i > 0 {
+		epoch, version = version[:i], version[i+1:]
+	}
+
+	if i := strings.LastIndex(version, "-"); i > 0 {
+		version, release = version[:i], version[i+1:]
+	}
+
+	return
+}
+
+func leadingSegment(s string, match func(rune) bool) string {
+	i := strings.IndexFunc(s, func(r rune) bool { return !match(r) })
+	if i < 0 {
+		return s
+	}
+
+	return s[:i]
+}
+
+func isDigit(r rune) bool {
+	return r >= '0' && r <= '9'
+}
+
+func isAlpha(r rune) bool {
+	return (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z')
+}
+
+func isVersionSeparator(r rune) bool {
+	return !isDigit(r) && !isAlpha(r) && r != '~' && r != '^'
+}
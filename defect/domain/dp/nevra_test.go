@@ -0,0 +1,100 @@
+package dp
+
+import "testing"
+
+func TestParseNEVRA(t *testing.T) {
+	cases := []struct {
+		rpm  string
+		want NEVRA
+		id   string
+	}{
+		{
+			rpm:  "zbar-0.22-4.oe2203.src.rpm",
+			want: NEVRA{Name: "zbar", Version: "0.22", Release: "4.oe2203", Arch: "src", DistTag: "oe2203"},
+			id:   "zbar-0.22-4",
+		},
+		{
+			rpm:  "kernel-5.10.0-60.92.0.119.oe2203sp1.aarch64.rpm",
+			want: NEVRA{Name: "kernel", Version: "5.10.0", Release: "60.92.0.119.oe2203sp1", Arch: "aarch64", DistTag: "oe2203sp1"},
+			id:   "kernel-5.10.0-60.92.0.119",
+		},
+		{
+			rpm:  "python3-pip-wheel-20.2.2-1.oe2203.noarch.rpm",
+			want: NEVRA{Name: "python3-pip-wheel", Version: "20.2.2", Release: "1.oe2203", Arch: "noarch", DistTag: "oe2203"},
+			id:   "python3-pip-wheel-20.2.2-1",
+		},
+		{
+			rpm:  "openssl-1:1.1.1m-2.x86_64",
+			want: NEVRA{Name: "openssl", Epoch: "1", Version: "1.1.1m", Release: "2", Arch: "x86_64"},
+			id:   "openssl-1:1.1.1m-2",
+		},
+	}
+
+	for _, c := range cases {
+		got, err := ParseNEVRA(c.rpm)
+		if err != nil {
+			t.Errorf("parse %s failed, err:%s", c.rpm, err.Error())
+
+			continue
+		}
+
+		if got != c.want {
+			t.Errorf("parse %s, got %+v, want %+v", c.rpm, got, c.want)
+		}
+
+		if got.ID() != c.id {
+			t.Errorf("id of %s, got %s, want %s", c.rpm, got.ID(), c.id)
+		}
+	}
+
+	for _, v := range []string{"", "zbar.src.rpm", "zbar-4.src.rpm", "zbar-0.22-4.rpm."} {
+		if _, err := ParseNEVRA(v); err == nil {
+			t.Errorf("parse %s, expect error", v)
+		}
+	}
+}
+
+func TestCompareRPMVersion(t *testing.T) {
+	cases := []struct {
+		a, b string
+		want int
+	}{
+		{"1.0", "1.0", 0},
+		{"1.0", "1.0.1", -1},
+		{"2.10", "2.9", 1},
+		{"1.0a", "1.0", 1},
+		{"1.0", "1.0~rc1", 1},
+		{"1.0~rc1", "1.0~rc2", -1},
+		{"1.0^git1", "1.0", 1},
+		{"1.0^git1", "1.0.1", -1},
+		{"1.010", "1.10", 0},
+		{"1.a", "1.1", -1},
+		{"0.22", "0.22.1", -1},
+	}
+
+	for _, c := range cases {
+		if got := CompareRPMVersion(c.a, c.b); got != c.want {
+			t.Errorf("compare %s with %s, got %d, want %d", c.a, c.b, got, c.want)
+		}
+	}
+}
+
+func TestCompareEVR(t *testing.T) {
+	cases := []struct {
+		a, b string
+		want int
+	}{
+		{"0.22-4", "0.22-3", 1},
+		{"0.22-4.oe2203", "0.22", 0},
+		{"0.22-4", "0.23", -1},
+		{"1:0.21-1", "0.22-1", 1},
+		{"0:0.22-1", "0.22-1", 0},
+		{"5.10.0-60.92.0", "5.10.0-60.100.0", -1},
+	}
+
+	for _, c := range cases {
+		if got := CompareEVR(c.a, c.b); got != c.want {
+			t.Errorf("compare %s with %s, got %d, want %d", c.a, c.b, got, c.want)
+		}
+	}
+}
@@ -0,0 +1,158 @@
+package dp
+
+import (
+	"errors"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+const (
+	cve            = "cve"
+	cwe            = "cwe"
+	upstreamCommit = "upstream_commit"
+	upstreamBug    = "upstream_bug"
+)
+
+var (
+	validReferenceType = map[string]bool{
+		cve:            true,
+		cwe:            true,
+		upstreamCommit: true,
+		upstreamBug:    true,
+	}
+
+	ReferenceTypeCVE            = referenceType(cve)
+	ReferenceTypeCWE            = referenceType(cwe)
+	ReferenceTypeUpstreamCommit = referenceType(upstreamCommit)
+	ReferenceTypeUpstreamBug    = referenceType(upstreamBug)
+
+	cveRe = regexp.MustCompile(`(?i)\bCVE-\d{4}-\d{4,}\b`)
+	cweRe = regexp.MustCompile(`(?i)\bCWE-\d+\b`)
+	urlRe = regexp.MustCompile(`https?://[^\s<>()\[\]"'，。；]+`)
+
+	commitURLRe = regexp.MustCompile(`(?i)/commits?(/|\?)|/-/commit/|[?&]h=[0-9a-f]{7,}`)
+	bugURLRe    = regexp.MustCompile(`(?i)/issues/\d+|/-/issues/|show_bug\.cgi|/bugs?/|/browse/[A-Z][A-Z0-9]+-\d+`)
+
+	// downstreamURLs are the urls of openEuler itself, they are not upstream references
+	downstreamURLs = []string{
+		"https://gitee.com/src-openeuler/",
+		"https://gitee.com/openeuler/",
+	}
+)
+
+type referenceType string
+
+type ReferenceType interface {
+	String() string
+}
+
+func NewReferenceType(s string) (ReferenceType, error) {
+	if !validReferenceType[s] {
+		return nil, errors.New("invalid reference type")
+	}
+
+	return referenceType(s), nil
+}
+
+func (t referenceType) String() string {
+	return string(t)
+}
+
+type reference struct {
+	t  ReferenceType
+	id string
+}
+
+// Reference ID is CVE-2023-1234 or CWE-79 for cve and cwe, and the url for the upstream ones
+type Reference interface {
+	Type() ReferenceType
+	ID() string
+}
+
+func NewReference(t ReferenceType, id string) (Reference, error) {
+	if t == nil {
+		return nil, errors.New("missing reference type")
+	}
+
+	id = strings.TrimSpace(id)
+
+	switch t.String() {
+	case cve, cwe:
+		re := cveRe
+		if t.String() == cwe {
+			re = cweRe
+		}
+
+		if re.FindString(id) != id {
+			return nil, errors.New("invalid " + t.String() + " id")
+		}
+
+		id = strings.ToUpper(id)
+	default:
+		if _, err := url.ParseRequestURI(id); err != nil || !strings.HasPrefix(id, "http") {
+			return nil, errors.New("invalid url of " + t.String())
+		}
+	}
+
+	return reference{t: t, id: id}, nil
+}
+
+func (r reference) Type() ReferenceType {
+	return r.t
+}
+
+func (r reference) ID() string {
+	return r.id
+}
+
+// ParseReferences extracts the cve and cwe ids, and the urls of upstream commits and bugs
+// from the text, the duplicate ones are removed and the order is kept.
+func ParseReferences(text string) []Reference {
+	var r []Reference
+	seen := map[string]bool{}
+
+	add := func(t ReferenceType, id string) {
+		ref, err := NewReference(t, id)
+		if err != nil || seen[t.String()+ref.ID()] {
+			return
+		}
+
+		seen[t.String()+ref.ID()] = true
+		r = append(r, ref)
+	}
+
+	for _, v := range cveRe.FindAllString(text, -1) {
+		add(ReferenceTypeCVE, v)
+	}
+
+	for _, v := range cweRe.FindAllString(text, -1) {
+		add(ReferenceTypeCWE, v)
+	}
+
+	for _, v := range urlRe.FindAllString(text, -1) {
+		v = strings.TrimRight(v, ".,;:")
+		if isDownstreamURL(v) {
+			continue
+		}
+
+		switch {
+		case commitURLRe.MatchString(v):
+			add(ReferenceTypeUpstreamCommit, v)
+		case bugURLRe.MatchString(v):
+			add(ReferenceTypeUpstreamBug, v)
+		}
+	}
+
+	return r
+}
+
+func isDownstreamURL(s string) bool {
+	for _, v := range downstreamURLs {
+		if strings.HasPrefix(s, v) {
+			return true
+		}
+	}
+
+	return false
+}
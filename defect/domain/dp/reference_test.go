@@ -0,0 +1,50 @@
+package dp
+
+import "testing"
+
+func TestParseReferences(t *testing.T) {
+	text := `zbar存在越界读, 见 cve-2023-40889 和 CVE-2023-40889, CWE-125。
+修复补丁: https://github.com/mchehab/zbar/commit/a1b2c3d4e5f6.
+上游问题: https://github.com/mchehab/zbar/issues/231
+内核补丁: https://git.kernel.org/pub/scm/linux/kernel/git/stable/linux.git/commit/?id=0123abcd
+https://bugzilla.redhat.com/show_bug.cgi?id=2237461
+https://gitee.com/src-openeuler/zbar/issues/I7XYZ1
+https://nvd.nist.gov/vuln/detail/CVE-2023-40889`
+
+	want := []struct {
+		t  string
+		id string
+	}{
+		{cve, "CVE-2023-40889"},
+		{cwe, "CWE-125"},
+		{upstreamCommit, "https://github.com/mchehab/zbar/commit/a1b2c3d4e5f6"},
+		{upstreamBug, "https://github.com/mchehab/zbar/issues/231"},
+		{upstreamCommit, "https://git.kernel.org/pub/scm/linux/kernel/git/stable/linux.git/commit/?id=0123abcd"},
+		{upstreamBug, "https://bugzilla.redhat.com/show_bug.cgi?id=2237461"},
+	}
+
+	got := ParseReferences(text)
+	if len(got) != len(want) {
+		t.Fatalf("got %d references, want %d", len(got), len(want))
+	}
+
+	for i, v := range want {
+		if got[i].Type().String() != v.t || got[i].ID() != v.id {
+			t.Errorf("got %s %s, want %s %s", got[i].Type().String(), got[i].ID(), v.t, v.id)
+		}
+	}
+}
+
+func TestNewReference(t *testing.T) {
+	if _, err := NewReference(ReferenceTypeCVE, "CVE-2023-1"); err == nil {
+		t.Error("CVE-2023-1 is invalid")
+	}
+
+	if _, err := NewReference(ReferenceTypeCWE, "CWE-79 "); err != nil {
+		t.Errorf("CWE-79 is valid, err:%s", err.Error())
+	}
+
+	if _, err := NewReference(ReferenceTypeUpstreamBug, "not a url"); err == nil {
+		t.Error("url is required")
+	}
+}
@@ -2,25 +2,19 @@ package dp
 
 import (
 	"errors"
+	"fmt"
+	"strings"
 )
 
-var MaintainVersion = make(map[SystemVersion]bool)
-
 type systemVersion string
 
 type SystemVersion interface {
 	String() string
 }
 
-func Init(maintainVersion []string) {
-	for _, version := range maintainVersion {
-		MaintainVersion[systemVersion(version)] = true
-	}
-}
-
 func NewSystemVersion(s string) (SystemVersion, error) {
-	// MaintainVersion is not used for validation because
-	// there is an error reading old data from the database when maintainVersion changes
+	// the maintained versions are not used for validation because
+	// there is an error reading old data from the database when they change
 	if s == "" {
 		return nil, errors.New("invalid system version")
 	}
@@ -31,3 +25,15 @@ func NewSystemVersion(s string) (SystemVersion, error) {
 func (s systemVersion) String() string {
 	return string(s)
 }
+
+// CPEOfSystemVersion converts openEuler-22.03-LTS-SP1 to cpe:/a:openEuler:openEuler:22.03-LTS-SP1
+func CPEOfSystemVersion(s SystemVersion) string {
+	v := s.String()
+
+	i := strings.Index(v, "-")
+	if i < 0 {
+		return fmt.Sprintf("cpe:/a:%s:%s", v, v)
+	}
+
+	return fmt.Sprintf("cpe:/a:%s:%s:%s", v[:i], v[:i], v[i+1:])
+}
@@ -0,0 +1,60 @@
+package domain
+
+import "time"
+
+const (
+	EventDefectAccepted    = "defect_accepted"
+	EventBulletinGenerated = "bulletin_generated"
+)
+
+func IsValidEvent(s string) bool {
+	return s == EventDefectAccepted || s == EventBulletinGenerated
+}
+
+// Event is something happened to the defects that the subscribers care about,
+// Components, Repos and Severity are used to match the subscriptions.
+// Title and Content are the plain text sent by the channels.
+type Event struct {
+	Type       string
+	Title      string
+	Content    string
+	Components []string
+	Repos      []string
+	Severity   []string
+	OccurredAt time.Time
+}
+
+// Subscription receives the events via the channel, Recipients are used by the channels
+// which send to the specified receivers, such as email. The empty condition matches all.
+type Subscription struct {
+	Name       string
+	Channel    string
+	Events     []string
+	Components []string
+	SIGs       []string
+	Severity   []string
+	Recipients []string
+}
+
+// Match sigs is the sigs of the repos of event
+func (s *Subscription) Match(e *Event, sigs []string) bool {
+	return (len(s.Events) == 0 || containsString(s.Events, e.Type)) &&
+		isIntersected(s.Components, e.Components) &&
+		isIntersected(s.SIGs, sigs) &&
+		isIntersected(s.Severity, e.Severity)
+}
+
+// isIntersected returns true if the condition is empty or any of the values is in it
+func isIntersected(condition, values []string) bool {
+	if len(condition) == 0 {
+		return true
+	}
+
+	for _, v := range values {
+		if containsString(condition, v) {
+			return true
+		}
+	}
+
+	return false
+}
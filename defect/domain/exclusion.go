@@ -0,0 +1,137 @@
+package domain
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/opensourceways/defect-manager/defect/domain/dp"
+)
+
+// the reasons why a requested issue is not in any bulletin
+const (
+	ExclusionNotFound          = "not_found"
+	ExclusionNotClosed         = "not_closed"
+	ExclusionPublished         = "already_published"
+	ExclusionNoAffectedVersion = "no_affected_version"
+	ExclusionNotReleased       = "not_released"
+	ExclusionNoProductTree     = "missing_product_tree"
+	ExclusionGenerateFailed    = "generate_failed"
+	ExclusionUploadFailed      = "upload_failed"
+)
+
+// Exclusion is a requested issue excluded from the bulletins, Issue is the key of issue.
+// Versions is the excluded versions when the issue is excluded only in some of them, it is empty
+// if the whole issue is excluded.
+type Exclusion struct {
+	Issue    string
+	Reason   string
+	Detail   string
+	Versions []dp.SystemVersion
+}
+
+// Candidates checks the defects found by the keys of the requested issues, it returns the defects
+// which can be put into bulletins in the requested order and the excluded issues.
+func (ds Defects) Candidates(keys []string, policy PublishPolicy, maintained []dp.SystemVersion) (
+	Defects, []Exclusion,
+) {
+	found := make(map[string]*Defect, len(ds))
+	for i := range ds {
+		found[ds[i].Issue.Key()] = &ds[i]
+	}
+
+	var r Defects
+	var es []Exclusion
+	checked := make(map[string]bool, len(keys))
+	for _, k := range keys {
+		if checked[k] {
+			continue
+		}
+
+		checked[k] = true
+
+		if e, ok := checkCandidate(k, found[k], &policy, maintained); !ok {
+			es = append(es, e)
+		} else {
+			r = append(r, *found[k])
+		}
+	}
+
+	return r, es
+}
+
+func checkCandidate(key string, d *Defect, policy *PublishPolicy, maintained []dp.SystemVersion) (
+	Exclusion, bool,
+) {
+	e := Exclusion{Issue: key}
+
+	var detail string
+	allowed := true
+	if d != nil {
+		detail, allowed = policy.check(&d.Issue)
+	}
+
+	switch {
+	case d == nil:
+		e.Reason = ExclusionNotFound
+
+	case d.Issue.Status == nil || d.Issue.Status.String() != dp.IssueStatusClosed.String():
+		e.Reason = ExclusionNotClosed
+		if d.Issue.Status != nil {
+			e.Detail = "the status is " + d.Issue.Status.String()
+		}
+
+	case !allowed:
+		e.Reason = ExclusionPublished
+		e.Detail = detail
+
+	case len(d.maintainedVersions(maintained)) == 0:
+		e.Reason = ExclusionNoAffectedVersion
+		e.Detail = fmt.Sprintf("the affected versions [%s] are not maintained", joinVersions(d.AffectedVersion))
+
+	default:
+		return e, true
+	}
+
+	return e, false
+}
+
+// maintainedVersions returns the affected versions which are maintained
+func (d *Defect) maintainedVersions(maintained []dp.SystemVersion) []dp.SystemVersion {
+	var r []dp.SystemVersion
+	for _, v := range maintained {
+		if d.isAffectVersion(v) {
+			r = append(r, v)
+		}
+	}
+
+	return r
+}
+
+// ExcludeBulletin excludes all the issues of the bulletin for the same reason,
+// each issue is excluded only in the versions of the bulletin since it may be split into the others.
+func ExcludeBulletin(sb *SecurityBulletin, reason, detail string) []Exclusion {
+	r := make([]Exclusion, len(sb.Defects))
+	for i := range sb.Defects {
+		d := &sb.Defects[i]
+
+		var versions []dp.SystemVersion
+		for _, v := range sb.AffectedVersion {
+			if d.isAffectVersion(v) {
+				versions = append(versions, v)
+			}
+		}
+
+		r[i] = Exclusion{Issue: d.Issue.Key(), Reason: reason, Detail: detail, Versions: versions}
+	}
+
+	return r
+}
+
+func joinVersions(vs []dp.SystemVersion) string {
+	items := make([]string, len(vs))
+	for i, v := range vs {
+		items[i] = v.String()
+	}
+
+	return strings.Join(items, ", ")
+}
@@ -0,0 +1,91 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/opensourceways/defect-manager/defect/domain/dp"
+)
+
+func TestCandidates(t *testing.T) {
+	maintained := testVersions("v1", "v2")
+	open, _ := dp.NewIssueStatus("open")
+
+	ds := Defects{
+		testDefect("zbar", "zbar", "I1", "v1"),
+		testDefect("curl", "curl", "I2", "v1"),
+		testDefect("vim", "vim", "I3", "v3"),
+		testDefect("gcc", "gcc", "I4", "v2"),
+		testDefect("git", "git", "I5"),
+	}
+	for i := range ds {
+		ds[i].Issue.Status = dp.IssueStatusClosed
+	}
+	ds[3].Issue.Status = open
+
+	policy := PublishPolicy{Published: map[string]string{
+		"src-openeuler/curl/I2": "cvrf-openEuler-BA-2023-1001",
+		// the issue of another repo with the same number
+		"src-openeuler/zlib/I1": "cvrf-openEuler-BA-2023-1002",
+	}}
+
+	keys := []string{
+		"src-openeuler/zbar/I1", "src-openeuler/curl/I2", "src-openeuler/vim/I3", "src-openeuler/gcc/I4",
+		"src-openeuler/git/I5", "src-openeuler/zlib/I1", "src-openeuler/zbar/I1",
+	}
+
+	r, es := ds.Candidates(keys, policy, maintained)
+	if len(r) != 1 || r[0].Issue.Key() != "src-openeuler/zbar/I1" {
+		t.Errorf("unexpected candidates: %v", r)
+	}
+
+	want := map[string]string{
+		"src-openeuler/curl/I2": ExclusionPublished,
+		"src-openeuler/vim/I3":  ExclusionNoAffectedVersion,
+		"src-openeuler/gcc/I4":  ExclusionNotClosed,
+		"src-openeuler/git/I5":  ExclusionNoAffectedVersion,
+		"src-openeuler/zlib/I1": ExclusionNotFound,
+	}
+
+	if len(es) != len(want) {
+		t.Fatalf("unexpected exclusions: %v", es)
+	}
+
+	for _, e := range es {
+		if want[e.Issue] != e.Reason {
+			t.Errorf("issue %s, got %s, want %s", e.Issue, e.Reason, want[e.Issue])
+		}
+	}
+}
+
+func TestCandidatesForced(t *testing.T) {
+	ds := Defects{testDefect("zbar", "zbar", "I1", "v1")}
+	ds[0].Issue.Status = dp.IssueStatusClosed
+
+	policy := PublishPolicy{Published: map[string]string{"src-openeuler/zbar/I1": ""}, Force: true}
+
+	if r, es := ds.Candidates([]string{"src-openeuler/zbar/I1"}, policy, testVersions("v1")); len(r) != 1 || len(es) != 0 {
+		t.Errorf("the published issue must be re-issued when forced, got %v, %v", r, es)
+	}
+}
+
+func TestExcludeBulletinOfVersion(t *testing.T) {
+	maintained := testVersions("v1", "v2")
+	ds := Defects{testDefect("zbar", "zbar", "I1", "v1", "v2"), testDefect("zbar", "zbar", "I2", "v1")}
+
+	// I2 does not affect v2, so zbar is split into one bulletin per version
+	sbs, err := NewAutoGrouping().Group(ds, maintained)
+	if err != nil || len(sbs) != 2 {
+		t.Fatalf("unexpected bulletins: %v, %v", sbs, err)
+	}
+
+	es := ExcludeBulletin(&sbs[0], ExclusionNoProductTree, "")
+	if len(es) != 2 {
+		t.Fatalf("unexpected exclusions: %v", es)
+	}
+
+	for _, e := range es {
+		if len(e.Versions) != 1 || e.Versions[0] != maintained[0] {
+			t.Errorf("issue %s must be excluded only in v1, got %v", e.Issue, e.Versions)
+		}
+	}
+}
@@ -0,0 +1,47 @@
+package domain
+
+import "time"
+
+const (
+	GenerationRunning   = "running"
+	GenerationSucceeded = "succeeded"
+	GenerationFailed    = "failed"
+)
+
+// Generation is a generation of bulletins requested by the api which runs in the background,
+// Issues is the keys of the requested issues, Report is the json of the report if it succeeds
+// and Error is why it fails.
+type Generation struct {
+	ID        string
+	Issues    []string
+	Status    string
+	Report    []byte
+	Error     string
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+func NewGeneration(id string, issues []string, now time.Time) Generation {
+	return Generation{
+		ID:        id,
+		Issues:    issues,
+		Status:    GenerationRunning,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+}
+
+// Done records the result, report is ignored if err is not nil
+func (g *Generation) Done(report []byte, err error, now time.Time) {
+	g.UpdatedAt = now
+
+	if err != nil {
+		g.Status = GenerationFailed
+		g.Error = err.Error()
+
+		return
+	}
+
+	g.Status = GenerationSucceeded
+	g.Report = report
+}
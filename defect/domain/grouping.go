@@ -0,0 +1,266 @@
+package domain
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/opensourceways/defect-manager/defect/domain/dp"
+)
+
+const (
+	// GroupingAuto combines the defects of a component into one bulletin when all of them
+	// affect all the maintained versions, otherwise it splits them into one bulletin per version
+	GroupingAuto = "auto"
+	// GroupingComponent puts all the defects of a component into one bulletin
+	GroupingComponent = "component"
+	// GroupingVersionSet puts the versions of a component affected by the same defects into one bulletin
+	GroupingVersionSet = "version_set"
+	// GroupingSIG puts all the defects of the repos maintained by a sig into one bulletin
+	GroupingSIG = "sig"
+	// GroupingManual puts the defects into the bulletins specified by the issue keys
+	GroupingManual = "manual"
+)
+
+func IsValidGrouping(s string) bool {
+	switch s {
+	case GroupingAuto, GroupingComponent, GroupingVersionSet, GroupingSIG, GroupingManual:
+		return true
+	}
+
+	return false
+}
+
+// GroupingStrategy groups the defects into bulletins. Only the maintained versions are kept,
+// the bulletins and the defects in them are in a deterministic order,
+// so the identifications are assigned in the same order for the same defects.
+// The date and identification of bulletins are left to the caller.
+type GroupingStrategy interface {
+	Group(ds Defects, maintained []dp.SystemVersion) ([]SecurityBulletin, error)
+}
+
+func NewAutoGrouping() GroupingStrategy {
+	return autoGrouping{}
+}
+
+func NewComponentGrouping() GroupingStrategy {
+	return componentGrouping{}
+}
+
+func NewVersionSetGrouping() GroupingStrategy {
+	return versionSetGrouping{}
+}
+
+// NewSIGGrouping sigs is the sig of the repo of issue, the defects whose sig is unknown are grouped by component
+func NewSIGGrouping(sigs map[string]string) GroupingStrategy {
+	return sigGrouping{sigs: sigs}
+}
+
+// NewManualGrouping groups is the keys(org/repo/number) of the issues of each bulletin,
+// every defect must be in exactly one group. The keys of the excluded defects are skipped.
+func NewManualGrouping(groups [][]string) GroupingStrategy {
+	return manualGrouping{groups: groups}
+}
+
+type autoGrouping struct{}
+
+func (g autoGrouping) Group(ds Defects, maintained []dp.SystemVersion) ([]SecurityBulletin, error) {
+	var sbs []SecurityBulletin
+	for _, dsc := range ds.groupByComponent() {
+		if dsc.isCombined(maintained) {
+			sbs = append(sbs, newBulletin(maintained, Defects(dsc)))
+		} else {
+			sbs = append(sbs, dsc.separatedBulletins(maintained)...)
+		}
+	}
+
+	return sbs, nil
+}
+
+type componentGrouping struct{}
+
+func (g componentGrouping) Group(ds Defects, maintained []dp.SystemVersion) ([]SecurityBulletin, error) {
+	var sbs []SecurityBulletin
+	for _, dsc := range ds.groupByComponent() {
+		if sb, ok := newBulletinOfAffected(maintained, Defects(dsc)); ok {
+			sbs = append(sbs, sb)
+		}
+	}
+
+	return sbs, nil
+}
+
+type versionSetGrouping struct{}
+
+func (g versionSetGrouping) Group(ds Defects, maintained []dp.SystemVersion) ([]SecurityBulletin, error) {
+	var sbs []SecurityBulletin
+	for _, dsc := range ds.groupByComponent() {
+		var keys []string
+		versions := make(map[string][]dp.SystemVersion)
+		defects := make(map[string]Defects)
+
+		for _, v := range maintained {
+			var affected Defects
+			var ids []string
+			for _, d := range dsc {
+				if d.isAffectVersion(v) {
+					affected = append(affected, d)
+					ids = append(ids, d.Issue.Key())
+				}
+			}
+
+			if len(affected) == 0 {
+				continue
+			}
+
+			key := strings.Join(ids, ",")
+			if _, ok := versions[key]; !ok {
+				keys = append(keys, key)
+				defects[key] = affected
+			}
+
+			versions[key] = append(versions[key], v)
+		}
+
+		for _, k := range keys {
+			sbs = append(sbs, newBulletin(versions[k], defects[k]))
+		}
+	}
+
+	return sbs, nil
+}
+
+type sigGrouping struct {
+	sigs map[string]string
+}
+
+func (g sigGrouping) Group(ds Defects, maintained []dp.SystemVersion) ([]SecurityBulletin, error) {
+	var keys []string
+	groups := make(map[string]Defects)
+	for _, d := range ds.sorted() {
+		key := g.sigs[d.Issue.RepoPath()]
+		if key == "" {
+			// the component is never the name of a sig because of the prefix
+			key = "component:" + d.Component
+		}
+
+		if _, ok := groups[key]; !ok {
+			keys = append(keys, key)
+		}
+
+		groups[key] = append(groups[key], d)
+	}
+
+	var sbs []SecurityBulletin
+	for _, k := range keys {
+		if sb, ok := newBulletinOfAffected(maintained, groups[k]); ok {
+			sbs = append(sbs, sb)
+		}
+	}
+
+	return sbs, nil
+}
+
+type manualGrouping struct {
+	groups [][]string
+}
+
+// Group keeps the order of the groups specified by the user
+func (g manualGrouping) Group(ds Defects, maintained []dp.SystemVersion) ([]SecurityBulletin, error) {
+	defects := make(map[string]*Defect, len(ds))
+	for i := range ds {
+		defects[ds[i].Issue.Key()] = &ds[i]
+	}
+
+	grouped := make(map[string]bool, len(ds))
+
+	var sbs []SecurityBulletin
+	for i, group := range g.groups {
+		if len(group) == 0 {
+			return nil, fmt.Errorf("group %d is empty", i+1)
+		}
+
+		var dsg Defects
+		for _, k := range group {
+			if grouped[k] {
+				return nil, fmt.Errorf("issue %s is in more than one group", k)
+			}
+
+			grouped[k] = true
+
+			if d, ok := defects[k]; ok {
+				dsg = append(dsg, *d)
+			}
+		}
+
+		if sb, ok := newBulletinOfAffected(maintained, dsg); ok {
+			sbs = append(sbs, sb)
+		}
+	}
+
+	for i := range ds {
+		if k := ds[i].Issue.Key(); !grouped[k] {
+			return nil, fmt.Errorf("issue %s is not in any group", k)
+		}
+	}
+
+	return sbs, nil
+}
+
+// newBulletinOfAffected returns a bulletin of the maintained versions affected by any of the defects,
+// it returns false if none of them is affected.
+func newBulletinOfAffected(maintained []dp.SystemVersion, ds Defects) (SecurityBulletin, bool) {
+	var versions []dp.SystemVersion
+	for _, v := range maintained {
+		for i := range ds {
+			if ds[i].isAffectVersion(v) {
+				versions = append(versions, v)
+
+				break
+			}
+		}
+	}
+
+	if len(versions) == 0 {
+		return SecurityBulletin{}, false
+	}
+
+	return newBulletin(versions, ds), true
+}
+
+func newBulletin(versions []dp.SystemVersion, ds Defects) SecurityBulletin {
+	sb := SecurityBulletin{
+		AffectedVersion: versions,
+		Defects:         ds.sorted(),
+	}
+
+	sb.Component = strings.Join(sb.Components(), ", ")
+
+	return sb
+}
+
+// sorted returns a copy sorted by component and issue
+func (ds Defects) sorted() Defects {
+	r := make(Defects, len(ds))
+	copy(r, ds)
+
+	sort.SliceStable(r, func(i, j int) bool {
+		if r[i].Component != r[j].Component {
+			return r[i].Component < r[j].Component
+		}
+
+		return r[i].Issue.Key() < r[j].Issue.Key()
+	})
+
+	return r
+}
+
+func containsString(items []string, s string) bool {
+	for _, v := range items {
+		if v == s {
+			return true
+		}
+	}
+
+	return false
+}
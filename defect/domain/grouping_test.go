@@ -0,0 +1,121 @@
+package domain
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/opensourceways/defect-manager/defect/domain/dp"
+)
+
+func testVersions(names ...string) []dp.SystemVersion {
+	r := make([]dp.SystemVersion, len(names))
+	for i, v := range names {
+		r[i], _ = dp.NewSystemVersion(v)
+	}
+
+	return r
+}
+
+func testDefect(component, repo, number string, versions ...string) Defect {
+	return Defect{
+		Component:       component,
+		AffectedVersion: testVersions(versions...),
+		Issue:           Issue{Org: "src-openeuler", Repo: repo, Number: number},
+	}
+}
+
+// summary is like "zbar:v1,v2:I1,I2;...", the date is excluded
+func summary(sbs []SecurityBulletin) string {
+	items := make([]string, len(sbs))
+	for i := range sbs {
+		vs := make([]string, len(sbs[i].AffectedVersion))
+		for j, v := range sbs[i].AffectedVersion {
+			vs[j] = v.String()
+		}
+
+		ns := make([]string, len(sbs[i].Defects))
+		for j := range sbs[i].Defects {
+			ns[j] = sbs[i].Defects[j].Issue.Number
+		}
+
+		items[i] = sbs[i].Component + ":" + strings.Join(vs, ",") + ":" + strings.Join(ns, ",")
+	}
+
+	return strings.Join(items, ";")
+}
+
+func TestGrouping(t *testing.T) {
+	maintained := testVersions("v1", "v2", "v3")
+
+	ds := Defects{
+		testDefect("zbar", "zbar", "I3", "v1", "v2"),
+		testDefect("curl", "curl", "I2", "v1", "v2", "v3"),
+		testDefect("zbar", "zbar", "I1", "v1", "v2", "v3"),
+		testDefect("vim", "vim", "I4", "v3", "v4"),
+	}
+
+	cases := []struct {
+		name     string
+		strategy GroupingStrategy
+		want     string
+	}{
+		{
+			"auto", NewAutoGrouping(),
+			"curl:v1,v2,v3:I2;vim:v3:I4;zbar:v1:I1,I3;zbar:v2:I1,I3;zbar:v3:I1",
+		},
+		{
+			"component", NewComponentGrouping(),
+			"curl:v1,v2,v3:I2;vim:v3:I4;zbar:v1,v2,v3:I1,I3",
+		},
+		{
+			"version set", NewVersionSetGrouping(),
+			"curl:v1,v2,v3:I2;vim:v3:I4;zbar:v1,v2:I1,I3;zbar:v3:I1",
+		},
+		{
+			"sig", NewSIGGrouping(map[string]string{"src-openeuler/zbar": "sig-a", "src-openeuler/vim": "sig-a"}),
+			"curl:v1,v2,v3:I2;vim, zbar:v1,v2,v3:I4,I1,I3",
+		},
+		{
+			"manual", NewManualGrouping([][]string{
+				{"src-openeuler/zbar/I3", "src-openeuler/vim/I4"}, {"src-openeuler/curl/I2", "src-openeuler/zbar/I1"},
+			}),
+			"vim, zbar:v1,v2,v3:I4,I3;curl, zbar:v1,v2,v3:I2,I1",
+		},
+	}
+
+	for _, c := range cases {
+		// the result must not depend on the order of the defects
+		for _, input := range []Defects{ds, {ds[3], ds[2], ds[1], ds[0]}} {
+			sbs, err := c.strategy.Group(input, maintained)
+			if err != nil {
+				t.Fatalf("%s: %s", c.name, err.Error())
+			}
+
+			if got := summary(sbs); got != c.want {
+				t.Errorf("%s: got %s, want %s", c.name, got, c.want)
+			}
+		}
+	}
+}
+
+func TestManualGroupingError(t *testing.T) {
+	maintained := testVersions("v1")
+	ds := Defects{
+		testDefect("zbar", "zbar", "I1", "v1"),
+		testDefect("curl", "curl", "I2", "v1"),
+	}
+
+	groups := [][][]string{
+		{{"src-openeuler/zbar/I1"}},
+		{{"src-openeuler/zbar/I1", "src-openeuler/curl/I2"}, {"src-openeuler/curl/I2"}},
+		{{"src-openeuler/zbar/I1", "src-openeuler/curl/I2"}, {}},
+		// the issue of another repo with the same number
+		{{"src-openeuler/zbar/I1", "src-openeuler/zlib/I2"}},
+	}
+
+	for _, g := range groups {
+		if _, err := NewManualGrouping(g).Group(ds, maintained); err == nil {
+			t.Errorf("groups %v: expect error", g)
+		}
+	}
+}
@@ -0,0 +1,98 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/opensourceways/defect-manager/defect/domain/dp"
+)
+
+const (
+	NotificationPending   = "pending"
+	NotificationSucceeded = "succeeded"
+	NotificationFailed    = "failed"
+)
+
+func IsValidNotificationStatus(s string) bool {
+	return s == NotificationPending || s == NotificationSucceeded || s == NotificationFailed
+}
+
+// maxBackoffShift limits the times the retry interval is doubled
+const maxBackoffShift = 10
+
+// Notification tells the downstream that the bulletin has been uploaded to File in OBS,
+// Issues is the keys(org/repo/number) of the issues, Attempts is the count of tries and
+// LastError is the error of the latest failed one. It is pending until it succeeds or
+// the attempts run out, then it is failed.
+type Notification struct {
+	Identification  string
+	Component       string
+	AffectedVersion []dp.SystemVersion
+	Issues          []string
+	File            string
+	PublishedAt     time.Time
+	Status          string
+	Attempts        int
+	LastError       string
+	UpdatedAt       time.Time
+}
+
+func NewNotification(sb *SecurityBulletin, file string) Notification {
+	n := Notification{
+		Identification:  sb.Identification,
+		Component:       sb.Component,
+		AffectedVersion: sb.AffectedVersion,
+		Issues:          make([]string, len(sb.Defects)),
+		File:            file,
+		PublishedAt:     sb.Date,
+		Status:          NotificationPending,
+		UpdatedAt:       sb.Date,
+	}
+
+	for i := range sb.Defects {
+		n.Issues[i] = sb.Defects[i].Issue.Key()
+	}
+
+	return n
+}
+
+// Record updates the status with the result of an attempt, it is failed if the attempt
+// is the maxAttempts one or more.
+func (n *Notification) Record(err error, now time.Time, maxAttempts int) {
+	n.Attempts++
+	n.UpdatedAt = now
+
+	if err != nil {
+		n.LastError = err.Error()
+
+		if n.Attempts >= maxAttempts {
+			n.Status = NotificationFailed
+		} else {
+			n.Status = NotificationPending
+		}
+	} else {
+		n.Status = NotificationSucceeded
+		n.LastError = ""
+	}
+}
+
+// IsDue the pending notification is tried at once, then after interval which is doubled after each failure
+func (n *Notification) IsDue(now time.Time, interval time.Duration) bool {
+	if n.Status != NotificationPending {
+		return false
+	}
+
+	if n.Attempts == 0 {
+		return true
+	}
+
+	shift := n.Attempts - 1
+	if shift > maxBackoffShift {
+		shift = maxBackoffShift
+	}
+
+	return !now.Before(n.UpdatedAt.Add(interval << shift))
+}
+
+func (n *Notification) IsSucceeded() bool {
+	return n.Status == NotificationSucceeded
+}
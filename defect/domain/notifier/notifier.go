@@ -0,0 +1,14 @@
+package notifier
+
+import "github.com/opensourceways/defect-manager/defect/domain"
+
+// Notifier pushes the bulletin uploaded to OBS to the downstream, such as the security-notice backend
+type Notifier interface {
+	Notify(*domain.Notification) error
+}
+
+// Channel sends the event to the subscribers, such as email and the incoming webhooks of IM,
+// recipients are ignored by the channels which have a fixed receiver.
+type Channel interface {
+	Send(e *domain.Event, recipients []string) error
+}
@@ -1,5 +1,6 @@
 package obs
 
 type OBS interface {
-	Upload(fileName string, data []byte) error
+	// Upload returns the key of the object
+	Upload(fileName string, data []byte) (string, error)
 }
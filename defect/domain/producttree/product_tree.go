@@ -1,12 +1,27 @@
 package producttree
 
 import (
+	"fmt"
+
 	"github.com/opensourceways/defect-manager/defect/domain"
 	"github.com/opensourceways/defect-manager/defect/domain/dp"
 )
 
+// ComponentNotFoundError means the component has no rpm in the version,
+// the bulletin must not be generated with an empty product tree.
+type ComponentNotFoundError struct {
+	Component string
+	Version   string
+}
+
+func (e ComponentNotFoundError) Error() string {
+	return fmt.Sprintf("component %s not found in version %s", e.Component, e.Version)
+}
+
 type ProductTree interface {
-	InitCache()
-	CleanCache()
+	// GetTree matches the component with the source package, the binary rpm name is matched too
 	GetTree(component string, version []dp.SystemVersion) (domain.ProductTree, error)
+
+	// Components returns the source packages in the version
+	Components(version dp.SystemVersion) ([]string, error)
 }
@@ -0,0 +1,23 @@
+package domain
+
+// PublishPolicy Published is the identification of the latest bulletin of each published issue keyed by
+// the key of issue, it is empty if the issue is only known as published by the CVE backend.
+// The published issues are rejected unless Force is set for an intentional re-issue.
+type PublishPolicy struct {
+	Published map[string]string
+	Force     bool
+}
+
+// check returns the detail of exclusion if the issue is rejected
+func (p *PublishPolicy) check(issue *Issue) (string, bool) {
+	id, ok := p.Published[issue.Key()]
+	if !ok || p.Force {
+		return "", true
+	}
+
+	if id == "" {
+		return "it has been published, set force to re-issue", false
+	}
+
+	return "it has been published in " + id + ", set force to re-issue", false
+}
@@ -0,0 +1,8 @@
+package publisher
+
+import "github.com/opensourceways/defect-manager/defect/domain"
+
+// Publisher publishes the domain event to the message queue
+type Publisher interface {
+	Publish(*domain.DomainEvent) error
+}
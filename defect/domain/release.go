@@ -0,0 +1,177 @@
+package domain
+
+import (
+	"strings"
+	"time"
+
+	"github.com/opensourceways/defect-manager/defect/domain/dp"
+)
+
+const (
+	ReleaseStatusReleased = "released"
+	ReleaseStatusPending  = "pending"
+	ReleaseStatusNotFound = "not_found"
+)
+
+// Release is the state of the fixed package of a defect in an affected version,
+// Package is the newest rpm of the component listed by the product tree.
+// Baseline is the evr of the newest rpm when the defect is accepted, it is empty if there was none.
+type Release struct {
+	Version   dp.SystemVersion
+	Package   string
+	Status    string
+	Baseline  string
+	CheckedAt time.Time
+}
+
+func (r *Release) IsReleased() bool {
+	return r.Status == ReleaseStatusReleased
+}
+
+// CheckRelease the newest rpm must be the same as or newer than FixedVersion if it is set. Otherwise
+// it must be newer than the baseline recorded on acceptance, or its version must be newer than
+// ComponentVersion, or be the same one with a bumped release if ComponentVersion has the release.
+// The epoch and dist tag are ignored in the latter because the reported version has none of them.
+// latest is nil if there is no rpm in the version.
+func (d *Defect) CheckRelease(version dp.SystemVersion, latest *dp.NEVRA, now time.Time) Release {
+	return d.checkRelease(version, latest, d.baselineOf(version), now)
+}
+
+// BaselineRelease records latest as the baseline of the version when the defect is accepted,
+// a fix released before it is still detected by ComponentVersion.
+func (d *Defect) BaselineRelease(version dp.SystemVersion, latest *dp.NEVRA, now time.Time) Release {
+	baseline := ""
+	if latest != nil {
+		baseline = latest.EVR()
+	}
+
+	return d.checkRelease(version, latest, baseline, now)
+}
+
+// ReleaseOf returns nil if the release of the version has not been checked
+func (d *Defect) ReleaseOf(version dp.SystemVersion) *Release {
+	for i := range d.Releases {
+		if d.Releases[i].Version == version {
+			return &d.Releases[i]
+		}
+	}
+
+	return nil
+}
+
+func (d *Defect) baselineOf(version dp.SystemVersion) string {
+	if r := d.ReleaseOf(version); r != nil {
+		return r.Baseline
+	}
+
+	return ""
+}
+
+func (d *Defect) checkRelease(version dp.SystemVersion, latest *dp.NEVRA, baseline string, now time.Time) Release {
+	r := Release{
+		Version:   version,
+		Status:    ReleaseStatusNotFound,
+		Baseline:  baseline,
+		CheckedAt: now,
+	}
+
+	if latest == nil {
+		return r
+	}
+
+	r.Package = latest.ID()
+
+	released := true
+	if d.FixedVersion != "" {
+		released = dp.CompareEVR(latest.EVR(), d.FixedVersion) >= 0
+	} else if baseline != "" || d.ComponentVersion != "" {
+		released = (baseline != "" && dp.CompareEVR(latest.EVR(), baseline) > 0) ||
+			(d.ComponentVersion != "" && dp.CompareVR(latest.BaseEVR(), d.ComponentVersion) > 0)
+	}
+
+	if released {
+		r.Status = ReleaseStatusReleased
+	} else {
+		r.Status = ReleaseStatusPending
+	}
+
+	return r
+}
+
+// ReleasedVersions returns the affected versions in which the fixed package has been released
+func (d *Defect) ReleasedVersions() []dp.SystemVersion {
+	var r []dp.SystemVersion
+	for _, v := range d.AffectedVersion {
+		for i := range d.Releases {
+			if d.Releases[i].Version == v && d.Releases[i].IsReleased() {
+				r = append(r, v)
+
+				break
+			}
+		}
+	}
+
+	return r
+}
+
+// Released returns the defects whose affected versions are limited to the maintained ones in which
+// the fixed package has been released, the defect is excluded if there is none.
+// The maintained versions which are not released are excluded if the others are released.
+func (ds Defects) Released(maintained []dp.SystemVersion) (Defects, []Exclusion) {
+	var r Defects
+	var es []Exclusion
+	for i := range ds {
+		released := make(map[dp.SystemVersion]bool)
+		for _, v := range ds[i].ReleasedVersions() {
+			released[v] = true
+		}
+
+		var versions, pending []dp.SystemVersion
+		for _, v := range ds[i].maintainedVersions(maintained) {
+			if released[v] {
+				versions = append(versions, v)
+			} else {
+				pending = append(pending, v)
+			}
+		}
+
+		if len(versions) == 0 {
+			es = append(es, Exclusion{
+				Issue:  ds[i].Issue.Key(),
+				Reason: ExclusionNotReleased,
+				Detail: ds[i].releaseDetail(),
+			})
+
+			continue
+		}
+
+		if len(pending) > 0 {
+			es = append(es, Exclusion{
+				Issue:    ds[i].Issue.Key(),
+				Reason:   ExclusionNotReleased,
+				Detail:   ds[i].releaseDetail(),
+				Versions: pending,
+			})
+		}
+
+		d := ds[i]
+		d.AffectedVersion = versions
+		r = append(r, d)
+	}
+
+	return r, es
+}
+
+// releaseDetail is like "openEuler-22.03-LTS: pending, openEuler-22.03-LTS-SP1: not_found"
+func (d *Defect) releaseDetail() string {
+	if len(d.Releases) == 0 {
+		return "the release is not checked"
+	}
+
+	items := make([]string, len(d.Releases))
+	for i := range d.Releases {
+		items[i] = d.Releases[i].Version.String() + ": " + d.Releases[i].Status
+	}
+
+	return strings.Join(items, ", ")
+}
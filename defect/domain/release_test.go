@@ -0,0 +1,125 @@
+package domain
+
+import (
+	"testing"
+	"time"
+
+	"github.com/opensourceways/defect-manager/defect/domain/dp"
+)
+
+func TestCheckRelease(t *testing.T) {
+	now := time.Now()
+	version, _ := dp.NewSystemVersion("openEuler-22.03-LTS")
+
+	cases := []struct {
+		componentVersion string
+		fixedVersion     string
+		rpm              string
+		want             string
+	}{
+		{"0.22", "", "zbar-0.23-1.oe2203.src.rpm", ReleaseStatusReleased},
+		{"0.22", "", "zbar-0.22-4.oe2203.src.rpm", ReleaseStatusPending},
+		{"0.22-4", "", "zbar-0.22-5.oe2203.src.rpm", ReleaseStatusReleased},
+		{"0.22-4", "", "zbar-0.22-4.oe2203.src.rpm", ReleaseStatusPending},
+		{"0.22", "", "zbar-1:0.22-4.oe2203.src.rpm", ReleaseStatusPending},
+		{"0.22-4", "", "zbar-1:0.22-5.oe2203.src.rpm", ReleaseStatusReleased},
+		{"0.22", "0.22-5", "zbar-0.22-5.oe2203.src.rpm", ReleaseStatusReleased},
+		{"0.22", "0.22-5", "zbar-0.22-4.oe2203.src.rpm", ReleaseStatusPending},
+		{"0.22", "", "", ReleaseStatusNotFound},
+	}
+
+	for _, c := range cases {
+		d := Defect{ComponentVersion: c.componentVersion, FixedVersion: c.fixedVersion}
+
+		var latest *dp.NEVRA
+		if c.rpm != "" {
+			n, _ := dp.ParseNEVRA(c.rpm)
+			latest = &n
+		}
+
+		if got := d.CheckRelease(version, latest, now); got.Status != c.want {
+			t.Errorf("check %s of %s/%s, got %s, want %s",
+				c.rpm, c.componentVersion, c.fixedVersion, got.Status, c.want,
+			)
+		}
+	}
+}
+
+func TestCheckReleaseWithBaseline(t *testing.T) {
+	now := time.Now()
+	version, _ := dp.NewSystemVersion("openEuler-22.03-LTS")
+
+	accepted, _ := dp.ParseNEVRA("zbar-0.22-4.oe2203.src.rpm")
+	d := Defect{ComponentVersion: "0.22"}
+
+	r := d.BaselineRelease(version, &accepted, now)
+	if r.Baseline != "0.22-4.oe2203" || r.Status != ReleaseStatusPending {
+		t.Fatalf("unexpected baseline release: %+v", r)
+	}
+
+	d.Releases = []Release{r}
+
+	// the fix bumps the release only
+	for rpm, want := range map[string]string{
+		"zbar-0.22-4.oe2203.src.rpm": ReleaseStatusPending,
+		"zbar-0.22-5.oe2203.src.rpm": ReleaseStatusReleased,
+	} {
+		latest, _ := dp.ParseNEVRA(rpm)
+		if got := d.CheckRelease(version, &latest, now); got.Status != want || got.Baseline != r.Baseline {
+			t.Errorf("check %s, got %+v, want %s", rpm, got, want)
+		}
+	}
+
+	// the fix was released before the acceptance
+	released, _ := dp.ParseNEVRA("zbar-0.23-1.oe2203.src.rpm")
+	if got := d.BaselineRelease(version, &released, now); got.Status != ReleaseStatusReleased {
+		t.Errorf("got %s, want released", got.Status)
+	}
+}
+
+func TestReleased(t *testing.T) {
+	v1, _ := dp.NewSystemVersion("openEuler-22.03-LTS")
+	v2, _ := dp.NewSystemVersion("openEuler-22.03-LTS-SP1")
+	v3, _ := dp.NewSystemVersion("openEuler-20.03-LTS-SP1")
+
+	ds := Defects{
+		{
+			AffectedVersion: []dp.SystemVersion{v1, v2},
+			Releases: []Release{
+				{Version: v1, Status: ReleaseStatusReleased},
+				{Version: v2, Status: ReleaseStatusPending},
+			},
+		},
+		{
+			AffectedVersion: []dp.SystemVersion{v2},
+			Releases:        []Release{{Version: v2, Status: ReleaseStatusNotFound}},
+		},
+		{
+			// it is released only in a version which is not maintained
+			AffectedVersion: []dp.SystemVersion{v1, v3},
+			Releases: []Release{
+				{Version: v1, Status: ReleaseStatusPending},
+				{Version: v3, Status: ReleaseStatusReleased},
+			},
+		},
+	}
+
+	r, es := ds.Released([]dp.SystemVersion{v1, v2})
+	if len(r) != 1 || len(r[0].AffectedVersion) != 1 || r[0].AffectedVersion[0] != v1 {
+		t.Errorf("unexpected released defects: %v", r)
+	}
+
+	if len(es) != 3 || es[0].Reason != ExclusionNotReleased || es[1].Reason != ExclusionNotReleased ||
+		es[2].Reason != ExclusionNotReleased {
+		t.Errorf("unexpected exclusions: %v", es)
+	}
+
+	// the first defect is published in v1 and excluded only in v2
+	if len(es[0].Versions) != 1 || es[0].Versions[0] != v2 || len(es[1].Versions) != 0 {
+		t.Errorf("unexpected excluded versions: %v", es)
+	}
+
+	if len(ds[0].AffectedVersion) != 2 {
+		t.Errorf("the defects must not be changed")
+	}
+}
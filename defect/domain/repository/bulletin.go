@@ -0,0 +1,17 @@
+package repository
+
+import (
+	"github.com/opensourceways/defect-manager/defect/domain"
+)
+
+type BulletinRepository interface {
+	// SaveBulletin records the issues in the bulletin, it is idempotent,
+	// the events are saved to the outbox in the same transaction
+	SaveBulletin(*domain.SecurityBulletin, ...domain.DomainEvent) error
+	// FindPublished returns the identification of the latest bulletin of each published issue,
+	// the map is keyed by the key of issue
+	FindPublished([]domain.Issue) (map[string]string, error)
+	// LockGeneration blocks until no other replica is generating the bulletins,
+	// the returned func releases the lock.
+	LockGeneration() (unlock func(), err error)
+}
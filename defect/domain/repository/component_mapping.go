@@ -0,0 +1,23 @@
+package repository
+
+import (
+	"errors"
+
+	"github.com/opensourceways/defect-manager/defect/domain"
+	"github.com/opensourceways/defect-manager/defect/domain/dp"
+)
+
+var ErrMappingNotFound = errors.New("component mapping not found")
+
+type ComponentMappingRepository interface {
+	// FindSourcePackage returns empty string if the component is not mapped in the version
+	FindSourcePackage(component string, version dp.SystemVersion) (string, error)
+	// FindMappings returns the mappings in the table, the ones in config are not included
+	FindMappings() ([]domain.ComponentMapping, error)
+	// SaveMapping inserts the mapping or updates the source package if it exists,
+	// the component is case-insensitive
+	SaveMapping(*domain.ComponentMapping) error
+	// DeleteMapping version is nil for the mapping of all the versions,
+	// it returns ErrMappingNotFound if the mapping does not exist
+	DeleteMapping(component string, version dp.SystemVersion) error
+}
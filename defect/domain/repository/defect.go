@@ -1,6 +1,7 @@
 package repository
 
 import (
+	"errors"
 	"time"
 
 	"github.com/opensourceways/defect-manager/defect/domain/dp"
@@ -8,16 +9,63 @@ import (
 	"github.com/opensourceways/defect-manager/defect/domain"
 )
 
+const (
+	SortByCreatedAt     = "created_at"
+	SortByUpdatedAt     = "updated_at"
+	SortByNumber        = "number"
+	SortByComponent     = "component"
+	SortBySeverityLevel = "severity_level"
+)
+
+var ErrDefectNotFound = errors.New("defect not found")
+
 type OptToFindDefects struct {
-	BeginTime time.Time
-	Org       string
-	Number    []string
-	Status    dp.IssueStatus
+	BeginTime       time.Time
+	EndTime         time.Time
+	Org             string
+	Repo            string
+	Issues          []domain.Issue
+	Status          dp.IssueStatus
+	Component       string
+	SeverityLevel   dp.SeverityLevel
+	SystemVersion   dp.SystemVersion
+	AffectedVersion dp.SystemVersion
+	// Keyword is matched against the description
+	Keyword string
+	// Reference is the id of cve or cwe, or the url of upstream commit or bug, it is case-insensitive
+	Reference string
+	// Numbers matches the issues of any org and repo
+	Numbers []string
+
+	PageNum      int
+	CountPerPage int
+	SortBy       string
+	Ascend       bool
+	// Cursor is the NextCursor of the previous page, the defects after it in the order of
+	// (created_at, id) are returned and PageNum is ignored. It is valid only when sorting by created_at.
+	Cursor string
+}
+
+// DefectsPage NextCursor is empty if it is the last page or the defects are not sorted by created_at
+type DefectsPage struct {
+	Defects    domain.Defects
+	NextCursor string
 }
 
 type DefectRepository interface {
 	HasDefect(*domain.Issue) (bool, error)
-	AddDefect(*domain.Defect) error
-	SaveDefect(*domain.Defect) error
+	// SaveDefect inserts the defect or updates it by the approval if the issue exists, it returns true
+	// if it is inserted. The fixed version and cvss set by the admins are kept unless the defect has them,
+	// and the references are merged. The events are saved in the same transaction only when it is inserted.
+	SaveDefect(*domain.Defect, ...domain.DomainEvent) (bool, error)
+	// UpdateDefect overwrites the defect with the changes of the admins, the references are replaced
+	UpdateDefect(*domain.Defect) error
+	// SaveReleases updates the release states only, the updated time is not changed
+	SaveReleases(*domain.Issue, []domain.Release) error
+	FindDefect(*domain.Issue) (domain.Defect, error)
 	FindDefects(OptToFindDefects) (domain.Defects, error)
+	// FindDefectsPage pages by the cursor if it is set, otherwise by PageNum
+	FindDefectsPage(OptToFindDefects) (DefectsPage, error)
+	CountDefects(OptToFindDefects) (int, error)
+	DeleteDefect(*domain.Issue) error
 }
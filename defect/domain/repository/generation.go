@@ -0,0 +1,15 @@
+package repository
+
+import (
+	"errors"
+
+	"github.com/opensourceways/defect-manager/defect/domain"
+)
+
+var ErrGenerationNotFound = errors.New("generation not found")
+
+type GenerationRepository interface {
+	// SaveGeneration inserts the generation or updates it if the id exists
+	SaveGeneration(*domain.Generation) error
+	FindGeneration(id string) (domain.Generation, error)
+}
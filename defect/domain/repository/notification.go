@@ -0,0 +1,19 @@
+package repository
+
+import (
+	"errors"
+
+	"github.com/opensourceways/defect-manager/defect/domain"
+)
+
+var ErrNotificationNotFound = errors.New("notification not found")
+
+type NotificationRepository interface {
+	// SaveNotification inserts the notification or updates it if the identification exists
+	SaveNotification(*domain.Notification) error
+	FindNotification(identification string) (domain.Notification, error)
+	// FindNotifications returns all of them if status is empty, the latest is the first
+	FindNotifications(status string) ([]domain.Notification, error)
+	// LockSender returns false if the pending notifications are being sent by another replica
+	LockSender() (unlock func(), locked bool, err error)
+}
@@ -0,0 +1,24 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/opensourceways/defect-manager/defect/domain"
+)
+
+// OutboxRepository keeps the domain events until they are published
+type OutboxRepository interface {
+	SaveEvents(...domain.DomainEvent) error
+	// FindPendingEvents returns at most limit events which are neither published nor parked
+	// in the order they are saved
+	FindPendingEvents(limit int) ([]domain.DomainEvent, error)
+	MarkPublished(id string, at time.Time) error
+	// RecordFailure counts the failed attempt of publishing
+	RecordFailure(id string, reason string) error
+	// ParkEvent counts the failed attempt and stops publishing the event, it is kept for inspection
+	ParkEvent(id string, reason string, at time.Time) error
+	// LockRelay returns false if the events are being published by another replica
+	LockRelay() (unlock func(), locked bool, err error)
+	// DeletePublished removes the events published before the time
+	DeletePublished(before time.Time) error
+}
@@ -0,0 +1,15 @@
+package repository
+
+import "errors"
+
+var ErrSettingNotFound = errors.New("setting not found")
+
+// SettingRepository keeps the settings shared by the replicas, such as the switch of scheduler
+type SettingRepository interface {
+	// FindSetting returns ErrSettingNotFound if it has not been saved
+	FindSetting(name string) (string, error)
+	// SaveSetting inserts the setting or updates it if the name exists
+	SaveSetting(name, value string) error
+	// LockJob returns false if the scheduled job is being run by another replica
+	LockJob(name string) (unlock func(), locked bool, err error)
+}
@@ -0,0 +1,21 @@
+package repository
+
+import (
+	"errors"
+
+	"github.com/opensourceways/defect-manager/defect/domain"
+	"github.com/opensourceways/defect-manager/defect/domain/dp"
+)
+
+var (
+	ErrVersionNotFound = errors.New("version not found")
+	ErrVersionExists   = errors.New("version exists")
+)
+
+type VersionRepository interface {
+	AddVersion(*domain.MaintainedVersion) error
+	SaveVersion(*domain.MaintainedVersion) error
+	FindVersion(dp.SystemVersion) (domain.MaintainedVersion, error)
+	// FindVersions returns all the versions including the retired ones, ordered by GA date
+	FindVersions() (domain.MaintainedVersions, error)
+}
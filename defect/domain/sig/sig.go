@@ -0,0 +1,7 @@
+package sig
+
+type SIG interface {
+	// SIGOfRepo returns the sig maintaining the repo, such as src-openeuler/zbar,
+	// it returns empty if the repo is not maintained by any sig.
+	SIGOfRepo(repo string) (string, error)
+}
@@ -0,0 +1,39 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/opensourceways/defect-manager/defect/domain/dp"
+)
+
+// MaintainedVersion is a system version with its lifecycle,
+// it is not maintained any more after it is retired or reaches EOL.
+type MaintainedVersion struct {
+	Version   dp.SystemVersion
+	Branch    string
+	LTS       bool
+	SP        bool
+	GADate    time.Time
+	EOLDate   time.Time
+	Retired   bool
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+func (v *MaintainedVersion) IsMaintained(now time.Time) bool {
+	return !v.Retired && (v.EOLDate.IsZero() || now.Before(v.EOLDate))
+}
+
+type MaintainedVersions []MaintainedVersion
+
+// Maintained returns the versions being maintained at the time
+func (vs MaintainedVersions) Maintained(now time.Time) []dp.SystemVersion {
+	var r []dp.SystemVersion
+	for i := range vs {
+		if vs[i].IsMaintained(now) {
+			r = append(r, vs[i].Version)
+		}
+	}
+
+	return r
+}
@@ -0,0 +1,30 @@
+package domain
+
+import (
+	"testing"
+	"time"
+
+	"github.com/opensourceways/defect-manager/defect/domain/dp"
+)
+
+func TestMaintained(t *testing.T) {
+	now := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+
+	newVersion := func(s string) dp.SystemVersion {
+		v, _ := dp.NewSystemVersion(s)
+
+		return v
+	}
+
+	vs := MaintainedVersions{
+		{Version: newVersion("openEuler-20.03-LTS-SP1"), EOLDate: now.AddDate(0, -1, 0)},
+		{Version: newVersion("openEuler-22.03-LTS"), EOLDate: now.AddDate(1, 0, 0)},
+		{Version: newVersion("openEuler-22.03-LTS-SP1")},
+		{Version: newVersion("openEuler-23.03"), Retired: true},
+	}
+
+	got := vs.Maintained(now)
+	if len(got) != 2 || got[0].String() != "openEuler-22.03-LTS" || got[1].String() != "openEuler-22.03-LTS-SP1" {
+		t.Errorf("unexpected maintained versions: %v", got)
+	}
+}
@@ -0,0 +1,266 @@
+// Package backendtest provides an in-process stand-in of the cve-security-notice-server,
+// it is imported by the tests only.
+package backendtest
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const pathPrefix = "/cve-security-notice-server/securitynotice/"
+
+var regOfBulletinID = regexp.MustCompile(`openEuler-BA-(\d{4})-(\d{4,5})`)
+
+// bulletin is the bulletin in the wire format of the real server
+type bulletin struct {
+	NoticeType      string   `json:"notice_type"`
+	ID              string   `json:"id"`
+	Component       string   `json:"component"`
+	AffectedVersion []string `json:"affected_version"`
+	IssueNumber     []string `json:"issue_number"`
+	File            string   `json:"file"`
+	PublishedAt     string   `json:"published_at"`
+}
+
+type bugsPage struct {
+	Total int      `json:"total"`
+	List  []string `json:"list"`
+}
+
+type response struct {
+	Code   int             `json:"code"`
+	Msg    string          `json:"msg"`
+	Result json.RawMessage `json:"result"`
+}
+
+// Server keeps the bulletins in memory, it is used to run the whole flow offline
+type Server struct {
+	*httptest.Server
+
+	token      string
+	authHeader string
+
+	lock      sync.Mutex
+	bulletins []bulletin
+	failures  int
+}
+
+// NewServer starts the server, the requests must carry the token in the header if it is not empty.
+// The caller should Close it.
+func NewServer(authHeader, token string) *Server {
+	s := &Server{
+		token:      token,
+		authHeader: authHeader,
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc(pathPrefix+"getMaxNoticeId", s.getMaxNoticeID)
+	mux.HandleFunc(pathPrefix+"getPublishedBugs", s.getPublishedBugs)
+	mux.HandleFunc(pathPrefix+"listPublishedBugs", s.listPublishedBugs)
+	mux.HandleFunc(pathPrefix+"publishBulletin", s.publishBulletin)
+	mux.HandleFunc(pathPrefix+"getBulletin", s.getBulletin)
+
+	s.Server = httptest.NewServer(s.filter(mux))
+
+	return s
+}
+
+// FailNext makes the next n requests fail with 503
+func (s *Server) FailNext(n int) {
+	s.lock.Lock()
+	s.failures = n
+	s.lock.Unlock()
+}
+
+// Identifications returns the identifications of the published bulletins in order
+func (s *Server) Identifications() []string {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	r := make([]string, len(s.bulletins))
+	for i := range s.bulletins {
+		r[i] = s.bulletins[i].ID
+	}
+
+	return r
+}
+
+func (s *Server) filter(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if s.token != "" && r.Header.Get(s.authHeader) != s.token {
+			w.WriteHeader(http.StatusUnauthorized)
+
+			return
+		}
+
+		s.lock.Lock()
+		fail := s.failures > 0
+		if fail {
+			s.failures--
+		}
+		s.lock.Unlock()
+
+		if fail {
+			w.WriteHeader(http.StatusServiceUnavailable)
+
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+func (s *Server) getMaxNoticeID(w http.ResponseWriter, r *http.Request) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	maxID, year := "", 0
+	seq := 0
+	for i := range s.bulletins {
+		m := regOfBulletinID.FindStringSubmatch(s.bulletins[i].ID)
+		if m == nil {
+			continue
+		}
+
+		y, _ := strconv.Atoi(m[1])
+		n, _ := strconv.Atoi(m[2])
+		if y > year || (y == year && n > seq) {
+			maxID, year, seq = s.bulletins[i].ID, y, n
+		}
+	}
+
+	writeResult(w, maxID)
+}
+
+func (s *Server) getPublishedBugs(w http.ResponseWriter, r *http.Request) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	var bugs []string
+	for i := range s.bulletins {
+		bugs = append(bugs, s.bulletins[i].IssueNumber...)
+	}
+
+	sort.Strings(bugs)
+
+	writeResult(w, bugs)
+}
+
+// listPublishedBugs pages the issues of the bulletins published at or after since
+func (s *Server) listPublishedBugs(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+
+	since, err := time.Parse(time.RFC3339, q.Get("since"))
+	if err != nil {
+		http.Error(w, "invalid since", http.StatusBadRequest)
+
+		return
+	}
+
+	page, err1 := strconv.Atoi(q.Get("page_num"))
+	size, err2 := strconv.Atoi(q.Get("page_size"))
+	if err1 != nil || err2 != nil || page < 1 || size < 1 {
+		http.Error(w, "invalid page", http.StatusBadRequest)
+
+		return
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	var bugs []string
+	for i := range s.bulletins {
+		// it is validated when published
+		t, _ := time.Parse(time.RFC3339, s.bulletins[i].PublishedAt)
+		if !t.Before(since) {
+			bugs = append(bugs, s.bulletins[i].IssueNumber...)
+		}
+	}
+
+	sort.Strings(bugs)
+
+	start := (page - 1) * size
+	if start > len(bugs) {
+		start = len(bugs)
+	}
+
+	end := start + size
+	if end > len(bugs) {
+		end = len(bugs)
+	}
+
+	writeResult(w, bugsPage{Total: len(bugs), List: bugs[start:end]})
+}
+
+func (s *Server) getBulletin(w http.ResponseWriter, r *http.Request) {
+	id := r.URL.Query().Get("id")
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	for i := range s.bulletins {
+		if s.bulletins[i].ID == id {
+			writeResult(w, s.bulletins[i])
+
+			return
+		}
+	}
+
+	http.Error(w, "bulletin not found", http.StatusNotFound)
+}
+
+func (s *Server) publishBulletin(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+
+		return
+	}
+
+	var data bulletin
+	if err := json.NewDecoder(r.Body).Decode(&data); err != nil || data.ID == "" {
+		http.Error(w, "invalid bulletin", http.StatusBadRequest)
+
+		return
+	}
+
+	if _, err := time.Parse(time.RFC3339, data.PublishedAt); err != nil {
+		http.Error(w, "invalid published_at", http.StatusBadRequest)
+
+		return
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	// it is idempotent like the real one
+	for i := range s.bulletins {
+		if s.bulletins[i].ID == data.ID {
+			writeResult(w, nil)
+
+			return
+		}
+	}
+
+	s.bulletins = append(s.bulletins, data)
+
+	writeResult(w, nil)
+}
+
+func writeResult(w http.ResponseWriter, result interface{}) {
+	data, err := json.Marshal(result)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+
+	_ = json.NewEncoder(w).Encode(response{Result: data})
+}
@@ -0,0 +1,163 @@
+package backendimpl
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	pathPrefix = "/cve-security-notice-server/securitynotice/"
+
+	// maxBodySize limits the body of response read in memory
+	maxBodySize = 32 << 20
+)
+
+var ErrUnauthorized = errors.New("unauthorized by the cve backend")
+
+// StatusError is returned when the backend responds with an unexpected http status
+type StatusError struct {
+	StatusCode int
+	Body       string
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("unexpected status %d of the cve backend: %s", e.StatusCode, e.Body)
+}
+
+func (e *StatusError) retryable() bool {
+	return e.StatusCode >= http.StatusInternalServerError || e.StatusCode == http.StatusTooManyRequests
+}
+
+// APIError is returned when the backend responds with a non-zero code
+type APIError struct {
+	Code int
+	Msg  string
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("the cve backend responds code %d: %s", e.Code, e.Msg)
+}
+
+type response struct {
+	Code   int             `json:"code"`
+	Msg    string          `json:"msg"`
+	Result json.RawMessage `json:"result"`
+}
+
+type client struct {
+	cfg *Config
+	cli *http.Client
+}
+
+func newClient(cfg *Config) client {
+	return client{
+		cfg: cfg,
+		cli: &http.Client{Timeout: cfg.timeout()},
+	}
+}
+
+func (c client) get(path string, query url.Values, result interface{}) error {
+	return c.do(http.MethodGet, path, query, nil, result)
+}
+
+func (c client) post(path string, body, result interface{}) error {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+
+	return c.do(http.MethodPost, path, nil, data, result)
+}
+
+// do retries on the network errors and the 5xx or 429 responses
+func (c client) do(method, path string, query url.Values, body []byte, result interface{}) error {
+	for i := 0; ; i++ {
+		err := c.doOnce(method, path, query, body, result)
+		if err == nil || !isRetryable(err) || i >= c.cfg.maxRetries() {
+			return err
+		}
+
+		logrus.Warnf("%s %s of the cve backend failed %d times, err:%s", method, path, i+1, err.Error())
+
+		time.Sleep(c.cfg.retryInterval() * time.Duration(i+1))
+	}
+}
+
+func (c client) doOnce(method, path string, query url.Values, body []byte, result interface{}) error {
+	u := strings.TrimSuffix(c.cfg.Endpoint, "/") + pathPrefix + path
+	if len(query) > 0 {
+		u += "?" + query.Encode()
+	}
+
+	var reader io.Reader
+	if body != nil {
+		reader = bytes.NewReader(body)
+	}
+
+	req, err := http.NewRequest(method, u, reader)
+	if err != nil {
+		return err
+	}
+
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	if c.cfg.Token != "" {
+		req.Header.Set(c.cfg.AuthHeader, c.cfg.Token)
+	}
+
+	resp, err := c.cli.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxBodySize))
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden:
+		return fmt.Errorf("%w, status %d", ErrUnauthorized, resp.StatusCode)
+
+	case resp.StatusCode < 200 || resp.StatusCode > 299:
+		return &StatusError{StatusCode: resp.StatusCode, Body: string(data)}
+	}
+
+	var res response
+	if err = json.Unmarshal(data, &res); err != nil {
+		return err
+	}
+
+	if res.Code != 0 {
+		return &APIError{Code: res.Code, Msg: res.Msg}
+	}
+
+	if result == nil || len(res.Result) == 0 {
+		return nil
+	}
+
+	return json.Unmarshal(res.Result, result)
+}
+
+func isRetryable(err error) bool {
+	var se *StatusError
+	if errors.As(err, &se) {
+		return se.retryable()
+	}
+
+	var ue *url.Error
+
+	return errors.As(err, &ue)
+}
@@ -1,5 +1,57 @@
 package backendimpl
 
+import "time"
+
+// Config Token is sent in the header of AuthHeader if it is set.
+// Timeout is the seconds of a request, RetryInterval is the milliseconds between retries.
+// MaxRetries is 3 if it is not set, 0 disables the retries.
+// PageSize is the count of items fetched a time when listing.
 type Config struct {
-	Endpoint string `json:"endpoint" required:"true"`
+	Endpoint      string `json:"endpoint"       required:"true"`
+	Token         string `json:"token"`
+	AuthHeader    string `json:"auth_header"`
+	Timeout       int    `json:"timeout"`
+	MaxRetries    *int   `json:"max_retries"`
+	RetryInterval int    `json:"retry_interval"`
+	PageSize      int    `json:"page_size"`
+}
+
+func (c *Config) SetDefault() {
+	if c.AuthHeader == "" {
+		c.AuthHeader = "Authorization"
+	}
+
+	if c.Timeout <= 0 {
+		c.Timeout = 10
+	}
+
+	if c.MaxRetries == nil {
+		v := 3
+		c.MaxRetries = &v
+	}
+
+	if c.RetryInterval <= 0 {
+		c.RetryInterval = 500
+	}
+
+	if c.PageSize <= 0 {
+		c.PageSize = 100
+	}
+}
+
+func (c *Config) timeout() time.Duration {
+	return time.Duration(c.Timeout) * time.Second
+}
+
+// maxRetries a negative MaxRetries is taken as 0
+func (c *Config) maxRetries() int {
+	if c.MaxRetries == nil || *c.MaxRetries < 0 {
+		return 0
+	}
+
+	return *c.MaxRetries
+}
+
+func (c *Config) retryInterval() time.Duration {
+	return time.Duration(c.RetryInterval) * time.Millisecond
 }
@@ -1,26 +1,32 @@
 package backendimpl
 
 import (
-	"encoding/json"
 	"errors"
-	"fmt"
 	"net/http"
+	"net/url"
 	"regexp"
 	"strconv"
+	"time"
 
-	"github.com/opensourceways/server-common-lib/utils"
-
+	"github.com/opensourceways/defect-manager/defect/domain/backend"
 	localutils "github.com/opensourceways/defect-manager/utils"
 )
 
+const noticeTypeBug = "bug"
+
 var regOfBulletinID = regexp.MustCompile(`openEuler-BA-(\d{4})-(\d{4,5})`)
 
 var instance *backendImpl
 
-func Init(cfg *Config) {
-	instance = &backendImpl{
-		cli: utils.NewHttpClient(3),
-		cfg: cfg,
+func Init(cfg *Config, clock localutils.Clock) {
+	instance = New(cfg, clock)
+}
+
+// New creates a backend without replacing the one returned by Instance
+func New(cfg *Config, clock localutils.Clock) *backendImpl {
+	return &backendImpl{
+		cli:   newClient(cfg),
+		clock: clock,
 	}
 }
 
@@ -28,54 +34,70 @@ func Instance() *backendImpl {
 	return instance
 }
 
+// backendImpl clock decides the year in which the bulletin id is reset
 type backendImpl struct {
-	cli utils.HttpClient
-	cfg *Config
+	cli   client
+	clock localutils.Clock
 }
 
-type maxIdResult struct {
-	Code   int    `json:"code"`
-	Result string `json:"result"`
-	Msg    string `json:"msg"`
+// bulletinData is the bulletin exchanged with the backend, IssueNumber is the keys of the issues,
+// PublishedAt is in RFC3339
+type bulletinData struct {
+	NoticeType      string   `json:"notice_type"`
+	ID              string   `json:"id"`
+	Component       string   `json:"component"`
+	AffectedVersion []string `json:"affected_version"`
+	IssueNumber     []string `json:"issue_number"`
+	File            string   `json:"file"`
+	PublishedAt     string   `json:"published_at"`
 }
 
-type publishedDefectResult struct {
-	Code   int      `json:"code"`
-	Result []string `json:"result"`
-	Msg    string   `json:"msg"`
-}
-
-func (impl backendImpl) MaxBulletinID() (maxId int, err error) {
-	url := fmt.Sprintf("%s/cve-security-notice-server/securitynotice/getMaxNoticeId?notice_type=bug",
-		impl.cfg.Endpoint,
-	)
-	request, err := http.NewRequest(http.MethodGet, url, nil)
-	if err != nil {
-		return
+func toBulletinData(b *backend.Bulletin) bulletinData {
+	return bulletinData{
+		NoticeType:      noticeTypeBug,
+		ID:              b.Identification,
+		Component:       b.Component,
+		AffectedVersion: b.AffectedVersion,
+		IssueNumber:     b.Issues,
+		File:            b.File,
+		PublishedAt:     b.PublishedAt.Format(time.RFC3339),
 	}
+}
 
-	r, _, err := impl.cli.Download(request)
+func (d *bulletinData) toBulletin() (backend.Bulletin, error) {
+	t, err := time.Parse(time.RFC3339, d.PublishedAt)
 	if err != nil {
-		return
+		return backend.Bulletin{}, err
 	}
 
-	var res maxIdResult
-	if err = json.Unmarshal(r, &res); err != nil {
-		return
-	}
+	return backend.Bulletin{
+		Identification:  d.ID,
+		Component:       d.Component,
+		AffectedVersion: d.AffectedVersion,
+		Issues:          d.IssueNumber,
+		File:            d.File,
+		PublishedAt:     t,
+	}, nil
+}
 
-	if res.Code != 0 {
-		err = errors.New(res.Msg)
+// bugsPage Total is the count of all the issues matched
+type bugsPage struct {
+	Total int      `json:"total"`
+	List  []string `json:"list"`
+}
 
+func (impl backendImpl) MaxBulletinID() (maxId int, err error) {
+	var result string
+	if err = impl.cli.get("getMaxNoticeId", url.Values{"notice_type": {noticeTypeBug}}, &result); err != nil {
 		return
 	}
 
 	// init id
-	if res.Result == "" {
+	if result == "" {
 		return 1000, nil
 	}
 
-	match := regOfBulletinID.FindAllStringSubmatch(res.Result, -1)
+	match := regOfBulletinID.FindAllStringSubmatch(result, -1)
 	if len(match) == 0 {
 		err = errors.New("invalid bulletin id")
 
@@ -83,7 +105,7 @@ func (impl backendImpl) MaxBulletinID() (maxId int, err error) {
 	}
 
 	// reset id to 1000 at new year
-	if match[0][1] != strconv.Itoa(localutils.Year()) {
+	if match[0][1] != strconv.Itoa(impl.clock.Now().Year()) {
 		return 1000, nil
 	}
 
@@ -91,29 +113,55 @@ func (impl backendImpl) MaxBulletinID() (maxId int, err error) {
 }
 
 func (impl backendImpl) PublishedDefects() (pub []string, err error) {
-	url := fmt.Sprintf("%s/cve-security-notice-server/securitynotice/getPublishedBugs",
-		impl.cfg.Endpoint,
-	)
-	request, err := http.NewRequest(http.MethodGet, url, nil)
-	if err != nil {
-		return
+	err = impl.cli.get("getPublishedBugs", nil, &pub)
+
+	return
+}
+
+// PublishBulletin the backend is expected to ignore a bulletin it has, so it is safe to retry
+func (impl backendImpl) PublishBulletin(b *backend.Bulletin) error {
+	return impl.cli.post("publishBulletin", toBulletinData(b), nil)
+}
+
+// PublishedDefectsSince pages through the listing until all the matched issues are fetched
+func (impl backendImpl) PublishedDefectsSince(t time.Time) ([]string, error) {
+	var r []string
+	for page := 1; ; page++ {
+		query := url.Values{
+			"since":     {t.Format(time.RFC3339)},
+			"page_num":  {strconv.Itoa(page)},
+			"page_size": {strconv.Itoa(impl.cli.cfg.PageSize)},
+		}
+
+		var res bugsPage
+		if err := impl.cli.get("listPublishedBugs", query, &res); err != nil {
+			return nil, err
+		}
+
+		r = append(r, res.List...)
+
+		if len(res.List) == 0 || len(r) >= res.Total {
+			return r, nil
+		}
 	}
+}
+
+func (impl backendImpl) FindBulletin(identification string) (backend.Bulletin, error) {
+	var data *bulletinData
 
-	r, _, err := impl.cli.Download(request)
+	err := impl.cli.get("getBulletin", url.Values{"id": {identification}}, &data)
 	if err != nil {
-		return
-	}
+		var se *StatusError
+		if errors.As(err, &se) && se.StatusCode == http.StatusNotFound {
+			err = backend.ErrBulletinNotFound
+		}
 
-	var res publishedDefectResult
-	if err = json.Unmarshal(r, &res); err != nil {
-		return
+		return backend.Bulletin{}, err
 	}
 
-	if res.Code != 0 {
-		err = errors.New(res.Msg)
-
-		return
+	if data == nil {
+		return backend.Bulletin{}, backend.ErrBulletinNotFound
 	}
 
-	return res.Result, nil
+	return data.toBulletin()
 }
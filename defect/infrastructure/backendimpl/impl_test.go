@@ -0,0 +1,111 @@
+package backendimpl
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/opensourceways/defect-manager/defect/domain/backend"
+	"github.com/opensourceways/defect-manager/defect/infrastructure/backendimpl/backendtest"
+	"github.com/opensourceways/defect-manager/utils"
+)
+
+func newTestBackend(endpoint, token string, maxRetries int) *backendImpl {
+	cfg := &Config{Endpoint: endpoint, Token: token, RetryInterval: 1, MaxRetries: &maxRetries, PageSize: 2}
+	cfg.SetDefault()
+
+	return New(cfg, utils.SystemClock{})
+}
+
+type fixedClock struct {
+	now time.Time
+}
+
+func (c fixedClock) Now() time.Time {
+	return c.now
+}
+
+func TestBackend(t *testing.T) {
+	s := backendtest.NewServer("Authorization", "secret")
+	defer s.Close()
+
+	impl := newTestBackend(s.URL, "secret", 3)
+
+	if id, err := impl.MaxBulletinID(); err != nil || id != 1000 {
+		t.Fatalf("max bulletin id of empty backend, got %d, %v", id, err)
+	}
+
+	now := time.Now().Truncate(time.Second)
+	since := now.AddDate(0, 0, -1)
+	for i, date := range []time.Time{since.AddDate(0, 0, -1), since, now} {
+		b := backend.Bulletin{
+			Identification: fmt.Sprintf("cvrf-openEuler-BA-%d-%d", now.Year(), 1001+i),
+			Component:      "zbar",
+			Issues:         []string{fmt.Sprintf("src-openeuler/zbar/I%d", 2*i), fmt.Sprintf("src-openeuler/zbar/I%d", 2*i+1)},
+			PublishedAt:    date,
+		}
+
+		if err := impl.PublishBulletin(&b); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if id, err := impl.MaxBulletinID(); err != nil || id != 1003 {
+		t.Errorf("max bulletin id, got %d, %v", id, err)
+	}
+
+	if v, err := impl.PublishedDefects(); err != nil || len(v) != 6 {
+		t.Errorf("published defects, got %v, %v", v, err)
+	}
+
+	// the 4 issues are fetched in 2 pages
+	if v, err := impl.PublishedDefectsSince(since); err != nil || len(v) != 4 || v[0] != "src-openeuler/zbar/I2" {
+		t.Errorf("published defects since %s, got %v, %v", since, v, err)
+	}
+
+	id := fmt.Sprintf("cvrf-openEuler-BA-%d-1002", now.Year())
+	if b, err := impl.FindBulletin(id); err != nil || len(b.Issues) != 2 || !b.PublishedAt.Equal(since) {
+		t.Errorf("find bulletin, got %v, %v", b, err)
+	}
+
+	if _, err := impl.FindBulletin("cvrf-openEuler-BA-2000-1001"); !errors.Is(err, backend.ErrBulletinNotFound) {
+		t.Errorf("expect not found, got %v", err)
+	}
+
+	// the id is reset in the new year
+	impl.clock = fixedClock{now: now.AddDate(1, 0, 0)}
+	if id, err := impl.MaxBulletinID(); err != nil || id != 1000 {
+		t.Errorf("max bulletin id of new year, got %d, %v", id, err)
+	}
+}
+
+func TestBackendErrors(t *testing.T) {
+	s := backendtest.NewServer("Authorization", "secret")
+	defer s.Close()
+
+	if _, err := newTestBackend(s.URL, "wrong", 3).PublishedDefects(); !errors.Is(err, ErrUnauthorized) {
+		t.Errorf("expect unauthorized, got %v", err)
+	}
+
+	impl := newTestBackend(s.URL, "secret", 3)
+
+	// it succeeds after retrying
+	s.FailNext(3)
+	if _, err := impl.PublishedDefects(); err != nil {
+		t.Errorf("expect success after retries, got %v", err)
+	}
+
+	s.FailNext(4)
+
+	var se *StatusError
+	if _, err := impl.PublishedDefects(); !errors.As(err, &se) || se.StatusCode != 503 {
+		t.Errorf("expect status error, got %v", err)
+	}
+
+	// the retries are disabled
+	s.FailNext(1)
+	if _, err := newTestBackend(s.URL, "secret", 0).PublishedDefects(); !errors.As(err, &se) {
+		t.Errorf("expect no retry, got %v", err)
+	}
+}
@@ -8,7 +8,11 @@ import (
 
 	"github.com/opensourceways/defect-manager/defect/domain"
 	"github.com/opensourceways/defect-manager/defect/domain/dp"
-	"github.com/opensourceways/defect-manager/utils"
+)
+
+const (
+	nvdUrlPrefix = "https://nvd.nist.gov/vuln/detail/"
+	dateLayout   = "2006-01-02"
 )
 
 var instance *bulletinImpl
@@ -72,6 +76,8 @@ func (impl bulletinImpl) documentPublisher() DocumentPublisher {
 }
 
 func (impl bulletinImpl) documentTracking(sb *domain.SecurityBulletin) DocumentTracking {
+	date := sb.Date.Format(dateLayout)
+
 	return DocumentTracking{
 		Identification: Identification{
 			Id: sb.Identification,
@@ -81,15 +87,15 @@ func (impl bulletinImpl) documentTracking(sb *domain.SecurityBulletin) DocumentT
 		RevisionHistory: RevisionHistory{
 			Revision: []Revision{{
 				Number:      "1.0",
-				Date:        sb.Date,
+				Date:        date,
 				Description: "Initial",
 			}},
 		},
-		InitialReleaseDate: sb.Date,
-		CurrentReleaseDate: sb.Date,
+		InitialReleaseDate: date,
+		CurrentReleaseDate: date,
 		Generator: Generator{
 			Engine: "openEuler BA Tool V1.0",
-			Date:   sb.Date,
+			Date:   date,
 		},
 	}
 }
@@ -99,7 +105,7 @@ func (impl bulletinImpl) documentNotes(sb *domain.SecurityBulletin) DocumentNote
 	var highestLevelIndex int
 
 	for _, defect := range sb.Defects {
-		description += fmt.Sprintf("%s(%s)\r\n\r\n", defect.Description, impl.bugID(defect.Issue.Number))
+		description += fmt.Sprintf("%s(%s)\r\n\r\n", defect.Description, impl.bugID(sb, defect.Issue.Number))
 		// Choose the highest security level in defects, as security level in bulletin
 		for k, v := range dp.SequenceSeverityLevel {
 			if v == defect.SeverityLevel.String() && k > highestLevelIndex {
@@ -164,31 +170,42 @@ func (impl bulletinImpl) documentReferences(sb *domain.SecurityBulletin) Documen
 		defectUrl = append(defectUrl, CveUrl{Url: url})
 	}
 
-	return DocumentReferences{
-		CveReference: []CveReference{
-			{
-				Type:   "Self",
-				CveUrl: selfUrl,
-			},
-			{
-				Type:   "openEuler Bugfix",
-				CveUrl: defectUrl,
-			},
+	refs := []CveReference{
+		{
+			Type:   "Self",
+			CveUrl: selfUrl,
+		},
+		{
+			Type:   "openEuler Bugfix",
+			CveUrl: defectUrl,
 		},
 	}
-}
 
-func (impl bulletinImpl) productTree(sb *domain.SecurityBulletin) ProductTree {
-	getCpe := func(v string) string {
-		t := strings.Split(v, "-")
-		return fmt.Sprintf("cpe:/a:%v:%v:%v", t[0], t[0], strings.Join(t[1:], "-"))
+	var otherUrl []CveUrl
+	for _, defect := range sb.Defects {
+		for _, v := range defect.ReferencesOf(dp.ReferenceTypeCVE) {
+			otherUrl = append(otherUrl, CveUrl{Url: nvdUrlPrefix + v})
+		}
+	}
+
+	if len(otherUrl) > 0 {
+		refs = append(refs, CveReference{
+			Type:   "Other",
+			CveUrl: otherUrl,
+		})
 	}
 
+	return DocumentReferences{
+		CveReference: refs,
+	}
+}
+
+func (impl bulletinImpl) productTree(sb *domain.SecurityBulletin) ProductTree {
 	var productOfVersion []FullProductName
 	for _, v := range sb.AffectedVersion {
 		productOfVersion = append(productOfVersion, FullProductName{
 			ProductId:       v.String(),
-			Cpe:             getCpe(v.String()),
+			Cpe:             dp.CPEOfSystemVersion(v),
 			FullProductName: v.String(),
 		})
 	}
@@ -203,12 +220,12 @@ func (impl bulletinImpl) productTree(sb *domain.SecurityBulletin) ProductTree {
 		branchOfVersion,
 	}
 
-	for arch, products := range sb.ProductTree {
+	for _, arch := range sb.Arches() {
 		var productOfArch []FullProductName
-		for _, p := range products {
+		for _, p := range sb.ProductsOf(arch) {
 			productOfArch = append(productOfArch, FullProductName{
 				ProductId:       p.ID,
-				Cpe:             getCpe(p.CPE),
+				Cpe:             p.CPE,
 				FullProductName: p.FullName,
 			})
 		}
@@ -230,6 +247,7 @@ func (impl bulletinImpl) productTree(sb *domain.SecurityBulletin) ProductTree {
 
 func (impl bulletinImpl) vulnerability(sb *domain.SecurityBulletin) []Vulnerability {
 	var vs []Vulnerability
+	date := sb.Date.Format(dateLayout)
 
 	for k, defect := range sb.Defects {
 		var idOfStatus []ProductId
@@ -251,8 +269,8 @@ func (impl bulletinImpl) vulnerability(sb *domain.SecurityBulletin) []Vulnerabil
 					Note:    defect.Description,
 				},
 			},
-			ReleaseDate: sb.Date,
-			Bug:         impl.bugID(defect.Issue.Number),
+			ReleaseDate: date,
+			Bug:         impl.bugID(sb, defect.Issue.Number),
 			ProductStatuses: ProductStatuses{
 				Status: Status{
 					Type:      "Fixed",
@@ -269,18 +287,70 @@ func (impl bulletinImpl) vulnerability(sb *domain.SecurityBulletin) []Vulnerabil
 				Remediation: Remediation{
 					Type:        "Vendor Fix",
 					Description: fmt.Sprintf("%s bug update", sb.Component),
-					Date:        sb.Date,
+					Date:        date,
 					Url:         impl.cfg.SecurityBulletinUrlPrefix + sb.Identification,
 				},
 			},
 		}
 
+		// CVRF allows only one CVE in a vulnerability, the others are in the references
+		if cves := defect.ReferencesOf(dp.ReferenceTypeCVE); len(cves) > 0 {
+			vul.CVE = cves[0]
+		}
+
+		for _, v := range defect.ReferencesOf(dp.ReferenceTypeCWE) {
+			vul.CWE = append(vul.CWE, CWE{ID: v, Name: v})
+		}
+
+		vul.References = impl.vulReferences(&defect)
+
+		if defect.CVSS != nil {
+			vul.CVSSScoreSets = &CVSSScoreSets{
+				ScoreSet: ScoreSet{
+					BaseScore: strconv.FormatFloat(defect.CVSS.Score(), 'f', 1, 64),
+					Vector:    defect.CVSS.Vector(),
+				},
+			}
+		}
+
 		vs = append(vs, vul)
 	}
 
 	return vs
 }
 
-func (impl bulletinImpl) bugID(issueNumber string) string {
-	return fmt.Sprintf("BUG-%d-%s", utils.Year(), issueNumber)
+func (impl bulletinImpl) vulReferences(defect *domain.Defect) *VulReferences {
+	var refs []VulReference
+	for _, v := range defect.ReferencesOf(dp.ReferenceTypeCVE) {
+		refs = append(refs, VulReference{
+			Type:        "External",
+			Url:         nvdUrlPrefix + v,
+			Description: v,
+		})
+	}
+
+	description := map[string]string{
+		dp.ReferenceTypeUpstreamCommit.String(): "Upstream Commit",
+		dp.ReferenceTypeUpstreamBug.String():    "Upstream Bug",
+	}
+
+	for _, t := range []dp.ReferenceType{dp.ReferenceTypeUpstreamCommit, dp.ReferenceTypeUpstreamBug} {
+		for _, v := range defect.ReferencesOf(t) {
+			refs = append(refs, VulReference{
+				Type:        "External",
+				Url:         v,
+				Description: description[t.String()],
+			})
+		}
+	}
+
+	if len(refs) == 0 {
+		return nil
+	}
+
+	return &VulReferences{Reference: refs}
+}
+
+func (impl bulletinImpl) bugID(sb *domain.SecurityBulletin, issueNumber string) string {
+	return fmt.Sprintf("BUG-%d-%s", sb.Date.Year(), issueNumber)
 }
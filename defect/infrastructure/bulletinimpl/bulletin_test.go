@@ -0,0 +1,121 @@
+package bulletinimpl
+
+import (
+	"bytes"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/opensourceways/defect-manager/defect/domain"
+	"github.com/opensourceways/defect-manager/defect/domain/dp"
+)
+
+var update = flag.Bool("update", false, "update the golden files")
+
+func testBulletin() domain.SecurityBulletin {
+	v1, _ := dp.NewSystemVersion("openEuler-20.03-LTS-SP3")
+	v2, _ := dp.NewSystemVersion("openEuler-22.03-LTS")
+	low, _ := dp.NewSeverityLevel("Low")
+	high, _ := dp.NewSeverityLevel("High")
+	cvss, _ := dp.NewCVSS("CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:N/A:N")
+
+	tree := make(domain.ProductTree)
+	for _, rpm := range []string{
+		"zbar-devel-0.22-5.oe2203.x86_64.rpm",
+		"zbar-0.22-5.oe2203.x86_64.rpm",
+		"zbar-0.22-5.oe2203.src.rpm",
+		"zbar-0.22-5.oe2203.aarch64.rpm",
+		"zbar-0.22-4.oe2003sp3.aarch64.rpm",
+		"zbar-0.22-4.oe2003sp3.src.rpm",
+	} {
+		n, _ := dp.ParseNEVRA(rpm)
+		arch := dp.NewArch(n.Arch)
+		tree[arch] = append(tree[arch], domain.Product{
+			ID:       n.Name,
+			CPE:      "cpe:/a:openEuler:openEuler:22.03-LTS",
+			FullName: rpm,
+			RPM:      n,
+		})
+	}
+
+	return domain.SecurityBulletin{
+		AffectedVersion: []dp.SystemVersion{v1, v2},
+		Identification:  "cvrf-openEuler-BA-2023-1001",
+		Date:            time.Date(2023, 10, 16, 23, 30, 0, 0, time.UTC),
+		Component:       "zbar",
+		ProductTree:     tree,
+		Defects: domain.Defects{
+			{
+				Component:       "zbar",
+				Description:     "zbar crashes when decoding a malformed QR code",
+				SeverityLevel:   high,
+				CVSS:            cvss,
+				AffectedVersion: []dp.SystemVersion{v1, v2},
+				References:      dp.ParseReferences("CVE-2023-40889 CWE-787 https://github.com/mchehab/zbar/issues/258"),
+				Issue:           domain.Issue{Org: "src-openeuler", Repo: "zbar", Number: "I7ABCD"},
+			},
+			{
+				Component:       "zbar",
+				Description:     "zbarimg prints a wrong type",
+				SeverityLevel:   low,
+				AffectedVersion: []dp.SystemVersion{v2},
+				Issue:           domain.Issue{Org: "src-openeuler", Repo: "zbar", Number: "I7EFGH"},
+			},
+		},
+	}
+}
+
+func TestGenerateGolden(t *testing.T) {
+	cfg := new(Config)
+	cfg.SetDefault()
+	impl := bulletinImpl{cfg: cfg}
+
+	testGolden(t, impl.Generate, "bulletin.golden.xml")
+}
+
+func TestGenerateCSAFGolden(t *testing.T) {
+	cfg := new(Config)
+	cfg.SetDefault()
+	impl := bulletinImpl{cfg: cfg}
+
+	testGolden(t, impl.GenerateCSAF, "bulletin.golden.json")
+}
+
+func testGolden(t *testing.T, generate func(*domain.SecurityBulletin) ([]byte, error), name string) {
+	sb := testBulletin()
+	got, err := generate(&sb)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the product tree is a map, its iteration order must not affect the output
+	for i := 0; i < 20; i++ {
+		sb := testBulletin()
+		v, err := generate(&sb)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if !bytes.Equal(v, got) {
+			t.Fatal("the output is not reproducible")
+		}
+	}
+
+	golden := filepath.Join("testdata", name)
+	if *update {
+		if err := os.WriteFile(golden, got, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	want, err := os.ReadFile(golden)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Equal(got, want) {
+		t.Errorf("the output differs from %s, run go test -update if the change is expected:\n%s", golden, got)
+	}
+}
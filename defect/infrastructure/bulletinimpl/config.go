@@ -7,6 +7,7 @@ type Config struct {
 	IssuingAuthority          string `json:"issuing_authority"`
 	SecurityBulletinUrlPrefix string `json:"security_bulletin_url_prefix"`
 	DefectUrlPrefix           string `json:"defect_url_prefix"`
+	PublisherNamespace        string `json:"publisher_namespace"`
 }
 
 func (c *Config) SetDefault() {
@@ -33,4 +34,8 @@ func (c *Config) SetDefault() {
 	if c.DefectUrlPrefix == "" {
 		c.DefectUrlPrefix = "https://www.openeuler.org/en/security/cve/detail.html?id="
 	}
+
+	if c.PublisherNamespace == "" {
+		c.PublisherNamespace = "https://www.openeuler.org"
+	}
 }
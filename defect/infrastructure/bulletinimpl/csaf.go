@@ -0,0 +1,382 @@
+package bulletinimpl
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/opensourceways/defect-manager/defect/domain"
+	"github.com/opensourceways/defect-manager/defect/domain/dp"
+)
+
+const (
+	csafVersion     = "2.0"
+	csafBugIDSystem = "openEuler Bugfix"
+)
+
+type CsafBA struct {
+	Document        CsafDocument        `json:"document"`
+	ProductTree     CsafProductTree     `json:"product_tree"`
+	Vulnerabilities []CsafVulnerability `json:"vulnerabilities"`
+}
+
+type CsafDocument struct {
+	Category          string            `json:"category"`
+	CsafVersion       string            `json:"csaf_version"`
+	Title             string            `json:"title"`
+	Lang              string            `json:"lang"`
+	Publisher         CsafPublisher     `json:"publisher"`
+	Tracking          CsafTracking      `json:"tracking"`
+	Notes             []CsafNote        `json:"notes"`
+	References        []CsafReference   `json:"references"`
+	AggregateSeverity CsafAggregateText `json:"aggregate_severity"`
+}
+
+type CsafPublisher struct {
+	Category       string `json:"category"`
+	Name           string `json:"name"`
+	Namespace      string `json:"namespace"`
+	ContactDetails string `json:"contact_details"`
+}
+
+type CsafTracking struct {
+	ID                 string         `json:"id"`
+	Status             string         `json:"status"`
+	Version            string         `json:"version"`
+	InitialReleaseDate string         `json:"initial_release_date"`
+	CurrentReleaseDate string         `json:"current_release_date"`
+	RevisionHistory    []CsafRevision `json:"revision_history"`
+	Generator          CsafGenerator  `json:"generator"`
+}
+
+type CsafRevision struct {
+	Number  string `json:"number"`
+	Date    string `json:"date"`
+	Summary string `json:"summary"`
+}
+
+type CsafGenerator struct {
+	Engine CsafEngine `json:"engine"`
+	Date   string     `json:"date"`
+}
+
+type CsafEngine struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+type CsafNote struct {
+	Category string `json:"category"`
+	Title    string `json:"title,omitempty"`
+	Text     string `json:"text"`
+}
+
+type CsafReference struct {
+	Category string `json:"category"`
+	Summary  string `json:"summary"`
+	URL      string `json:"url"`
+}
+
+type CsafAggregateText struct {
+	Text string `json:"text"`
+}
+
+type CsafProductTree struct {
+	Branches []CsafBranch `json:"branches"`
+}
+
+// CsafBranch has either the sub branches or the product
+type CsafBranch struct {
+	Category string       `json:"category"`
+	Name     string       `json:"name"`
+	Branches []CsafBranch `json:"branches,omitempty"`
+	Product  *CsafProduct `json:"product,omitempty"`
+}
+
+type CsafProduct struct {
+	ProductID string             `json:"product_id"`
+	Name      string             `json:"name"`
+	Helper    *CsafProductHelper `json:"product_identification_helper,omitempty"`
+}
+
+type CsafProductHelper struct {
+	CPE string `json:"cpe"`
+}
+
+type CsafVulnerability struct {
+	CVE           string            `json:"cve,omitempty"`
+	CWE           *CsafCWE          `json:"cwe,omitempty"`
+	IDs           []CsafID          `json:"ids"`
+	Notes         []CsafNote        `json:"notes"`
+	ReleaseDate   string            `json:"release_date"`
+	ProductStatus CsafProductStatus `json:"product_status"`
+	Remediations  []CsafRemediation `json:"remediations"`
+	Threats       []CsafThreat      `json:"threats"`
+	Scores        []CsafScore       `json:"scores,omitempty"`
+	References    []CsafReference   `json:"references,omitempty"`
+}
+
+type CsafCWE struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+type CsafID struct {
+	SystemName string `json:"system_name"`
+	Text       string `json:"text"`
+}
+
+type CsafProductStatus struct {
+	Fixed []string `json:"fixed"`
+}
+
+type CsafRemediation struct {
+	Category   string   `json:"category"`
+	Details    string   `json:"details"`
+	Date       string   `json:"date"`
+	URL        string   `json:"url"`
+	ProductIDs []string `json:"product_ids"`
+}
+
+type CsafThreat struct {
+	Category string `json:"category"`
+	Details  string `json:"details"`
+}
+
+// CsafScore has cvss_v3 for the vector of v3.0 and v3.1, cvss_v4 for v4.0
+type CsafScore struct {
+	Products []string  `json:"products"`
+	CVSSv3   *CsafCVSS `json:"cvss_v3,omitempty"`
+	CVSSv4   *CsafCVSS `json:"cvss_v4,omitempty"`
+}
+
+type CsafCVSS struct {
+	Version      string  `json:"version"`
+	VectorString string  `json:"vectorString"`
+	BaseScore    float64 `json:"baseScore"`
+	BaseSeverity string  `json:"baseSeverity"`
+}
+
+// GenerateCSAF returns the CSAF 2.0 advisory of the bulletin, it has the same content as the CVRF one
+func (impl bulletinImpl) GenerateCSAF(sb *domain.SecurityBulletin) ([]byte, error) {
+	data := CsafBA{
+		Document:        impl.csafDocument(sb),
+		ProductTree:     impl.csafProductTree(sb),
+		Vulnerabilities: impl.csafVulnerabilities(sb),
+	}
+
+	return json.MarshalIndent(data, "", "\t")
+}
+
+func (impl bulletinImpl) csafDocument(sb *domain.SecurityBulletin) CsafDocument {
+	date := sb.Date.Format(time.RFC3339)
+	notes := impl.documentNotes(sb)
+
+	categories := map[string]string{
+		"Synopsis":    "summary",
+		"Summary":     "summary",
+		"Description": "description",
+	}
+
+	var cnotes []CsafNote
+	var severity string
+	for _, n := range notes.Note {
+		switch n.Title {
+		case "Severity":
+			severity = n.Note
+		case "Affected Component":
+			cnotes = append(cnotes, CsafNote{Category: "general", Title: n.Title, Text: n.Note})
+		default:
+			cnotes = append(cnotes, CsafNote{Category: categories[n.Title], Title: n.Title, Text: n.Note})
+		}
+	}
+
+	refs := []CsafReference{{
+		Category: "self",
+		Summary:  sb.Identification,
+		URL:      impl.cfg.SecurityBulletinUrlPrefix + sb.Identification,
+	}}
+
+	for _, defect := range sb.Defects {
+		refs = append(refs, CsafReference{
+			Category: "external",
+			Summary:  impl.bugID(sb, defect.Issue.Number),
+			URL: fmt.Sprintf("https://gitee.com/%s/%s/issues/%s",
+				defect.Issue.Org, defect.Issue.Repo, defect.Issue.Number,
+			),
+		})
+	}
+
+	return CsafDocument{
+		Category:    "csaf_security_advisory",
+		CsafVersion: csafVersion,
+		Title:       impl.documentTitle(sb).DocumentTitle,
+		Lang:        "en",
+		Publisher: CsafPublisher{
+			Category:       "vendor",
+			Name:           impl.cfg.IssuingAuthority,
+			Namespace:      impl.cfg.PublisherNamespace,
+			ContactDetails: impl.cfg.ContactDetails,
+		},
+		Tracking: CsafTracking{
+			ID:                 sb.Identification,
+			Status:             "final",
+			Version:            "1",
+			InitialReleaseDate: date,
+			CurrentReleaseDate: date,
+			RevisionHistory: []CsafRevision{{
+				Number:  "1",
+				Date:    date,
+				Summary: "Initial",
+			}},
+			Generator: CsafGenerator{
+				Engine: CsafEngine{Name: "openEuler BA Tool", Version: "1.0"},
+				Date:   date,
+			},
+		},
+		Notes:             cnotes,
+		References:        refs,
+		AggregateSeverity: CsafAggregateText{Text: severity},
+	}
+}
+
+func (impl bulletinImpl) csafProductTree(sb *domain.SecurityBulletin) CsafProductTree {
+	var versions []CsafBranch
+	for _, v := range sb.AffectedVersion {
+		versions = append(versions, CsafBranch{
+			Category: "product_version",
+			Name:     v.String(),
+			Product: &CsafProduct{
+				ProductID: v.String(),
+				Name:      v.String(),
+				Helper:    &CsafProductHelper{CPE: dp.CPEOfSystemVersion(v)},
+			},
+		})
+	}
+
+	branches := []CsafBranch{{
+		Category: "product_name",
+		Name:     "openEuler",
+		Branches: versions,
+	}}
+
+	for _, arch := range sb.Arches() {
+		// product_id must be unique in CSAF, the id of CVRF is the package name which is not
+		var products []CsafBranch
+		for _, p := range sb.ProductsOf(arch) {
+			products = append(products, CsafBranch{
+				Category: "product_version",
+				Name:     p.FullName,
+				Product: &CsafProduct{
+					ProductID: p.FullName,
+					Name:      p.FullName,
+					Helper:    &CsafProductHelper{CPE: p.CPE},
+				},
+			})
+		}
+
+		branches = append(branches, CsafBranch{
+			Category: "architecture",
+			Name:     arch.String(),
+			Branches: products,
+		})
+	}
+
+	return CsafProductTree{Branches: branches}
+}
+
+func (impl bulletinImpl) csafVulnerabilities(sb *domain.SecurityBulletin) []CsafVulnerability {
+	date := sb.Date.Format(time.RFC3339)
+
+	fixed := make([]string, len(sb.AffectedVersion))
+	for i, v := range sb.AffectedVersion {
+		fixed[i] = v.String()
+	}
+
+	vs := make([]CsafVulnerability, 0, len(sb.Defects))
+	for i := range sb.Defects {
+		defect := &sb.Defects[i]
+
+		vul := CsafVulnerability{
+			IDs: []CsafID{{SystemName: csafBugIDSystem, Text: impl.bugID(sb, defect.Issue.Number)}},
+			Notes: []CsafNote{{
+				Category: "description",
+				Title:    "Vulnerability Description",
+				Text:     defect.Description,
+			}},
+			ReleaseDate:   date,
+			ProductStatus: CsafProductStatus{Fixed: fixed},
+			Remediations: []CsafRemediation{{
+				Category:   "vendor_fix",
+				Details:    fmt.Sprintf("%s bug update", sb.Component),
+				Date:       date,
+				URL:        impl.cfg.SecurityBulletinUrlPrefix + sb.Identification,
+				ProductIDs: fixed,
+			}},
+			Threats: []CsafThreat{{Category: "impact", Details: defect.SeverityLevel.String()}},
+		}
+
+		// CSAF allows only one CVE and one CWE in a vulnerability like CVRF
+		if cves := defect.ReferencesOf(dp.ReferenceTypeCVE); len(cves) > 0 {
+			vul.CVE = cves[0]
+		}
+
+		if cwes := defect.ReferencesOf(dp.ReferenceTypeCWE); len(cwes) > 0 {
+			vul.CWE = &CsafCWE{ID: cwes[0], Name: cwes[0]}
+		}
+
+		if refs := impl.vulReferences(defect); refs != nil {
+			for _, r := range refs.Reference {
+				vul.References = append(vul.References, CsafReference{
+					Category: strings.ToLower(r.Type),
+					Summary:  r.Description,
+					URL:      r.Url,
+				})
+			}
+		}
+
+		if defect.CVSS != nil {
+			vul.Scores = []CsafScore{csafScore(defect.CVSS, fixed)}
+		}
+
+		vs = append(vs, vul)
+	}
+
+	return vs
+}
+
+func csafScore(c dp.CVSS, products []string) CsafScore {
+	v := &CsafCVSS{
+		Version:      c.Version(),
+		VectorString: c.Vector(),
+		BaseScore:    c.Score(),
+		BaseSeverity: cvssSeverity(c.Score()),
+	}
+
+	s := CsafScore{Products: products}
+	if c.Version() == dp.CVSSVersion40 {
+		s.CVSSv4 = v
+	} else {
+		s.CVSSv3 = v
+	}
+
+	return s
+}
+
+// cvssSeverity is the qualitative severity rating of the specification, it has None and Medium
+// which are different from the severity level.
+func cvssSeverity(score float64) string {
+	switch {
+	case score >= 9.0:
+		return "CRITICAL"
+	case score >= 7.0:
+		return "HIGH"
+	case score >= 4.0:
+		return "MEDIUM"
+	case score > 0:
+		return "LOW"
+	default:
+		return "NONE"
+	}
+}
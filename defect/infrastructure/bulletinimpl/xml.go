@@ -120,9 +120,31 @@ type Vulnerability struct {
 	CveNotes        CveNotes        `xml:"Notes,omitempty"`
 	ReleaseDate     string          `xml:"ReleaseDate"`
 	Bug             string          `xml:"Bug"`
+	CVE             string          `xml:"CVE,omitempty"`
+	CWE             []CWE           `xml:"CWE,omitempty"`
 	ProductStatuses ProductStatuses `xml:"ProductStatuses,omitempty"`
 	Threats         Threats         `xml:"Threats,omitempty"`
+	CVSSScoreSets   *CVSSScoreSets  `xml:"CVSSScoreSets,omitempty"`
 	Remediations    Remediations    `xml:"Remediations,omitempty"`
+	References      *VulReferences  `xml:"References,omitempty"`
+}
+
+type CWE struct {
+	XMLName xml.Name `xml:"CWE,omitempty"`
+	ID      string   `xml:"ID,attr"`
+	Name    string   `xml:",innerxml"`
+}
+
+type VulReferences struct {
+	XMLName   xml.Name       `xml:"References,omitempty"`
+	Reference []VulReference `xml:"Reference,omitempty"`
+}
+
+type VulReference struct {
+	XMLName     xml.Name `xml:"Reference,omitempty"`
+	Type        string   `xml:"Type,attr"`
+	Url         string   `xml:"URL"`
+	Description string   `xml:"Description"`
 }
 
 type CveNotes struct {
@@ -166,6 +188,17 @@ type Threat struct {
 	Description string   `xml:"Description"`
 }
 
+type CVSSScoreSets struct {
+	XMLName  xml.Name `xml:"CVSSScoreSets,omitempty"`
+	ScoreSet ScoreSet `xml:"ScoreSet,omitempty"`
+}
+
+type ScoreSet struct {
+	XMLName   xml.Name `xml:"ScoreSet,omitempty"`
+	BaseScore string   `xml:"BaseScore"`
+	Vector    string   `xml:"Vector"`
+}
+
 type Remediations struct {
 	XMLName     xml.Name    `xml:"Remediations,omitempty"`
 	Remediation Remediation `xml:"Remediation,omitempty"`
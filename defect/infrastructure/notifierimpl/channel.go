@@ -0,0 +1,127 @@
+package notifierimpl
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/opensourceways/defect-manager/defect/domain"
+	"github.com/opensourceways/defect-manager/defect/domain/notifier"
+)
+
+func newChannel(cfg *Channel) notifier.Channel {
+	if cfg.Type == ChannelSMTP {
+		return smtpChannel{cfg: &cfg.SMTP}
+	}
+
+	return webhookChannel{
+		cfg:    &cfg.Webhook,
+		cli:    NewWebhookClient(&cfg.Webhook),
+		format: formats[cfg.Type],
+		check:  checks[cfg.Type],
+	}
+}
+
+// formats build the body of the incoming webhooks
+var formats = map[string]func(*domain.Event) interface{}{
+	ChannelWebhook: func(e *domain.Event) interface{} {
+		return eventPayload{
+			Event:      e.Type,
+			Title:      e.Title,
+			Content:    e.Content,
+			Components: e.Components,
+			Repos:      e.Repos,
+			Severity:   e.Severity,
+			OccurredAt: e.OccurredAt.Format(time.RFC3339),
+		}
+	},
+
+	ChannelWeCom: func(e *domain.Event) interface{} {
+		return map[string]interface{}{
+			"msgtype": "text",
+			"text":    map[string]string{"content": textOf(e)},
+		}
+	},
+
+	ChannelFeishu: func(e *domain.Event) interface{} {
+		return map[string]interface{}{
+			"msg_type": "text",
+			"content":  map[string]string{"text": textOf(e)},
+		}
+	},
+
+	ChannelSlack: func(e *domain.Event) interface{} {
+		return map[string]string{"text": textOf(e)}
+	},
+}
+
+// checks parse the body of the 200 response of the IM which reports the failure in it
+var checks = map[string]func([]byte) error{
+	ChannelWeCom: func(body []byte) error {
+		var v struct {
+			ErrCode *int   `json:"errcode"`
+			ErrMsg  string `json:"errmsg"`
+		}
+
+		if err := json.Unmarshal(body, &v); err != nil || v.ErrCode == nil {
+			return fmt.Errorf("invalid response of wecom: %s", string(body))
+		}
+
+		if *v.ErrCode != 0 {
+			return fmt.Errorf("wecom responds errcode %d: %s", *v.ErrCode, v.ErrMsg)
+		}
+
+		return nil
+	},
+
+	ChannelFeishu: func(body []byte) error {
+		var v struct {
+			Code *int   `json:"code"`
+			Msg  string `json:"msg"`
+		}
+
+		if err := json.Unmarshal(body, &v); err != nil || v.Code == nil {
+			return fmt.Errorf("invalid response of feishu: %s", string(body))
+		}
+
+		if *v.Code != 0 {
+			return fmt.Errorf("feishu responds code %d: %s", *v.Code, v.Msg)
+		}
+
+		return nil
+	},
+}
+
+type eventPayload struct {
+	Event      string   `json:"event"`
+	Title      string   `json:"title"`
+	Content    string   `json:"content"`
+	Components []string `json:"components"`
+	Repos      []string `json:"repos"`
+	Severity   []string `json:"severity"`
+	OccurredAt string   `json:"occurred_at"`
+}
+
+func textOf(e *domain.Event) string {
+	return e.Title + "\n\n" + e.Content
+}
+
+// webhookChannel posts the event to a generic webhook or the incoming webhook of IM,
+// the receiver is fixed by the url, so the recipients are ignored. check is nil if
+// any 2xx response is a success.
+type webhookChannel struct {
+	cfg    *Webhook
+	cli    *http.Client
+	format func(*domain.Event) interface{}
+	check  func([]byte) error
+}
+
+func (ch webhookChannel) Send(e *domain.Event, recipients []string) error {
+	body, err := postJSON(ch.cli, ch.cfg, ch.format(e))
+	if err != nil || ch.check == nil {
+		return err
+	}
+
+	return ch.check(body)
+}
@@ -0,0 +1,103 @@
+package notifierimpl
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/opensourceways/defect-manager/defect/domain"
+)
+
+func testEvent() domain.Event {
+	return domain.Event{
+		Type:       domain.EventDefectAccepted,
+		Title:      "Defect I1 of zbar is accepted",
+		Content:    "Component: zbar\n.leading dot\nSeverity: High",
+		Components: []string{"zbar"},
+		Repos:      []string{"src-openeuler/zbar"},
+		Severity:   []string{"High"},
+		OccurredAt: time.Date(2023, 10, 16, 10, 0, 0, 0, time.UTC),
+	}
+}
+
+func TestSMTPChannel(t *testing.T) {
+	server, err := newFakeSMTPServer()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer server.Close()
+
+	host, port := server.Addr()
+	cfg := Channel{Name: "mail", Type: ChannelSMTP, SMTP: SMTP{Host: host, Port: port, From: "defect@openeuler.org"}}
+	cfg.SMTP.setDefault()
+
+	e := testEvent()
+	if err := newChannel(&cfg).Send(&e, []string{"a@openeuler.org", "b@openeuler.org"}); err != nil {
+		t.Fatal(err)
+	}
+
+	mails := server.Mails()
+	if len(mails) != 1 {
+		t.Fatalf("got %d mails", len(mails))
+	}
+
+	m := mails[0]
+	if m.From != "defect@openeuler.org" || strings.Join(m.To, ",") != "a@openeuler.org,b@openeuler.org" {
+		t.Errorf("unexpected envelope: %v", m)
+	}
+
+	if !strings.Contains(m.Data, "Subject: Defect I1 of zbar is accepted\r\n") ||
+		!strings.Contains(m.Data, "\r\n.leading dot\r\n") {
+		t.Errorf("unexpected mail:\n%s", m.Data)
+	}
+}
+
+func TestWebhookChannels(t *testing.T) {
+	var body map[string]interface{}
+	resp := `{"errcode":0,"errmsg":"ok","code":0,"msg":"success"}`
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body = nil
+		_ = json.NewDecoder(r.Body).Decode(&body)
+
+		_, _ = w.Write([]byte(resp))
+	}))
+	defer server.Close()
+
+	cases := []struct {
+		typ   string
+		field func(map[string]interface{}) interface{}
+	}{
+		{ChannelWebhook, func(m map[string]interface{}) interface{} { return m["title"] }},
+		{ChannelWeCom, func(m map[string]interface{}) interface{} { return m["text"].(map[string]interface{})["content"] }},
+		{ChannelFeishu, func(m map[string]interface{}) interface{} { return m["content"].(map[string]interface{})["text"] }},
+		{ChannelSlack, func(m map[string]interface{}) interface{} { return m["text"] }},
+	}
+
+	e := testEvent()
+	for _, c := range cases {
+		cfg := Channel{Name: c.typ, Type: c.typ, Webhook: Webhook{URL: server.URL}}
+		cfg.Webhook.SetDefault()
+
+		if err := newChannel(&cfg).Send(&e, nil); err != nil {
+			t.Fatalf("%s: %s", c.typ, err.Error())
+		}
+
+		if v, _ := c.field(body).(string); !strings.HasPrefix(v, e.Title) {
+			t.Errorf("%s: unexpected body %v", c.typ, body)
+		}
+	}
+
+	// the failures of IM are reported in the body of 200 response
+	resp = `{"errcode":93000,"errmsg":"invalid webhook url","code":19001,"msg":"param invalid"}`
+	for _, typ := range []string{ChannelWeCom, ChannelFeishu} {
+		cfg := Channel{Name: typ, Type: typ, Webhook: Webhook{URL: server.URL}}
+		cfg.Webhook.SetDefault()
+
+		if err := newChannel(&cfg).Send(&e, nil); err == nil {
+			t.Errorf("%s: expect the error in body", typ)
+		}
+	}
+}
@@ -0,0 +1,211 @@
+package notifierimpl
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/opensourceways/defect-manager/defect/domain"
+	"github.com/opensourceways/defect-manager/defect/domain/dp"
+)
+
+const (
+	TargetBackend = "backend"
+	TargetWebhook = "webhook"
+
+	ChannelSMTP    = "smtp"
+	ChannelWebhook = "webhook"
+	ChannelWeCom   = "wecom"
+	ChannelFeishu  = "feishu"
+	ChannelSlack   = "slack"
+)
+
+// Config Target is backend or webhook, the notification of bulletins to the downstream is disabled if it is empty.
+// RetryInterval is the seconds between attempts, it is doubled after each failure.
+// The events of defects are sent to the Subscriptions via the Channels.
+type Config struct {
+	Target        string         `json:"target"`
+	MaxAttempts   int            `json:"max_attempts"`
+	RetryInterval int            `json:"retry_interval"`
+	Webhook       Webhook        `json:"webhook"`
+	Channels      []Channel      `json:"channels"`
+	Subscriptions []Subscription `json:"subscriptions"`
+}
+
+// Channel Type is one of smtp, webhook, wecom, feishu and slack,
+// SMTP is used by smtp and Webhook is used by the others.
+type Channel struct {
+	Name    string  `json:"name"    required:"true"`
+	Type    string  `json:"type"    required:"true"`
+	SMTP    SMTP    `json:"smtp"`
+	Webhook Webhook `json:"webhook"`
+}
+
+// SMTP the mail is sent without authentication if Username is empty,
+// STARTTLS is used if the server supports it. Timeout is in seconds.
+type SMTP struct {
+	Host     string `json:"host"`
+	Port     int    `json:"port"`
+	Username string `json:"username"`
+	Password string `json:"password"`
+	From     string `json:"from"`
+	Timeout  int    `json:"timeout"`
+}
+
+// Subscription the events are sent to it if they match all the conditions, the empty condition matches all.
+// Recipients are the email addresses when the channel is smtp.
+type Subscription struct {
+	Name       string   `json:"name"    required:"true"`
+	Channel    string   `json:"channel" required:"true"`
+	Events     []string `json:"events"`
+	Components []string `json:"components"`
+	SIGs       []string `json:"sigs"`
+	Severity   []string `json:"severity"`
+	Recipients []string `json:"recipients"`
+}
+
+func (s *Subscription) toSubscription() domain.Subscription {
+	return domain.Subscription{
+		Name:       s.Name,
+		Channel:    s.Channel,
+		Events:     s.Events,
+		Components: s.Components,
+		SIGs:       s.SIGs,
+		Severity:   s.Severity,
+		Recipients: s.Recipients,
+	}
+}
+
+// Webhook Token is sent in the header of AuthHeader if it is set, Timeout is in seconds.
+type Webhook struct {
+	URL        string `json:"url"`
+	Token      string `json:"token"`
+	AuthHeader string `json:"auth_header"`
+	Timeout    int    `json:"timeout"`
+}
+
+func (c *Config) SetDefault() {
+	if c.MaxAttempts <= 0 {
+		c.MaxAttempts = 3
+	}
+
+	if c.RetryInterval <= 0 {
+		c.RetryInterval = 2
+	}
+
+	c.Webhook.SetDefault()
+
+	for i := range c.Channels {
+		c.Channels[i].Webhook.SetDefault()
+		c.Channels[i].SMTP.setDefault()
+	}
+}
+
+func (s *SMTP) setDefault() {
+	if s.Port <= 0 {
+		s.Port = 25
+	}
+
+	if s.Timeout <= 0 {
+		s.Timeout = 10
+	}
+}
+
+func (w *Webhook) SetDefault() {
+	if w.AuthHeader == "" {
+		w.AuthHeader = "Authorization"
+	}
+
+	if w.Timeout <= 0 {
+		w.Timeout = 10
+	}
+}
+
+func (c *Config) Validate() error {
+	switch c.Target {
+	case "", TargetBackend:
+
+	case TargetWebhook:
+		if c.Webhook.URL == "" {
+			return errors.New("missing url of webhook")
+		}
+
+	default:
+		return errors.New("unknown target of notification: " + c.Target)
+	}
+
+	types := make(map[string]string)
+	for i := range c.Channels {
+		ch := &c.Channels[i]
+		if _, ok := types[ch.Name]; ok {
+			return fmt.Errorf("duplicate channel %s", ch.Name)
+		}
+
+		if err := ch.validate(); err != nil {
+			return fmt.Errorf("channel %s, %s", ch.Name, err.Error())
+		}
+
+		types[ch.Name] = ch.Type
+	}
+
+	for i := range c.Subscriptions {
+		if err := c.Subscriptions[i].validate(types); err != nil {
+			return fmt.Errorf("subscription %s, %s", c.Subscriptions[i].Name, err.Error())
+		}
+	}
+
+	return nil
+}
+
+func (ch *Channel) validate() error {
+	switch ch.Type {
+	case ChannelSMTP:
+		if ch.SMTP.Host == "" || ch.SMTP.From == "" {
+			return errors.New("missing host or from of smtp")
+		}
+
+	case ChannelWebhook, ChannelWeCom, ChannelFeishu, ChannelSlack:
+		if ch.Webhook.URL == "" {
+			return errors.New("missing url of webhook")
+		}
+
+	default:
+		return errors.New("unknown type " + ch.Type)
+	}
+
+	return nil
+}
+
+// validate types is the type of each channel
+func (s *Subscription) validate(types map[string]string) error {
+	t, ok := types[s.Channel]
+	if !ok {
+		return errors.New("unknown channel " + s.Channel)
+	}
+
+	if t == ChannelSMTP && len(s.Recipients) == 0 {
+		return errors.New("missing recipients")
+	}
+
+	for _, e := range s.Events {
+		if !domain.IsValidEvent(e) {
+			return errors.New("unknown event " + e)
+		}
+	}
+
+	for _, v := range s.Severity {
+		if _, err := dp.NewSeverityLevel(v); err != nil {
+			return fmt.Errorf("%s: %s", err.Error(), v)
+		}
+	}
+
+	return nil
+}
+
+func (c *Config) IsEnabled() bool {
+	return c.Target != ""
+}
+
+func (c *Config) RetryDelay() time.Duration {
+	return time.Duration(c.RetryInterval) * time.Second
+}
@@ -0,0 +1,176 @@
+package notifierimpl
+
+import (
+	"bufio"
+	"net"
+	"strings"
+	"sync"
+)
+
+// fakeMail is a mail received by the fakeSMTPServer, Data is the message with headers
+type fakeMail struct {
+	From string
+	To   []string
+	Data string
+}
+
+// fakeSMTPServer is a local stand-in of the smtp server which keeps the mails in memory,
+// it supports the commands used by the smtp channel without STARTTLS and authentication.
+type fakeSMTPServer struct {
+	listener net.Listener
+
+	lock  sync.Mutex
+	mails []fakeMail
+
+	wg sync.WaitGroup
+}
+
+// newFakeSMTPServer listens on a random local port, the caller should Close it
+func newFakeSMTPServer() (*fakeSMTPServer, error) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, err
+	}
+
+	s := &fakeSMTPServer{listener: l}
+
+	s.wg.Add(1)
+	go s.serve()
+
+	return s, nil
+}
+
+// Addr returns the host and port
+func (s *fakeSMTPServer) Addr() (string, int) {
+	addr := s.listener.Addr().(*net.TCPAddr)
+
+	return addr.IP.String(), addr.Port
+}
+
+func (s *fakeSMTPServer) Mails() []fakeMail {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	r := make([]fakeMail, len(s.mails))
+	copy(r, s.mails)
+
+	return r
+}
+
+func (s *fakeSMTPServer) Close() {
+	s.listener.Close()
+	s.wg.Wait()
+}
+
+func (s *fakeSMTPServer) serve() {
+	defer s.wg.Done()
+
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+
+		s.wg.Add(1)
+		go func() {
+			defer s.wg.Done()
+			defer conn.Close()
+
+			s.handle(conn)
+		}()
+	}
+}
+
+func (s *fakeSMTPServer) handle(conn net.Conn) {
+	r := bufio.NewReader(conn)
+	reply := func(line string) bool {
+		_, err := conn.Write([]byte(line + "\r\n"))
+
+		return err == nil
+	}
+
+	if !reply("220 localhost fake smtp") {
+		return
+	}
+
+	var mail fakeMail
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+
+		line = strings.TrimRight(line, "\r\n")
+		cmd := strings.ToUpper(line)
+
+		var ok bool
+		switch {
+		case strings.HasPrefix(cmd, "EHLO"), strings.HasPrefix(cmd, "HELO"):
+			ok = reply("250 localhost")
+
+		case strings.HasPrefix(cmd, "MAIL FROM:"):
+			mail = fakeMail{From: trimAddr(line[len("MAIL FROM:"):])}
+			ok = reply("250 OK")
+
+		case strings.HasPrefix(cmd, "RCPT TO:"):
+			mail.To = append(mail.To, trimAddr(line[len("RCPT TO:"):]))
+			ok = reply("250 OK")
+
+		case cmd == "DATA":
+			if !reply("354 end data with <CR><LF>.<CR><LF>") {
+				return
+			}
+
+			if mail.Data, err = readData(r); err != nil {
+				return
+			}
+
+			s.lock.Lock()
+			s.mails = append(s.mails, mail)
+			s.lock.Unlock()
+
+			ok = reply("250 OK")
+
+		case cmd == "RSET", cmd == "NOOP":
+			ok = reply("250 OK")
+
+		case cmd == "QUIT":
+			reply("221 bye")
+
+			return
+
+		default:
+			ok = reply("502 command not implemented")
+		}
+
+		if !ok {
+			return
+		}
+	}
+}
+
+// readData reads until the line of single dot and removes the dot-stuffing
+func readData(r *bufio.Reader) (string, error) {
+	var b strings.Builder
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return "", err
+		}
+
+		if line == ".\r\n" {
+			return b.String(), nil
+		}
+
+		b.WriteString(strings.TrimPrefix(line, "."))
+	}
+}
+
+func trimAddr(s string) string {
+	s = strings.TrimSpace(s)
+	if i := strings.Index(s, " "); i >= 0 {
+		s = s[:i]
+	}
+
+	return strings.Trim(s, "<>")
+}
@@ -0,0 +1,169 @@
+package notifierimpl
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/opensourceways/defect-manager/defect/domain"
+	"github.com/opensourceways/defect-manager/defect/domain/backend"
+	"github.com/opensourceways/defect-manager/defect/domain/notifier"
+)
+
+// maxResponseSize limits the body of webhook response read in memory
+const maxResponseSize = 64 << 10
+
+var (
+	instance      notifier.Notifier
+	channels      map[string]notifier.Channel
+	subscriptions []domain.Subscription
+)
+
+// Init the notifier is nil if the notification is disabled
+func Init(cfg *Config, be backend.CveBackend) {
+	channels = make(map[string]notifier.Channel, len(cfg.Channels))
+	for i := range cfg.Channels {
+		channels[cfg.Channels[i].Name] = newChannel(&cfg.Channels[i])
+	}
+
+	subscriptions = make([]domain.Subscription, len(cfg.Subscriptions))
+	for i := range cfg.Subscriptions {
+		subscriptions[i] = cfg.Subscriptions[i].toSubscription()
+	}
+
+	instance = New(cfg, be)
+}
+
+// New creates the notifier of the target without replacing the one returned by Instance,
+// it is nil if the notification is disabled.
+func New(cfg *Config, be backend.CveBackend) notifier.Notifier {
+	switch cfg.Target {
+	case TargetBackend:
+		return backendNotifier{be: be}
+
+	case TargetWebhook:
+		return webhookNotifier{
+			cfg: &cfg.Webhook,
+			cli: NewWebhookClient(&cfg.Webhook),
+		}
+	}
+
+	return nil
+}
+
+func Instance() notifier.Notifier {
+	return instance
+}
+
+// Channels returns the channels by name
+func Channels() map[string]notifier.Channel {
+	return channels
+}
+
+func Subscriptions() []domain.Subscription {
+	return subscriptions
+}
+
+func versionsOf(n *domain.Notification) []string {
+	r := make([]string, len(n.AffectedVersion))
+	for i, v := range n.AffectedVersion {
+		r[i] = v.String()
+	}
+
+	return r
+}
+
+func toBackendBulletin(n *domain.Notification) backend.Bulletin {
+	return backend.Bulletin{
+		Identification:  n.Identification,
+		Component:       n.Component,
+		AffectedVersion: versionsOf(n),
+		Issues:          n.Issues,
+		File:            n.File,
+		PublishedAt:     n.PublishedAt,
+	}
+}
+
+// backendNotifier publishes the bulletin to the cve-security-notice-server
+type backendNotifier struct {
+	be backend.CveBackend
+}
+
+func (impl backendNotifier) Notify(n *domain.Notification) error {
+	b := toBackendBulletin(n)
+
+	return impl.be.PublishBulletin(&b)
+}
+
+type webhookPayload struct {
+	Identification  string   `json:"identification"`
+	Component       string   `json:"component"`
+	AffectedVersion []string `json:"affected_version"`
+	Issues          []string `json:"issues"`
+	File            string   `json:"file"`
+	PublishedAt     string   `json:"published_at"`
+}
+
+// webhookNotifier posts the bulletin in json to the webhook
+type webhookNotifier struct {
+	cfg *Webhook
+	cli *http.Client
+}
+
+func (impl webhookNotifier) Notify(n *domain.Notification) error {
+	return PostJSON(impl.cli, impl.cfg, webhookPayload{
+		Identification:  n.Identification,
+		Component:       n.Component,
+		AffectedVersion: versionsOf(n),
+		Issues:          n.Issues,
+		File:            n.File,
+		PublishedAt:     n.PublishedAt.Format(time.RFC3339),
+	})
+}
+
+// NewWebhookClient returns the client with the timeout of webhook
+func NewWebhookClient(cfg *Webhook) *http.Client {
+	return &http.Client{Timeout: time.Duration(cfg.Timeout) * time.Second}
+}
+
+// PostJSON posts v in json to the webhook, any 2xx response is a success
+func PostJSON(cli *http.Client, cfg *Webhook, v interface{}) error {
+	_, err := postJSON(cli, cfg, v)
+
+	return err
+}
+
+// postJSON returns the body of the 2xx response
+func postJSON(cli *http.Client, cfg *Webhook, v interface{}) ([]byte, error) {
+	body, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, cfg.URL, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	if cfg.Token != "" {
+		req.Header.Set(cfg.AuthHeader, cfg.Token)
+	}
+
+	resp, err := cli.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		data, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+
+		return nil, fmt.Errorf("webhook responds status %d: %s", resp.StatusCode, string(data))
+	}
+
+	return io.ReadAll(io.LimitReader(resp.Body, maxResponseSize))
+}
@@ -0,0 +1,108 @@
+package notifierimpl
+
+import (
+	"bytes"
+	"crypto/tls"
+	"fmt"
+	"mime"
+	"net"
+	"net/smtp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/opensourceways/defect-manager/defect/domain"
+)
+
+// smtpChannel sends the event as a plain text mail to the recipients
+type smtpChannel struct {
+	cfg *SMTP
+}
+
+func (ch smtpChannel) Send(e *domain.Event, recipients []string) error {
+	if len(recipients) == 0 {
+		return nil
+	}
+
+	addr := net.JoinHostPort(ch.cfg.Host, strconv.Itoa(ch.cfg.Port))
+	timeout := time.Duration(ch.cfg.Timeout) * time.Second
+
+	conn, err := net.DialTimeout("tcp", addr, timeout)
+	if err != nil {
+		return err
+	}
+
+	// the whole session must be finished in time
+	if err = conn.SetDeadline(time.Now().Add(timeout)); err != nil {
+		conn.Close()
+
+		return err
+	}
+
+	c, err := smtp.NewClient(conn, ch.cfg.Host)
+	if err != nil {
+		conn.Close()
+
+		return err
+	}
+	defer c.Close()
+
+	if ok, _ := c.Extension("STARTTLS"); ok {
+		if err = c.StartTLS(&tls.Config{ServerName: ch.cfg.Host}); err != nil {
+			return err
+		}
+	}
+
+	if ch.cfg.Username != "" {
+		auth := smtp.PlainAuth("", ch.cfg.Username, ch.cfg.Password, ch.cfg.Host)
+		if err = c.Auth(auth); err != nil {
+			return err
+		}
+	}
+
+	if err = c.Mail(ch.cfg.From); err != nil {
+		return err
+	}
+
+	for _, r := range recipients {
+		if err = c.Rcpt(r); err != nil {
+			return fmt.Errorf("recipient %s, %s", r, err.Error())
+		}
+	}
+
+	w, err := c.Data()
+	if err != nil {
+		return err
+	}
+
+	if _, err = w.Write(ch.message(e, recipients)); err != nil {
+		w.Close()
+
+		return err
+	}
+
+	if err = w.Close(); err != nil {
+		return err
+	}
+
+	return c.Quit()
+}
+
+func (ch smtpChannel) message(e *domain.Event, recipients []string) []byte {
+	var b bytes.Buffer
+
+	b.WriteString("From: " + ch.cfg.From + "\r\n")
+	b.WriteString("To: " + strings.Join(recipients, ", ") + "\r\n")
+	b.WriteString("Subject: " + mime.QEncoding.Encode("utf-8", e.Title) + "\r\n")
+	b.WriteString("Date: " + e.OccurredAt.Format(time.RFC1123Z) + "\r\n")
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+	b.WriteString("Content-Transfer-Encoding: 8bit\r\n")
+	b.WriteString("\r\n")
+
+	// the lines of mail end with CRLF
+	b.WriteString(strings.ReplaceAll(strings.ReplaceAll(e.Content, "\r\n", "\n"), "\n", "\r\n"))
+	b.WriteString("\r\n")
+
+	return b.Bytes()
+}
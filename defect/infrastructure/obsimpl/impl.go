@@ -3,23 +3,26 @@ package obsimpl
 import (
 	"bytes"
 	"fmt"
+	"time"
 
 	"github.com/huaweicloud/huaweicloud-sdk-go-obs/obs"
 
+	"github.com/opensourceways/defect-manager/metrics"
 	"github.com/opensourceways/defect-manager/utils"
 )
 
 var instance *obsImpl
 
-func Init(cfg *Config) error {
+func Init(cfg *Config, clock utils.Clock) error {
 	cli, err := obs.New(cfg.AccessKey, cfg.SecretKey, cfg.Endpoint)
 	if err != nil {
 		return err
 	}
 
 	instance = &obsImpl{
-		cfg: cfg,
-		cli: cli,
+		cfg:   cfg,
+		cli:   cli,
+		clock: clock,
 	}
 
 	return nil
@@ -29,18 +32,22 @@ func Instance() *obsImpl {
 	return instance
 }
 
+// obsImpl the files are uploaded to the directory of the day given by clock
 type obsImpl struct {
-	cfg *Config
-	cli *obs.ObsClient
+	cfg   *Config
+	cli   *obs.ObsClient
+	clock utils.Clock
 }
 
-func (impl obsImpl) Upload(fileName string, data []byte) error {
+func (impl obsImpl) Upload(fileName string, data []byte) (string, error) {
 	input := &obs.PutObjectInput{}
 	input.Bucket = impl.cfg.Bucket
-	input.Key = fmt.Sprintf("%s/%s/%s", impl.cfg.Directory, utils.Date(), fileName)
+	input.Key = fmt.Sprintf("%s/%s/%s", impl.cfg.Directory, impl.clock.Now().Format("2006-01-02"), fileName)
 	input.Body = bytes.NewReader(data)
 
+	start := time.Now()
 	_, err := impl.cli.PutObject(input)
+	metrics.OBSUploadDuration.WithLabelValues(metrics.Result(err)).Observe(metrics.Since(start))
 
-	return err
+	return input.Key, err
 }
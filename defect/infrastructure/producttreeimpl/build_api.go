@@ -0,0 +1,93 @@
+package producttreeimpl
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/opensourceways/server-common-lib/utils"
+)
+
+type buildAPIResult struct {
+	Code     int               `json:"code"`
+	Msg      string            `json:"msg"`
+	Revision string            `json:"revision"`
+	Result   []buildAPIPackage `json:"result"`
+}
+
+type buildAPIRevisionResult struct {
+	Code   int    `json:"code"`
+	Msg    string `json:"msg"`
+	Result string `json:"result"`
+}
+
+type buildAPIPackage struct {
+	Component string   `json:"component"`
+	RPMs      []string `json:"rpms"`
+}
+
+// buildAPISource requests GET <endpoint>/packages?version=<version> and
+// GET <endpoint>/packages/revision?version=<version>,
+// it stands in for OBS/EulerMaker until their apis are integrated.
+type buildAPISource struct {
+	cli utils.HttpClient
+	cfg *BuildAPI
+}
+
+func newBuildAPISource(cfg *BuildAPI) buildAPISource {
+	return buildAPISource{
+		cli: utils.NewHttpClient(3),
+		cfg: cfg,
+	}
+}
+
+func (s buildAPISource) revision(version string) (string, error) {
+	var res buildAPIRevisionResult
+	if err := s.get("/packages/revision", version, &res); err != nil {
+		return "", err
+	}
+
+	if res.Code != 0 {
+		return "", errors.New(res.Msg)
+	}
+
+	return res.Result, nil
+}
+
+func (s buildAPISource) rpms(version string) (map[string][]string, string, error) {
+	var res buildAPIResult
+	if err := s.get("/packages", version, &res); err != nil {
+		return nil, "", err
+	}
+
+	if res.Code != 0 {
+		return nil, "", errors.New(res.Msg)
+	}
+
+	r := make(map[string][]string, len(res.Result))
+	for _, v := range res.Result {
+		r[v.Component] = append(r[v.Component], v.RPMs...)
+	}
+
+	sortRPMs(r)
+
+	return r, res.Revision, nil
+}
+
+func (s buildAPISource) get(path, version string, result interface{}) error {
+	u := fmt.Sprintf("%s%s?version=%s", s.cfg.Endpoint, path, url.QueryEscape(version))
+
+	req, err := http.NewRequest(http.MethodGet, u, nil)
+	if err != nil {
+		return err
+	}
+
+	if s.cfg.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+s.cfg.Token)
+	}
+
+	_, err = s.cli.ForwardTo(req, result)
+
+	return err
+}
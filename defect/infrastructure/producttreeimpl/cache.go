@@ -0,0 +1,268 @@
+package producttreeimpl
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/opensourceways/defect-manager/defect/domain/dp"
+	"github.com/opensourceways/defect-manager/metrics"
+)
+
+const cacheFileSuffix = ".json"
+
+// versionRPMs is the rpms of each component in a version fetched from a source at a revision
+type versionRPMs struct {
+	Version   string              `json:"version"`
+	Source    string              `json:"source"`
+	Revision  string              `json:"revision"`
+	FetchedAt time.Time           `json:"fetched_at"`
+	RPMs      map[string][]string `json:"rpms"`
+}
+
+// resolve returns the source package of component, it matches the name case-insensitively
+// and then the name of binary rpms, such as python3-pip-wheel of python-pip.
+func (v *versionRPMs) resolve(component string) string {
+	if _, ok := v.RPMs[component]; ok {
+		return component
+	}
+
+	keys := make([]string, 0, len(v.RPMs))
+	for k := range v.RPMs {
+		keys = append(keys, k)
+	}
+
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		if strings.EqualFold(k, component) {
+			return k
+		}
+	}
+
+	for _, k := range keys {
+		for _, rpm := range v.RPMs[k] {
+			if n, err := dp.ParseNEVRA(rpm); err == nil && strings.EqualFold(n.Name, component) {
+				return k
+			}
+		}
+	}
+
+	return component
+}
+
+func (v *versionRPMs) isExpired(ttl time.Duration) bool {
+	return time.Since(v.FetchedAt) >= ttl
+}
+
+// rpmCache is safe for concurrent use, the rpms of a version are fetched
+// only once at a time, and are fetched again only when the revision of source changes.
+type rpmCache struct {
+	cfg     *Config
+	sources map[string]source
+
+	lock  sync.RWMutex
+	items map[string]*versionRPMs
+
+	fetchLock  sync.Mutex
+	fetchLocks map[string]*sync.Mutex
+}
+
+func newRPMCache(cfg *Config, sources map[string]source) *rpmCache {
+	c := &rpmCache{
+		cfg:        cfg,
+		sources:    sources,
+		items:      make(map[string]*versionRPMs),
+		fetchLocks: make(map[string]*sync.Mutex),
+	}
+
+	c.load()
+
+	return c
+}
+
+func (c *rpmCache) get(version string) (*versionRPMs, error) {
+	if item := c.fresh(version); item != nil {
+		return item, nil
+	}
+
+	lock := c.lockOf(version)
+	lock.Lock()
+	defer lock.Unlock()
+
+	// it may have been fetched by another goroutine while waiting for the lock
+	if item := c.fresh(version); item != nil {
+		return item, nil
+	}
+
+	name := c.cfg.sourceOf(version)
+	s := c.sources[name]
+
+	c.lock.RLock()
+	old := c.items[version]
+	c.lock.RUnlock()
+
+	if old != nil && old.Source == name {
+		if rev, err := s.revision(version); err == nil && rev != "" && rev == old.Revision {
+			item := *old
+			item.FetchedAt = time.Now()
+			c.save(&item)
+
+			return &item, nil
+		}
+	}
+
+	item, err := c.fetch(name, s, version)
+	if err != nil {
+		if old != nil && old.Source == name {
+			logrus.Warnf("fetch rpms of %s failed, use the stale one of %s, err:%s", version, old.Revision, err.Error())
+
+			return old, nil
+		}
+
+		return nil, err
+	}
+
+	c.save(item)
+
+	return item, nil
+}
+
+func (c *rpmCache) fresh(version string) *versionRPMs {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	item := c.items[version]
+	if item == nil || item.Source != c.cfg.sourceOf(version) || item.isExpired(c.cfg.Cache.ttl()) {
+		return nil
+	}
+
+	return item
+}
+
+func (c *rpmCache) lockOf(version string) *sync.Mutex {
+	c.fetchLock.Lock()
+	defer c.fetchLock.Unlock()
+
+	lock, ok := c.fetchLocks[version]
+	if !ok {
+		lock = new(sync.Mutex)
+		c.fetchLocks[version] = lock
+	}
+
+	return lock
+}
+
+func (c *rpmCache) fetch(name string, s source, version string) (*versionRPMs, error) {
+	maxCount := 3
+	interval := time.Second * 3
+
+	var err error
+	for count := 1; ; count++ {
+		var rpms map[string][]string
+		var rev string
+
+		start := time.Now()
+		rpms, rev, err = s.rpms(version)
+		metrics.ProductTreeFetchDuration.WithLabelValues(name, metrics.Result(err)).Observe(metrics.Since(start))
+
+		if err == nil {
+			return &versionRPMs{
+				Version:   version,
+				Source:    name,
+				Revision:  rev,
+				FetchedAt: time.Now(),
+				RPMs:      rpms,
+			}, nil
+		}
+
+		logrus.Errorf("get rpms of %s from %s error %s", version, name, err.Error())
+
+		if count >= maxCount {
+			break
+		}
+
+		time.Sleep(interval)
+	}
+
+	return nil, fmt.Errorf("fetch rpms of %s from %s failed after %d times, err:%s", version, name, maxCount, err.Error())
+}
+
+func (c *rpmCache) save(item *versionRPMs) {
+	c.lock.Lock()
+	c.items[item.Version] = item
+	c.lock.Unlock()
+
+	if c.cfg.Cache.Dir == "" {
+		return
+	}
+
+	if err := c.persist(item); err != nil {
+		logrus.Errorf("persist rpms of %s error %s", item.Version, err.Error())
+	}
+}
+
+// persist writes a temporary file and renames it, so the file is never half written
+func (c *rpmCache) persist(item *versionRPMs) error {
+	if err := os.MkdirAll(c.cfg.Cache.Dir, 0o755); err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(item)
+	if err != nil {
+		return err
+	}
+
+	file := filepath.Join(c.cfg.Cache.Dir, item.Version+cacheFileSuffix)
+	tmp := file + ".tmp"
+
+	if err = os.WriteFile(tmp, data, 0o644); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp, file)
+}
+
+func (c *rpmCache) load() {
+	if c.cfg.Cache.Dir == "" {
+		return
+	}
+
+	entries, err := os.ReadDir(c.cfg.Cache.Dir)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			logrus.Errorf("read cache dir of product tree error %s", err.Error())
+		}
+
+		return
+	}
+
+	for _, e := range entries {
+		if e.IsDir() || !strings.HasSuffix(e.Name(), cacheFileSuffix) {
+			continue
+		}
+
+		data, err := os.ReadFile(filepath.Join(c.cfg.Cache.Dir, e.Name()))
+		if err != nil {
+			logrus.Errorf("read cache %s error %s", e.Name(), err.Error())
+
+			continue
+		}
+
+		item := new(versionRPMs)
+		if err = json.Unmarshal(data, item); err != nil || item.Version == "" {
+			logrus.Errorf("invalid cache %s", e.Name())
+
+			continue
+		}
+
+		c.items[item.Version] = item
+	}
+}
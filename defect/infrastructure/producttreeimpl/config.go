@@ -1,13 +1,133 @@
 package producttreeimpl
 
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+const (
+	SourceGitee    = "gitee"
+	SourceLocalCSV = "local_csv"
+	SourceRepodata = "repodata"
+	SourceBuildAPI = "build_api"
+)
+
+// Config Source is the default source of product tree, Sources overrides it for the specified maintained version.
 type Config struct {
-	Token  string `json:"token"        required:"true"`
-	PkgRPM PkgRPM `json:"pkg_rpm"      required:"true"`
+	Token    string            `json:"token"`
+	PkgRPM   PkgRPM            `json:"pkg_rpm"`
+	LocalCSV LocalCSV          `json:"local_csv"`
+	Repodata Repodata          `json:"repodata"`
+	BuildAPI BuildAPI          `json:"build_api"`
+	Source   string            `json:"source"`
+	Sources  map[string]string `json:"sources"`
+	Cache    Cache             `json:"cache"`
+}
+
+// Cache TTL: the unit is minute, the revision of source is checked after it expires.
+// Dir: the directory to persist the cache, it is disabled if empty.
+type Cache struct {
+	TTL int    `json:"ttl"`
+	Dir string `json:"dir"`
+}
+
+func (c *Cache) ttl() time.Duration {
+	return time.Duration(c.TTL) * time.Minute
 }
 
 type PkgRPM struct {
-	Org        string `json:"org"         required:"true"`
-	Repo       string `json:"repo"        required:"repo"`
-	PathPrefix string `json:"path_prefix" required:"true"`
-	Branch     string `json:"branch"      required:"true"`
+	Org        string `json:"org"`
+	Repo       string `json:"repo"`
+	PathPrefix string `json:"path_prefix"`
+	Branch     string `json:"branch"`
+}
+
+// LocalCSV reads <dir>/<version>.csv which has the same format as the csv in gitee
+type LocalCSV struct {
+	Dir string `json:"dir"`
+}
+
+// Repodata URLs are the base urls of yum repositories, {version} in them is replaced with the maintained version,
+// such as https://repo.openeuler.org/{version}/source, a local directory is accepted too.
+type Repodata struct {
+	URLs []string `json:"urls"`
+}
+
+// BuildAPI is the stand-in of the api of OBS/EulerMaker which lists the rpms of each package built for a version
+type BuildAPI struct {
+	Endpoint string `json:"endpoint"`
+	Token    string `json:"token"`
+}
+
+func (c *Config) SetDefault() {
+	if c.Source == "" {
+		c.Source = SourceGitee
+	}
+
+	if c.Cache.TTL <= 0 {
+		c.Cache.TTL = 360
+	}
+}
+
+func (c *Config) Validate() error {
+	for _, name := range c.usedSources() {
+		if err := c.validateSource(name); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (c *Config) sourceOf(version string) string {
+	if v, ok := c.Sources[version]; ok {
+		return v
+	}
+
+	return c.Source
+}
+
+func (c *Config) usedSources() []string {
+	used := map[string]bool{c.Source: true}
+	for _, v := range c.Sources {
+		used[v] = true
+	}
+
+	r := make([]string, 0, len(used))
+	for k := range used {
+		r = append(r, k)
+	}
+
+	return r
+}
+
+func (c *Config) validateSource(name string) error {
+	switch name {
+	case SourceGitee:
+		p := &c.PkgRPM
+		if c.Token == "" || p.Org == "" || p.Repo == "" || p.PathPrefix == "" || p.Branch == "" {
+			return errors.New("missing token or pkg_rpm of product tree")
+		}
+
+	case SourceLocalCSV:
+		if c.LocalCSV.Dir == "" {
+			return errors.New("missing local_csv.dir of product tree")
+		}
+
+	case SourceRepodata:
+		if len(c.Repodata.URLs) == 0 {
+			return errors.New("missing repodata.urls of product tree")
+		}
+
+	case SourceBuildAPI:
+		if c.BuildAPI.Endpoint == "" {
+			return errors.New("missing build_api.endpoint of product tree")
+		}
+
+	default:
+		return fmt.Errorf("unknown source of product tree: %s", name)
+	}
+
+	return nil
 }
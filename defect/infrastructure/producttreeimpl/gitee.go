@@ -0,0 +1,55 @@
+package producttreeimpl
+
+import (
+	"encoding/base64"
+	"fmt"
+
+	sdk "github.com/opensourceways/go-gitee/gitee"
+	"github.com/opensourceways/robot-gitee-lib/client"
+)
+
+// giteeSource reads the csv in gitee, example:
+// https://gitee.com/openeuler_latest_rpms/obs_pkg_rpms_20230517/raw/master/latest_rpm/openEuler-22.03-LTS.csv
+type giteeSource struct {
+	cli client.Client
+	cfg *PkgRPM
+}
+
+func newGiteeSource(cfg *Config) giteeSource {
+	return giteeSource{
+		cli: client.NewClient(func() []byte {
+			return []byte(cfg.Token)
+		}),
+		cfg: &cfg.PkgRPM,
+	}
+}
+
+// revision is the sha of csv, gitee has no cheaper api to get it than fetching the content
+func (s giteeSource) revision(version string) (string, error) {
+	content, err := s.getContent(version)
+
+	return content.Sha, err
+}
+
+func (s giteeSource) rpms(version string) (map[string][]string, string, error) {
+	content, err := s.getContent(version)
+	if err != nil {
+		return nil, "", err
+	}
+
+	decodeContent, err := base64.StdEncoding.DecodeString(content.Content)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return parseCSV(decodeContent), content.Sha, nil
+}
+
+func (s giteeSource) getContent(version string) (sdk.Content, error) {
+	return s.cli.GetPathContent(
+		s.cfg.Org,
+		s.cfg.Repo,
+		fmt.Sprintf("%s%s.csv", s.cfg.PathPrefix, version),
+		s.cfg.Branch,
+	)
+}
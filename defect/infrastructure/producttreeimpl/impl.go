@@ -1,33 +1,27 @@
 package producttreeimpl
 
 import (
-	"bytes"
-	"encoding/base64"
 	"fmt"
-	"io"
+	"sort"
 	"strings"
-	"sync"
-	"sync/atomic"
-	"time"
 
-	"github.com/opensourceways/robot-gitee-lib/client"
 	"github.com/sirupsen/logrus"
 
 	"github.com/opensourceways/defect-manager/defect/domain"
 	"github.com/opensourceways/defect-manager/defect/domain/dp"
+	"github.com/opensourceways/defect-manager/defect/domain/producttree"
 )
 
 var instance *productTreeImpl
 
 func Init(cfg *Config) {
+	sources := make(map[string]source)
+	for _, name := range cfg.usedSources() {
+		sources[name] = newSource(name, cfg)
+	}
+
 	instance = &productTreeImpl{
-		cli: client.NewClient(func() []byte {
-			return []byte(cfg.Token)
-		}),
-		cfg:                 cfg,
-		rpmCache:            make(map[string][]byte),
-		rpmOfComponentCache: make(map[string]string),
-		taskCount:           0,
+		cache: newRPMCache(cfg, sources),
 	}
 }
 
@@ -36,156 +30,69 @@ func Instance() *productTreeImpl {
 }
 
 type productTreeImpl struct {
-	cli client.Client
-	cfg *Config
-
-	rpmCache            map[string][]byte
-	rpmOfComponentCache map[string]string
-
-	lock      sync.Mutex
-	wg        sync.WaitGroup
-	taskCount int64
-}
-
-func (impl *productTreeImpl) InitCache() {
-	atomic.AddInt64(&impl.taskCount, 1)
-
-	impl.initRPMCache()
-}
-
-//CleanCache use atomic to avoid cleaning when other tasks are being performed
-func (impl *productTreeImpl) CleanCache() {
-	atomic.AddInt64(&impl.taskCount, -1)
-	if atomic.LoadInt64(&impl.taskCount) == 0 {
-		impl.rpmCache = make(map[string][]byte)
-		impl.rpmOfComponentCache = make(map[string]string)
-	}
+	cache *rpmCache
 }
 
+// GetTree returns producttree.ComponentNotFoundError if the component has no rpm in any of the versions
 func (impl *productTreeImpl) GetTree(component string, versions []dp.SystemVersion) (domain.ProductTree, error) {
-	affectedRPM := make(map[string]string)
+	tree := make(map[dp.Arch][]domain.Product)
 	for _, v := range versions {
-		key := fmt.Sprintf("%s_%s", component, v.String())
-		rpm, ok := impl.rpmOfComponentCache[key]
-		if !ok {
-			rpm = impl.parseRPM(component, v.String())
-			impl.rpmOfComponentCache[key] = rpm
-		}
-
-		affectedRPM[v.String()] = rpm
-	}
-
-	return impl.buildTree(affectedRPM), nil
-}
-
-func (impl *productTreeImpl) parseRPM(component, version string) string {
-	// content of buf, example:
-	// https://gitee.com/openeuler_latest_rpms/obs_pkg_rpms_20230517/raw/master/latest_rpm/openEuler-22.03-LTS.csv
-	buf := bytes.NewBuffer(impl.rpmCache[version])
-
-	var rpmOfComponent string
-	for {
-		line, err := buf.ReadString('\n')
-		if err == io.EOF {
-			break
-		} else if err != nil {
-			logrus.Errorf("error reading %s from %s error %s", component, version, err.Error())
-			continue
-		}
-
-		split := strings.Split(line, ",")
-		if len(split) != 3 {
-			logrus.Errorf("the format of line error")
-			continue
+		item, err := impl.cache.get(v.String())
+		if err != nil {
+			return nil, fmt.Errorf("product tree of %s is unavailable, err:%s", v.String(), err.Error())
 		}
 
-		if split[1] == component {
-			rpmOfComponent = split[2]
-			break
+		if n := impl.addProducts(tree, v, item.RPMs[item.resolve(component)]); n == 0 {
+			return nil, producttree.ComponentNotFoundError{
+				Component: component,
+				Version:   v.String(),
+			}
 		}
 	}
 
-	return rpmOfComponent
+	return tree, nil
 }
 
-func (impl *productTreeImpl) initRPMCache() {
-	// use lock to avoid duplicate execution
-	impl.lock.Lock()
-	defer impl.lock.Unlock()
-	if len(impl.rpmCache) == len(dp.MaintainVersion) {
-		return
+func (impl *productTreeImpl) Components(version dp.SystemVersion) ([]string, error) {
+	item, err := impl.cache.get(version.String())
+	if err != nil {
+		return nil, err
 	}
 
-	for version := range dp.MaintainVersion {
-		v := version.String()
-		impl.wg.Add(1)
-		go func() {
-			impl.fetchRPMData(v)
-			impl.wg.Done()
-		}()
+	r := make([]string, 0, len(item.RPMs))
+	for k := range item.RPMs {
+		r = append(r, k)
 	}
 
-	impl.wg.Wait()
+	sort.Strings(r)
+
+	return r, nil
 }
 
-func (impl *productTreeImpl) fetchRPMData(version string) {
-	count := 0
-	maxCount := 10
-	interval := time.Second * 3
+func (impl *productTreeImpl) addProducts(tree domain.ProductTree, version dp.SystemVersion, rpms []string) int {
+	cpe := dp.CPEOfSystemVersion(version)
 
-	for {
-		if count > maxCount {
-			logrus.Errorf("fetch rpm data of %s failed after %d times", version, maxCount)
-			break
-		}
-		count++
-
-		content, err := impl.cli.GetPathContent(
-			impl.cfg.PkgRPM.Org,
-			impl.cfg.PkgRPM.Repo,
-			fmt.Sprintf("%s%s.csv", impl.cfg.PkgRPM.PathPrefix, version),
-			impl.cfg.PkgRPM.Branch,
-		)
+	n := 0
+	for _, rpm := range rpms {
+		// example of rpm: zbar-0.22-4.oe2203.src.rpm
+		nevra, err := dp.ParseNEVRA(rpm)
 		if err != nil {
-			logrus.Errorf("get content of %s error %s", version, err.Error())
-			time.Sleep(interval)
-			continue
-		}
+			logrus.Errorf("parse rpm %s of %s failed, err:%s", rpm, version.String(), err.Error())
 
-		decodeContent, err := base64.StdEncoding.DecodeString(content.Content)
-		if err != nil {
-			logrus.Errorf("base64decode content of %s error %s", version, err.Error())
-			time.Sleep(interval)
 			continue
 		}
 
-		impl.rpmCache[version] = decodeContent
-
-		break
-	}
-}
-
-func (impl *productTreeImpl) buildTree(affectedRPM map[string]string) domain.ProductTree {
-	tree := make(map[dp.Arch][]domain.Product)
-	for version, rpms := range affectedRPM {
-
-		rpmSlice := strings.Fields(rpms)
-		for _, rpm := range rpmSlice {
-			// example of rpm: zbar-0.22-4.oe2203.src.rpm
-			t := strings.Split(rpm, ".")
-			arch := t[len(t)-2]
-			productId := strings.Join(t[:len(t)-3], ".")
-
-			product := domain.Product{
-				ID:       productId,
-				CPE:      version,
-				FullName: rpm,
-			}
-
-			dpArch := dp.NewArch(arch)
-			tree[dpArch] = append(tree[dpArch], product)
+		product := domain.Product{
+			ID:       nevra.ID(),
+			CPE:      cpe,
+			FullName: strings.TrimSpace(rpm),
+			RPM:      nevra,
 		}
+
+		arch := dp.NewArch(nevra.Arch)
+		tree[arch] = append(tree[arch], product)
+		n++
 	}
 
-	return tree
+	return n
 }
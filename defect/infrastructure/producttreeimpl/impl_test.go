@@ -0,0 +1,56 @@
+package producttreeimpl
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/opensourceways/defect-manager/defect/domain/dp"
+	"github.com/opensourceways/defect-manager/defect/domain/producttree"
+)
+
+func TestGetTree(t *testing.T) {
+	dir := t.TempDir()
+	csv := "openEuler-22.03-LTS,zbar,zbar-0.22-4.oe2203.src.rpm zbar-0.22-4.oe2203.aarch64.rpm\n"
+	if err := os.WriteFile(filepath.Join(dir, "openEuler-22.03-LTS.csv"), []byte(csv), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := Config{
+		Source:   SourceLocalCSV,
+		LocalCSV: LocalCSV{Dir: dir},
+		Cache:    Cache{Dir: filepath.Join(dir, "cache")},
+	}
+	cfg.SetDefault()
+
+	Init(&cfg)
+
+	version, _ := dp.NewSystemVersion("openEuler-22.03-LTS")
+
+	tree, err := Instance().GetTree("zbar", []dp.SystemVersion{version})
+	if err != nil {
+		t.Fatalf("get tree failed, err:%s", err.Error())
+	}
+
+	products := tree[dp.NewArch("aarch64")]
+	if len(tree) != 2 || len(products) != 1 || products[0].ID != "zbar-0.22-4" {
+		t.Errorf("unexpected tree: %v", tree)
+	}
+
+	_, err = Instance().GetTree("kernel", []dp.SystemVersion{version})
+	if !errors.As(err, &producttree.ComponentNotFoundError{}) {
+		t.Errorf("expect component not found, got %v", err)
+	}
+
+	// the persisted cache is used after the source becomes unavailable
+	if err = os.Remove(filepath.Join(dir, "openEuler-22.03-LTS.csv")); err != nil {
+		t.Fatal(err)
+	}
+
+	Init(&cfg)
+
+	if _, err = Instance().GetTree("zbar", []dp.SystemVersion{version}); err != nil {
+		t.Errorf("get tree from persisted cache failed, err:%s", err.Error())
+	}
+}
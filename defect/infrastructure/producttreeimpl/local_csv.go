@@ -0,0 +1,39 @@
+package producttreeimpl
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+type localCSVSource struct {
+	cfg *LocalCSV
+}
+
+// revision is the modification time and size of the csv
+func (s localCSVSource) revision(version string) (string, error) {
+	info, err := os.Stat(s.path(version))
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("%d-%d", info.ModTime().UnixNano(), info.Size()), nil
+}
+
+func (s localCSVSource) rpms(version string) (map[string][]string, string, error) {
+	rev, err := s.revision(version)
+	if err != nil {
+		return nil, "", err
+	}
+
+	content, err := os.ReadFile(s.path(version))
+	if err != nil {
+		return nil, "", err
+	}
+
+	return parseCSV(content), rev, nil
+}
+
+func (s localCSVSource) path(version string) string {
+	return filepath.Join(s.cfg.Dir, version+".csv")
+}
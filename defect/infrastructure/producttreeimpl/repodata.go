@@ -0,0 +1,194 @@
+package producttreeimpl
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"errors"
+	"io"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/opensourceways/server-common-lib/utils"
+	"github.com/sirupsen/logrus"
+
+	"github.com/opensourceways/defect-manager/defect/domain/dp"
+)
+
+const (
+	repomdPath      = "repodata/repomd.xml"
+	repoDataPrimary = "primary"
+	versionHolder   = "{version}"
+)
+
+type repomd struct {
+	Data []struct {
+		Type     string `xml:"type,attr"`
+		Location struct {
+			Href string `xml:"href,attr"`
+		} `xml:"location"`
+	} `xml:"data"`
+}
+
+type primaryPackage struct {
+	Name     string `xml:"name"`
+	Arch     string `xml:"arch"`
+	Location struct {
+		Href string `xml:"href,attr"`
+	} `xml:"location"`
+	Format struct {
+		SourceRPM string `xml:"sourcerpm"`
+	} `xml:"format"`
+}
+
+// repodataSource reads the primary.xml(.gz) of yum repositories
+type repodataSource struct {
+	cli utils.HttpClient
+	cfg *Repodata
+}
+
+func newRepodataSource(cfg *Repodata) repodataSource {
+	return repodataSource{
+		cli: utils.NewHttpClient(3),
+		cfg: cfg,
+	}
+}
+
+// revision is the digest of repomd.xml of all the repositories, it changes whenever a repository is updated
+func (s repodataSource) revision(version string) (string, error) {
+	h := sha256.New()
+	for _, base := range s.baseURLs(version) {
+		content, err := s.read(base + "/" + repomdPath)
+		if err != nil {
+			return "", err
+		}
+
+		h.Write(content)
+	}
+
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+func (s repodataSource) rpms(version string) (map[string][]string, string, error) {
+	h := sha256.New()
+	r := make(map[string][]string)
+	for _, base := range s.baseURLs(version) {
+		md, err := s.read(base + "/" + repomdPath)
+		if err != nil {
+			return nil, "", err
+		}
+
+		h.Write(md)
+
+		if err := s.readRepo(base, md, r); err != nil {
+			return nil, "", err
+		}
+	}
+
+	sortRPMs(r)
+
+	return r, hex.EncodeToString(h.Sum(nil)), nil
+}
+
+func (s repodataSource) baseURLs(version string) []string {
+	r := make([]string, len(s.cfg.URLs))
+	for i, v := range s.cfg.URLs {
+		r[i] = strings.TrimSuffix(strings.ReplaceAll(v, versionHolder, version), "/")
+	}
+
+	return r
+}
+
+func (s repodataSource) readRepo(base string, content []byte, r map[string][]string) error {
+	var md repomd
+	if err := xml.Unmarshal(content, &md); err != nil {
+		return err
+	}
+
+	href := ""
+	for _, v := range md.Data {
+		if v.Type == repoDataPrimary {
+			href = v.Location.Href
+		}
+	}
+
+	if href == "" {
+		return errors.New("no primary data in " + base)
+	}
+
+	content, err := s.read(base + "/" + href)
+	if err != nil {
+		return err
+	}
+
+	var reader io.Reader = bytes.NewReader(content)
+	if strings.HasSuffix(href, ".gz") {
+		gz, err := gzip.NewReader(reader)
+		if err != nil {
+			return err
+		}
+		defer gz.Close()
+
+		reader = gz
+	}
+
+	return parsePrimary(reader, r)
+}
+
+func (s repodataSource) read(u string) ([]byte, error) {
+	if strings.HasPrefix(u, "http://") || strings.HasPrefix(u, "https://") {
+		req, err := http.NewRequest(http.MethodGet, u, nil)
+		if err != nil {
+			return nil, err
+		}
+
+		content, _, err := s.cli.Download(req)
+
+		return content, err
+	}
+
+	return os.ReadFile(filepath.FromSlash(strings.TrimPrefix(u, "file://")))
+}
+
+// parsePrimary decodes the packages one by one, because primary.xml of a full repository is large
+func parsePrimary(reader io.Reader, r map[string][]string) error {
+	decoder := xml.NewDecoder(reader)
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		start, ok := token.(xml.StartElement)
+		if !ok || start.Name.Local != "package" {
+			continue
+		}
+
+		var p primaryPackage
+		if err = decoder.DecodeElement(&p, &start); err != nil {
+			return err
+		}
+
+		component := p.Name
+		if p.Format.SourceRPM != "" {
+			nevra, err := dp.ParseNEVRA(p.Format.SourceRPM)
+			if err != nil {
+				logrus.Errorf("parse source rpm %s error %s", p.Format.SourceRPM, err.Error())
+
+				continue
+			}
+
+			component = nevra.Name
+		}
+
+		r[component] = append(r[component], path.Base(p.Location.Href))
+	}
+}
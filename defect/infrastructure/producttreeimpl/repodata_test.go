@@ -0,0 +1,34 @@
+package producttreeimpl
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestRepodataSource(t *testing.T) {
+	s := newRepodataSource(&Repodata{URLs: []string{"testdata/{version}"}})
+
+	r, rev, err := s.rpms("repo")
+	if err != nil {
+		t.Fatalf("read repodata failed, err:%s", err.Error())
+	}
+
+	want := map[string][]string{
+		"zbar": {
+			"zbar-0.22-4.oe2203.aarch64.rpm",
+			"zbar-0.22-4.oe2203.src.rpm",
+			"zbar-devel-0.22-4.oe2203.aarch64.rpm",
+		},
+		"python-pip": {
+			"python3-pip-wheel-20.2.2-1.oe2203.noarch.rpm",
+		},
+	}
+
+	if !reflect.DeepEqual(r, want) {
+		t.Errorf("got %v, want %v", r, want)
+	}
+
+	if v, err := s.revision("repo"); err != nil || v != rev {
+		t.Errorf("revision %s is different from the one of rpms %s", v, rev)
+	}
+}
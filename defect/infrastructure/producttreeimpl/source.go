@@ -0,0 +1,84 @@
+package producttreeimpl
+
+import (
+	"bufio"
+	"bytes"
+	"sort"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+)
+
+// source provides the rpms of each component in a maintained version
+type source interface {
+	// revision identifies the content of the version, the rpms are fetched again only when it changes
+	revision(version string) (string, error)
+
+	// rpms returns the file names of rpm keyed by the component, which is the name of source package,
+	// and the revision of them
+	rpms(version string) (map[string][]string, string, error)
+}
+
+func newSource(name string, cfg *Config) source {
+	switch name {
+	case SourceLocalCSV:
+		return localCSVSource{cfg: &cfg.LocalCSV}
+
+	case SourceRepodata:
+		return newRepodataSource(&cfg.Repodata)
+
+	case SourceBuildAPI:
+		return newBuildAPISource(&cfg.BuildAPI)
+
+	default:
+		return newGiteeSource(cfg)
+	}
+}
+
+// parseCSV parses the content of csv, example of line:
+// openEuler-22.03-LTS,zbar,zbar-0.22-4.oe2203.src.rpm zbar-0.22-4.oe2203.aarch64.rpm
+func parseCSV(content []byte) map[string][]string {
+	r := make(map[string][]string)
+
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+
+		split := strings.Split(line, ",")
+		if len(split) != 3 {
+			logrus.Errorf("the format of line error: %s", line)
+
+			continue
+		}
+
+		r[split[1]] = append(r[split[1]], strings.Fields(split[2])...)
+	}
+
+	if err := scanner.Err(); err != nil {
+		logrus.Errorf("read csv error %s", err.Error())
+	}
+
+	return r
+}
+
+// sortRPMs sorts and removes the duplicate rpms which exist in several repositories
+func sortRPMs(r map[string][]string) {
+	for k, v := range r {
+		sort.Strings(v)
+
+		n := 0
+		for i := range v {
+			if i == 0 || v[i] != v[i-1] {
+				v[n] = v[i]
+				n++
+			}
+		}
+
+		r[k] = v[:n]
+	}
+}
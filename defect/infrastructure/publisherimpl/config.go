@@ -0,0 +1,75 @@
+package publisherimpl
+
+import (
+	"errors"
+	"time"
+
+	"github.com/opensourceways/defect-manager/defect/domain"
+)
+
+// Config the domain events are saved and published only if Enabled.
+// Topics is the topic of each type of event, the others are published to Topic.
+// The pending events are published every Interval seconds, at most BatchSize at a time,
+// and the published ones are deleted after Retention days. An event is parked after
+// MaxAttempts failures, it is kept in the outbox and not published any more.
+type Config struct {
+	Enabled     bool              `json:"enabled"`
+	Topic       string            `json:"topic"`
+	Topics      map[string]string `json:"topics"`
+	BatchSize   int               `json:"batch_size"`
+	MaxAttempts int               `json:"max_attempts"`
+	Interval    int               `json:"interval"`
+	Retention   int               `json:"retention"`
+}
+
+func (c *Config) SetDefault() {
+	if c.Topic == "" {
+		c.Topic = "defect_manager_event"
+	}
+
+	if c.BatchSize <= 0 {
+		c.BatchSize = 100
+	}
+
+	if c.MaxAttempts <= 0 {
+		c.MaxAttempts = 10
+	}
+
+	if c.Interval <= 0 {
+		c.Interval = 5
+	}
+
+	if c.Retention <= 0 {
+		c.Retention = 7
+	}
+}
+
+func (c *Config) Validate() error {
+	for k := range c.Topics {
+		switch k {
+		case domain.DomainEventDefectAccepted, domain.DomainEventDefectRejected, domain.DomainEventDefectReopened,
+			domain.DomainEventBulletinGenerated, domain.DomainEventBulletinUploaded:
+
+		default:
+			return errors.New("unknown event of topic: " + k)
+		}
+	}
+
+	return nil
+}
+
+func (c *Config) topicOf(event string) string {
+	if v := c.Topics[event]; v != "" {
+		return v
+	}
+
+	return c.Topic
+}
+
+func (c *Config) PublishInterval() time.Duration {
+	return time.Duration(c.Interval) * time.Second
+}
+
+func (c *Config) RetentionPeriod() time.Duration {
+	return time.Duration(c.Retention) * 24 * time.Hour
+}
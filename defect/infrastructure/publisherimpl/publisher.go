@@ -0,0 +1,44 @@
+package publisherimpl
+
+import (
+	kafka "github.com/opensourceways/kafka-lib/agent"
+
+	"github.com/opensourceways/defect-manager/defect/domain"
+	"github.com/opensourceways/defect-manager/defect/domain/publisher"
+)
+
+const (
+	headerEventID       = "event_id"
+	headerEventType     = "event_type"
+	headerSchemaVersion = "schema_version"
+	headerKey           = "key"
+)
+
+var instance publisher.Publisher
+
+// Init expects the kafka agent has been initialized, the publisher is nil if it is disabled
+func Init(cfg *Config) {
+	if cfg.Enabled {
+		instance = publisherImpl{cfg: cfg, publish: kafka.Publish}
+	}
+}
+
+func Instance() publisher.Publisher {
+	return instance
+}
+
+type publisherImpl struct {
+	cfg     *Config
+	publish func(topic string, header map[string]string, msg []byte) error
+}
+
+func (impl publisherImpl) Publish(e *domain.DomainEvent) error {
+	header := map[string]string{
+		headerEventID:       e.ID,
+		headerEventType:     e.Type,
+		headerSchemaVersion: e.Version,
+		headerKey:           e.Key,
+	}
+
+	return impl.publish(impl.cfg.topicOf(e.Type), header, e.Body)
+}
@@ -0,0 +1,43 @@
+package publisherimpl
+
+import (
+	"testing"
+
+	"github.com/opensourceways/defect-manager/defect/domain"
+)
+
+func TestPublish(t *testing.T) {
+	cfg := &Config{Topics: map[string]string{domain.DomainEventBulletinUploaded: "bulletin_uploaded"}}
+	cfg.SetDefault()
+
+	if err := cfg.Validate(); err != nil {
+		t.Fatal(err)
+	}
+
+	got := map[string]map[string]string{}
+	impl := publisherImpl{cfg: cfg, publish: func(topic string, header map[string]string, msg []byte) error {
+		got[topic] = header
+
+		return nil
+	}}
+
+	for _, typ := range []string{domain.DomainEventBulletinUploaded, domain.DomainEventDefectAccepted} {
+		e := domain.DomainEvent{ID: typ + "-id", Type: typ, Version: "1", Key: "k"}
+		if err := impl.Publish(&e); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if h := got["bulletin_uploaded"]; h[headerEventType] != domain.DomainEventBulletinUploaded {
+		t.Errorf("unexpected header of bulletin_uploaded: %v", h)
+	}
+
+	if h := got[cfg.Topic]; h[headerEventID] != "defect.accepted-id" || h[headerSchemaVersion] != "1" {
+		t.Errorf("unexpected header of %s: %v", cfg.Topic, h)
+	}
+
+	cfg.Topics["defect.unknown"] = "x"
+	if cfg.Validate() == nil {
+		t.Error("expect error of unknown event")
+	}
+}
@@ -0,0 +1,67 @@
+package repositoryimpl
+
+import (
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/opensourceways/defect-manager/defect/domain"
+)
+
+// generationLockKey is the key of postgres advisory lock which serializes
+// the generations of the replicas, the keys must differ from the one of migrations.
+// relayLockKey and senderLockKey make one replica relay the events and send the notifications.
+const (
+	generationLockKey = 7301191
+	relayLockKey      = 7301192
+	senderLockKey     = 7301193
+)
+
+type bulletinImpl struct {
+	db dbimpl
+}
+
+func (impl bulletinImpl) SaveBulletin(sb *domain.SecurityBulletin, events ...domain.DomainEvent) error {
+	dos := toBulletinDOs(sb)
+	if len(dos) == 0 {
+		return nil
+	}
+
+	return impl.db.DB().Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&dos).Error; err != nil {
+			return err
+		}
+
+		return saveEvents(tx, events)
+	})
+}
+
+func (impl bulletinImpl) FindPublished(issues []domain.Issue) (map[string]string, error) {
+	if len(issues) == 0 {
+		return nil, nil
+	}
+
+	var dos []bulletinDO
+
+	err := impl.db.DB().Model(&bulletinDO{}).
+		Where(fieldsOfIssue+" IN ?", issueTuples(issues)).
+		Order(fieldID).
+		Find(&dos).Error
+	if err != nil {
+		return nil, err
+	}
+
+	r := make(map[string]string, len(dos))
+	for i := range dos {
+		r[dos[i].issue().Key()] = dos[i].Identification
+	}
+
+	return r, nil
+}
+
+// LockGeneration holds the session lock on a dedicated connection, so it is
+// released by postgres if the process exits without unlocking.
+func (impl bulletinImpl) LockGeneration() (func(), error) {
+	unlock, _, err := advisoryLock(impl.db, generationLockKey, false)
+
+	return unlock, err
+}
@@ -0,0 +1,43 @@
+package repositoryimpl
+
+import (
+	"time"
+
+	"github.com/opensourceways/defect-manager/defect/domain"
+)
+
+type bulletinDO struct {
+	ID             int       `gorm:"column:id;primaryKey;autoIncrement"`
+	Identification string    `gorm:"column:identification"`
+	Number         string    `gorm:"column:number"`
+	Org            string    `gorm:"column:org"`
+	Repo           string    `gorm:"column:repo"`
+	Component      string    `gorm:"column:component"`
+	PublishedAt    time.Time `gorm:"column:published_at"`
+}
+
+func (d bulletinDO) TableName() string {
+	return bulletinTableName
+}
+
+func (d *bulletinDO) issue() domain.Issue {
+	return domain.Issue{Org: d.Org, Repo: d.Repo, Number: d.Number}
+}
+
+func toBulletinDOs(sb *domain.SecurityBulletin) []bulletinDO {
+	dos := make([]bulletinDO, len(sb.Defects))
+	for k := range sb.Defects {
+		d := &sb.Defects[k]
+
+		dos[k] = bulletinDO{
+			Identification: sb.Identification,
+			Number:         d.Issue.Number,
+			Org:            d.Issue.Org,
+			Repo:           d.Issue.Repo,
+			Component:      d.Component,
+			PublishedAt:    sb.Date,
+		}
+	}
+
+	return dos
+}
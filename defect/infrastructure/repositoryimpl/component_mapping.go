@@ -0,0 +1,137 @@
+package repositoryimpl
+
+import (
+	"strings"
+	"time"
+
+	"gorm.io/gorm/clause"
+
+	"github.com/opensourceways/defect-manager/defect/domain"
+	"github.com/opensourceways/defect-manager/defect/domain/dp"
+	"github.com/opensourceways/defect-manager/defect/domain/repository"
+)
+
+type componentMappingDO struct {
+	ID            int       `gorm:"column:id;primaryKey;autoIncrement"`
+	Component     string    `gorm:"column:component"`
+	SystemVersion string    `gorm:"column:system_version"`
+	SourcePackage string    `gorm:"column:source_package"`
+	CreatedAt     time.Time `gorm:"column:created_at;<-:create"`
+	UpdatedAt     time.Time `gorm:"column:updated_at"`
+}
+
+func (d componentMappingDO) TableName() string {
+	return componentMappingTableName
+}
+
+func (d *componentMappingDO) toMapping() domain.ComponentMapping {
+	m := domain.ComponentMapping{
+		Component:     d.Component,
+		SourcePackage: d.SourcePackage,
+	}
+
+	if d.SystemVersion != "" {
+		m.SystemVersion, _ = dp.NewSystemVersion(d.SystemVersion)
+	}
+
+	return m
+}
+
+func versionOfMapping(v dp.SystemVersion) string {
+	if v == nil {
+		return ""
+	}
+
+	return v.String()
+}
+
+// componentMappingImpl looks up the table first, and then the mappings in config,
+// the mapping of the specified version takes precedence over the one for all the versions.
+type componentMappingImpl struct {
+	db       dbimpl
+	mappings []ComponentMapping
+}
+
+func (impl componentMappingImpl) FindSourcePackage(component string, version dp.SystemVersion) (string, error) {
+	var dos []componentMappingDO
+
+	err := impl.db.DB().Model(&componentMappingDO{}).
+		Where("lower(component) = lower(?) AND system_version IN ?", component, []string{version.String(), ""}).
+		Order("system_version DESC").
+		Limit(1).
+		Find(&dos).Error
+	if err != nil {
+		return "", err
+	}
+
+	if len(dos) > 0 {
+		return dos[0].SourcePackage, nil
+	}
+
+	r := ""
+	for _, v := range impl.mappings {
+		if !strings.EqualFold(v.Component, component) {
+			continue
+		}
+
+		if v.SystemVersion == version.String() {
+			return v.SourcePackage, nil
+		}
+
+		if v.SystemVersion == "" {
+			r = v.SourcePackage
+		}
+	}
+
+	return r, nil
+}
+
+func (impl componentMappingImpl) FindMappings() ([]domain.ComponentMapping, error) {
+	var dos []componentMappingDO
+
+	err := impl.db.DB().Model(&componentMappingDO{}).
+		Order("lower(component), system_version").
+		Find(&dos).Error
+	if err != nil {
+		return nil, err
+	}
+
+	r := make([]domain.ComponentMapping, len(dos))
+	for i := range dos {
+		r[i] = dos[i].toMapping()
+	}
+
+	return r, nil
+}
+
+func (impl componentMappingImpl) SaveMapping(m *domain.ComponentMapping) error {
+	now := time.Now()
+	do := componentMappingDO{
+		Component:     m.Component,
+		SystemVersion: versionOfMapping(m.SystemVersion),
+		SourcePackage: m.SourcePackage,
+		CreatedAt:     now,
+		UpdatedAt:     now,
+	}
+
+	// the conflict target is the expression of the unique index
+	return impl.db.DB().Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "lower(component)", Raw: true}, {Name: "system_version"}},
+		DoUpdates: clause.AssignmentColumns([]string{"source_package", fieldUpdatedAt}),
+	}).Create(&do).Error
+}
+
+func (impl componentMappingImpl) DeleteMapping(component string, version dp.SystemVersion) error {
+	r := impl.db.DB().
+		Where("lower(component) = lower(?) AND system_version = ?", component, versionOfMapping(version)).
+		Delete(&componentMappingDO{})
+	if r.Error != nil {
+		return r.Error
+	}
+
+	if r.RowsAffected == 0 {
+		return repository.ErrMappingNotFound
+	}
+
+	return nil
+}
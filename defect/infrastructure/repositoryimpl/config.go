@@ -1,9 +1,72 @@
 package repositoryimpl
 
+import "errors"
+
 type Config struct {
-	Table Table `json:"table" required:"true"`
+	Table             Table              `json:"table"              required:"true"`
+	ComponentMappings []ComponentMapping `json:"component_mappings"`
 }
 
 type Table struct {
-	Defect string `json:"defect_manager" required:"true"`
+	Defect           string `json:"defect_manager"    required:"true"`
+	ComponentMapping string `json:"component_mapping"`
+	Version          string `json:"version"`
+	Reference        string `json:"reference"`
+	Bulletin         string `json:"bulletin"`
+	Notification     string `json:"notification"`
+	Outbox           string `json:"outbox"`
+	Setting          string `json:"setting"`
+	Generation       string `json:"generation"`
+}
+
+// ComponentMapping maps the component of issue to the source package in product tree,
+// SystemVersion is optional and the mapping applies to all the versions if it is empty.
+type ComponentMapping struct {
+	Component     string `json:"component"`
+	SystemVersion string `json:"system_version"`
+	SourcePackage string `json:"source_package"`
+}
+
+func (c *Config) SetDefault() {
+	if c.Table.ComponentMapping == "" {
+		c.Table.ComponentMapping = "component_mapping"
+	}
+
+	if c.Table.Version == "" {
+		c.Table.Version = "maintained_version"
+	}
+
+	if c.Table.Reference == "" {
+		c.Table.Reference = "defect_reference"
+	}
+
+	if c.Table.Bulletin == "" {
+		c.Table.Bulletin = "defect_bulletin"
+	}
+
+	if c.Table.Notification == "" {
+		c.Table.Notification = "bulletin_notification"
+	}
+
+	if c.Table.Outbox == "" {
+		c.Table.Outbox = "event_outbox"
+	}
+
+	if c.Table.Setting == "" {
+		c.Table.Setting = "defect_setting"
+	}
+
+	if c.Table.Generation == "" {
+		c.Table.Generation = "bulletin_generation"
+	}
+}
+
+func (c *Config) Validate() error {
+	for _, v := range c.ComponentMappings {
+		if v.Component == "" || v.SourcePackage == "" {
+			return errors.New("missing component or source_package of component mapping")
+		}
+	}
+
+	return nil
 }
@@ -2,17 +2,18 @@ package repositoryimpl
 
 import (
 	postgres "github.com/opensourceways/server-common-lib/postgre"
+	"gorm.io/gorm"
 )
 
 type dbimpl interface {
 	GetRecord(filter, result interface{}) error
 	Insert(result interface{}) error
+	FirstOrCreate(filter, result interface{}) error
 	UpdateRecord(filter, update interface{}) error
 	GetRecords(
 		filter []postgres.ColumnFilter, result interface{}, p postgres.Pagination, sort []postgres.SortByColumn,
 	) error
-
-	AutoMigrate(dst interface{}) error
+	DB() *gorm.DB
 
 	IsRowNotFound(error) bool
 	IsRowExists(error) bool
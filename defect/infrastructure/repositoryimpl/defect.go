@@ -1,39 +1,163 @@
 package repositoryimpl
 
 import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
 	postgres "github.com/opensourceways/server-common-lib/postgre"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 
 	"github.com/opensourceways/defect-manager/defect/domain"
+	"github.com/opensourceways/defect-manager/defect/domain/dp"
 	"github.com/opensourceways/defect-manager/defect/domain/repository"
 )
 
 const (
-	fieldOrg       = "org"
-	fieldNumber    = "number"
-	fieldStatus    = "status"
-	fieldCreatedAt = "created_at"
+	fieldID              = "id"
+	fieldOrg             = "org"
+	fieldRepo            = "repo"
+	fieldNumber          = "number"
+	fieldStatus          = "status"
+	fieldComponent       = "component"
+	fieldSeverityLevel   = "severity_level"
+	fieldFixedVersion    = "fixed_version"
+	fieldCVSSVector      = "cvss_vector"
+	fieldCVSSScore       = "cvss_score"
+	fieldSystemVersion   = "system_version"
+	fieldAffectedVersion = "affected_version"
+	fieldReleases        = "releases"
+	fieldDescription     = "description"
+	fieldCreatedAt       = "created_at"
+	fieldUpdatedAt       = "updated_at"
+
+	fieldsOfIssue = "(" + fieldOrg + ", " + fieldRepo + ", " + fieldNumber + ")"
 )
 
-var instance repository.DefectRepository
+// approvalFields is overwritten when the issue is approved again
+var approvalFields = []string{
+	"title",
+	fieldStatus,
+	"kernel",
+	fieldComponent,
+	"component_version",
+	fieldSystemVersion,
+	fieldDescription,
+	"reference_url",
+	"guidance_url",
+	"influence",
+	fieldAffectedVersion,
+	"abi",
+	fieldUpdatedAt,
+}
+
+// updatableFields is overwritten by the admins, the fixed version and cvss can be set by them only
+var updatableFields = append([]string{
+	fieldFixedVersion,
+	fieldSeverityLevel,
+	fieldCVSSVector,
+	fieldCVSSScore,
+}, approvalFields...)
 
-var defectTableName string
+var sortableFields = map[string]string{
+	repository.SortByCreatedAt:     fieldCreatedAt,
+	repository.SortByUpdatedAt:     fieldUpdatedAt,
+	repository.SortByNumber:        fieldNumber,
+	repository.SortByComponent:     fieldComponent,
+	repository.SortBySeverityLevel: fieldSeverityLevel,
+}
+
+var (
+	instance           repository.DefectRepository
+	mappingInstance    repository.ComponentMappingRepository
+	versionInstance    repository.VersionRepository
+	bulletinInstance   repository.BulletinRepository
+	noticeInstance     repository.NotificationRepository
+	outboxInstance     repository.OutboxRepository
+	settingInstance    repository.SettingRepository
+	generationInstance repository.GenerationRepository
+)
 
-func Init(cfg *Config) error {
+var (
+	defectTableName           string
+	componentMappingTableName string
+	versionTableName          string
+	referenceTableName        string
+	bulletinTableName         string
+	notificationTableName     string
+	outboxTableName           string
+	settingTableName          string
+	generationTableName       string
+)
+
+// Init expects the tables have been created by the migrations
+func Init(cfg *Config) {
 	defectTableName = cfg.Table.Defect
+	componentMappingTableName = cfg.Table.ComponentMapping
+	versionTableName = cfg.Table.Version
+	referenceTableName = cfg.Table.Reference
+	bulletinTableName = cfg.Table.Bulletin
+	notificationTableName = cfg.Table.Notification
+	outboxTableName = cfg.Table.Outbox
+	settingTableName = cfg.Table.Setting
+	generationTableName = cfg.Table.Generation
+
+	instance = defectImpl{postgres.NewDBTable(cfg.Table.Defect)}
+
+	mappingInstance = componentMappingImpl{
+		db:       postgres.NewDBTable(cfg.Table.ComponentMapping),
+		mappings: cfg.ComponentMappings,
+	}
+
+	versionInstance = versionImpl{postgres.NewDBTable(cfg.Table.Version)}
 
-	impl := defectImpl{postgres.NewDBTable(cfg.Table.Defect)}
+	bulletinInstance = bulletinImpl{postgres.NewDBTable(cfg.Table.Bulletin)}
 
-	instance = impl
+	noticeInstance = notificationImpl{postgres.NewDBTable(cfg.Table.Notification)}
 
-	err := impl.db.AutoMigrate(defectDO{})
+	outboxInstance = outboxImpl{postgres.NewDBTable(cfg.Table.Outbox)}
 
-	return err
+	settingInstance = settingImpl{postgres.NewDBTable(cfg.Table.Setting)}
+
+	generationInstance = generationImpl{postgres.NewDBTable(cfg.Table.Generation)}
 }
 
 func Instance() repository.DefectRepository {
 	return instance
 }
 
+func ComponentMappingInstance() repository.ComponentMappingRepository {
+	return mappingInstance
+}
+
+func VersionInstance() repository.VersionRepository {
+	return versionInstance
+}
+
+func BulletinInstance() repository.BulletinRepository {
+	return bulletinInstance
+}
+
+func NotificationInstance() repository.NotificationRepository {
+	return noticeInstance
+}
+
+func OutboxInstance() repository.OutboxRepository {
+	return outboxInstance
+}
+
+func SettingInstance() repository.SettingRepository {
+	return settingInstance
+}
+
+func GenerationInstance() repository.GenerationRepository {
+	return generationInstance
+}
+
 type defectImpl struct {
 	db dbimpl
 }
@@ -42,6 +166,7 @@ func (impl defectImpl) HasDefect(issue *domain.Issue) (bool, error) {
 	filter := defectDO{
 		Number: issue.Number,
 		Org:    issue.Org,
+		Repo:   issue.Repo,
 	}
 
 	var result defectDO
@@ -57,52 +182,443 @@ func (impl defectImpl) HasDefect(issue *domain.Issue) (bool, error) {
 	return true, nil
 }
 
-func (impl defectImpl) AddDefect(defect *domain.Defect) error {
+// SaveDefect inserts the defect or updates it if the issue exists. The fixed version and cvss
+// set by the admins are kept unless the defect carries them, the references are added to the existing ones.
+func (impl defectImpl) SaveDefect(defect *domain.Defect, events ...domain.DomainEvent) (inserted bool, err error) {
 	do := impl.toDefectDO(defect)
-	return impl.db.Insert(&do)
+
+	err = impl.db.DB().Transaction(func(tx *gorm.DB) error {
+		err := tx.Clauses(clause.OnConflict{
+			Columns: []clause.Column{
+				{Name: fieldOrg},
+				{Name: fieldRepo},
+				{Name: fieldNumber},
+			},
+			DoUpdates: approvalUpdates(),
+		}).Create(&do).Error
+		if err != nil {
+			return err
+		}
+
+		// the row updated by the upsert is locked by this transaction, so its xmax is not 0
+		err = tx.Model(&defectDO{}).Select("xmax = 0").Where(fieldID+" = ?", do.ID).Row().Scan(&inserted)
+		if err != nil {
+			return err
+		}
+
+		if len(defect.References) > 0 {
+			refs := toReferencesDO(do.ID, defect.References)
+			if err = tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&refs).Error; err != nil {
+				return err
+			}
+		}
+
+		if !inserted {
+			return nil
+		}
+
+		return saveEvents(tx, events)
+	})
+
+	return
+}
+
+// approvalUpdates the severity level is derived from the kept cvss if the approval has only the level
+func approvalUpdates() clause.Set {
+	existing := func(field string) string {
+		return defectTableName + "." + field
+	}
+
+	noVector := "EXCLUDED." + fieldCVSSVector + " = ''"
+
+	return append(
+		clause.AssignmentColumns(approvalFields),
+		clause.Assignment{
+			Column: clause.Column{Name: fieldFixedVersion},
+			Value: gorm.Expr(fmt.Sprintf(
+				"COALESCE(NULLIF(EXCLUDED.%s, ''), %s)", fieldFixedVersion, existing(fieldFixedVersion),
+			)),
+		},
+		clause.Assignment{
+			Column: clause.Column{Name: fieldSeverityLevel},
+			Value: gorm.Expr(fmt.Sprintf(
+				"CASE WHEN %s AND %s <> '' THEN %s ELSE EXCLUDED.%s END",
+				noVector, existing(fieldCVSSVector), existing(fieldSeverityLevel), fieldSeverityLevel,
+			)),
+		},
+		clause.Assignment{
+			Column: clause.Column{Name: fieldCVSSVector},
+			Value: gorm.Expr(fmt.Sprintf(
+				"CASE WHEN %s THEN %s ELSE EXCLUDED.%s END", noVector, existing(fieldCVSSVector), fieldCVSSVector,
+			)),
+		},
+		clause.Assignment{
+			Column: clause.Column{Name: fieldCVSSScore},
+			Value: gorm.Expr(fmt.Sprintf(
+				"CASE WHEN %s THEN %s ELSE EXCLUDED.%s END", noVector, existing(fieldCVSSScore), fieldCVSSScore,
+			)),
+		},
+	)
 }
 
-func (impl defectImpl) SaveDefect(defect *domain.Defect) error {
+// UpdateDefect overwrites all the fields and replaces the references
+func (impl defectImpl) UpdateDefect(defect *domain.Defect) error {
 	do := impl.toDefectDO(defect)
+
 	filter := defectDO{
 		Number: defect.Issue.Number,
 		Org:    defect.Issue.Org,
+		Repo:   defect.Issue.Repo,
+	}
+
+	return impl.db.DB().Transaction(func(tx *gorm.DB) error {
+		var existing defectDO
+		if err := tx.Select(fieldID).Where(&filter).Take(&existing).Error; err != nil {
+			if impl.db.IsRowNotFound(err) {
+				err = repository.ErrDefectNotFound
+			}
+
+			return err
+		}
+
+		err := tx.Model(&defectDO{}).Where(fieldID+" = ?", existing.ID).
+			Select(updatableFields).Updates(&do).Error
+		if err != nil {
+			return err
+		}
+
+		if err = tx.Where(fieldDefectID+" = ?", existing.ID).Delete(&referenceDO{}).Error; err != nil {
+			return err
+		}
+
+		if len(defect.References) == 0 {
+			return nil
+		}
+
+		refs := toReferencesDO(existing.ID, defect.References)
+
+		return tx.Create(&refs).Error
+	})
+}
+
+func (impl defectImpl) SaveReleases(issue *domain.Issue, releases []domain.Release) error {
+	v, err := toReleasesDO(releases)
+	if err != nil {
+		return err
+	}
+
+	filter := defectDO{
+		Number: issue.Number,
+		Org:    issue.Org,
+		Repo:   issue.Repo,
+	}
+
+	query := impl.db.DB().Model(&defectDO{}).Where(&filter).
+		UpdateColumn(fieldReleases, gorm.Expr("?::jsonb", v))
+	if err = query.Error; err != nil {
+		return err
+	}
+
+	if query.RowsAffected == 0 {
+		return repository.ErrDefectNotFound
+	}
+
+	return nil
+}
+
+func (impl defectImpl) FindDefect(issue *domain.Issue) (domain.Defect, error) {
+	filter := defectDO{
+		Number: issue.Number,
+		Org:    issue.Org,
+		Repo:   issue.Repo,
+	}
+
+	var result defectDO
+	if err := impl.db.GetRecord(&filter, &result); err != nil {
+		if impl.db.IsRowNotFound(err) {
+			err = repository.ErrDefectNotFound
+		}
+
+		return domain.Defect{}, err
 	}
 
-	return impl.db.UpdateRecord(filter, &do)
+	ds, err := impl.toDefects([]defectDO{result})
+	if err != nil {
+		return domain.Defect{}, err
+	}
+
+	return ds[0], nil
 }
 
 func (impl defectImpl) FindDefects(opt repository.OptToFindDefects) (ds domain.Defects, err error) {
-	var filter []postgres.ColumnFilter
-	filter = append(filter, postgres.NewGreaterFilter(fieldCreatedAt, opt.BeginTime))
+	query := impl.filter(opt)
 
-	if len(opt.Number) > 0 {
-		filter = append(filter, postgres.NewInFilter(fieldNumber, opt.Number))
+	column, ok := sortableFields[opt.SortBy]
+	if !ok {
+		column = fieldCreatedAt
 	}
 
-	if opt.Org != "" {
-		filter = append(filter, postgres.NewEqualFilter(fieldOrg, opt.Org))
+	// id breaks the ties so that the order is stable between pages
+	query = query.Order(clause.OrderByColumn{Column: clause.Column{Name: column}, Desc: !opt.Ascend}).
+		Order(clause.OrderByColumn{Column: clause.Column{Name: fieldID}, Desc: !opt.Ascend})
+
+	if opt.CountPerPage > 0 {
+		query = query.Limit(opt.CountPerPage)
+
+		if opt.PageNum > 1 {
+			query = query.Offset((opt.PageNum - 1) * opt.CountPerPage)
+		}
 	}
 
-	if opt.Status != nil {
-		filter = append(filter, postgres.NewEqualFilter(fieldStatus, opt.Status.String()))
+	var dos []defectDO
+	if err = query.Find(&dos).Error; err != nil {
+		return
+	}
+
+	return impl.toDefects(dos)
+}
+
+func (impl defectImpl) FindDefectsPage(opt repository.OptToFindDefects) (
+	page repository.DefectsPage, err error,
+) {
+	if opt.CountPerPage <= 0 {
+		err = errors.New("count per page is required")
+
+		return
 	}
 
+	keyset := opt.SortBy == "" || opt.SortBy == repository.SortByCreatedAt
+	if opt.Cursor != "" && !keyset {
+		err = errors.New("cursor can only be used when sorting by created_at")
+
+		return
+	}
+
+	column, ok := sortableFields[opt.SortBy]
+	if !ok {
+		column = fieldCreatedAt
+	}
+
+	query := impl.filter(opt)
+
+	if opt.Cursor != "" {
+		var c cursor
+		if c, err = decodeCursor(opt.Cursor); err != nil {
+			return
+		}
+
+		op := "<"
+		if opt.Ascend {
+			op = ">"
+		}
+
+		query = query.Where(
+			"("+fieldCreatedAt+", "+fieldID+") "+op+" (?, ?)", c.createdAt, c.id,
+		)
+	} else if opt.PageNum > 1 {
+		query = query.Offset((opt.PageNum - 1) * opt.CountPerPage)
+	}
+
+	// fetch one more to know whether there is next page
 	var dos []defectDO
-	err = impl.db.GetRecords(
-		filter, &dos,
-		postgres.Pagination{},
-		[]postgres.SortByColumn{
-			{Column: fieldCreatedAt},
-		})
+	err = query.
+		Order(clause.OrderByColumn{Column: clause.Column{Name: column}, Desc: !opt.Ascend}).
+		Order(clause.OrderByColumn{Column: clause.Column{Name: fieldID}, Desc: !opt.Ascend}).
+		Limit(opt.CountPerPage + 1).
+		Find(&dos).Error
 	if err != nil {
 		return
 	}
 
-	ds = make(domain.Defects, len(dos))
-	for k, d := range dos {
-		ds[k] = d.toDefect()
+	if len(dos) > opt.CountPerPage {
+		dos = dos[:opt.CountPerPage]
+
+		if keyset {
+			last := &dos[len(dos)-1]
+			page.NextCursor = encodeCursor(cursor{createdAt: last.CreatedAt, id: last.ID})
+		}
 	}
 
+	page.Defects, err = impl.toDefects(dos)
+
 	return
 }
+
+// toDefects loads the references of the defects at once
+func (impl defectImpl) toDefects(dos []defectDO) (domain.Defects, error) {
+	ds := make(domain.Defects, len(dos))
+	if len(dos) == 0 {
+		return ds, nil
+	}
+
+	ids := make([]int, len(dos))
+	for k := range dos {
+		ids[k] = dos[k].ID
+	}
+
+	var refs []referenceDO
+	err := impl.db.DB().Model(&referenceDO{}).
+		Where(fieldDefectID+" IN ?", ids).
+		Order(fieldID).
+		Find(&refs).Error
+	if err != nil {
+		return nil, err
+	}
+
+	refsOfDefect := make(map[int][]dp.Reference, len(dos))
+	for _, v := range refs {
+		if ref, err := v.toReference(); err == nil {
+			refsOfDefect[v.DefectID] = append(refsOfDefect[v.DefectID], ref)
+		}
+	}
+
+	for k := range dos {
+		ds[k] = dos[k].toDefect()
+		ds[k].References = refsOfDefect[dos[k].ID]
+	}
+
+	return ds, nil
+}
+
+func (impl defectImpl) CountDefects(opt repository.OptToFindDefects) (int, error) {
+	var total int64
+	err := impl.filter(opt).Count(&total).Error
+
+	return int(total), err
+}
+
+func (impl defectImpl) DeleteDefect(issue *domain.Issue) error {
+	filter := defectDO{
+		Number: issue.Number,
+		Org:    issue.Org,
+		Repo:   issue.Repo,
+	}
+
+	query := impl.db.DB().Where(&filter).Delete(&defectDO{})
+	if err := query.Error; err != nil {
+		return err
+	}
+
+	if query.RowsAffected == 0 {
+		return repository.ErrDefectNotFound
+	}
+
+	return nil
+}
+
+func (impl defectImpl) filter(opt repository.OptToFindDefects) *gorm.DB {
+	query := impl.db.DB().Model(&defectDO{})
+
+	if !opt.BeginTime.IsZero() {
+		query = query.Where(fieldCreatedAt+" >= ?", opt.BeginTime)
+	}
+
+	if !opt.EndTime.IsZero() {
+		query = query.Where(fieldCreatedAt+" < ?", opt.EndTime)
+	}
+
+	if len(opt.Issues) > 0 {
+		query = query.Where(fieldsOfIssue+" IN ?", issueTuples(opt.Issues))
+	}
+
+	if len(opt.Numbers) > 0 {
+		query = query.Where(fieldNumber+" IN ?", opt.Numbers)
+	}
+
+	if opt.Org != "" {
+		query = query.Where(fieldOrg+" = ?", opt.Org)
+	}
+
+	if opt.Repo != "" {
+		query = query.Where(fieldRepo+" = ?", opt.Repo)
+	}
+
+	if opt.Status != nil {
+		query = query.Where(fieldStatus+" = ?", opt.Status.String())
+	}
+
+	if opt.Component != "" {
+		query = query.Where(fieldComponent+" = ?", opt.Component)
+	}
+
+	if opt.SeverityLevel != nil {
+		query = query.Where(fieldSeverityLevel+" = ?", opt.SeverityLevel.String())
+	}
+
+	if opt.SystemVersion != nil {
+		query = query.Where(fieldSystemVersion+" = ?", opt.SystemVersion.String())
+	}
+
+	if opt.AffectedVersion != nil {
+		query = query.Where("? = ANY("+fieldAffectedVersion+")", opt.AffectedVersion.String())
+	}
+
+	if opt.Reference != "" {
+		query = query.Where(fieldID+" IN (?)", impl.db.DB().Model(&referenceDO{}).
+			Select(fieldDefectID).
+			Where("lower("+fieldRefID+") = lower(?)", opt.Reference),
+		)
+	}
+
+	if opt.Keyword != "" {
+		query = query.Where(fieldDescription+` ILIKE ? ESCAPE '\'`, "%"+escapeLike(opt.Keyword)+"%")
+	}
+
+	return query
+}
+
+var likeReplacer = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// escapeLike escapes the wildcards so that the keyword is matched literally
+func escapeLike(s string) string {
+	return likeReplacer.Replace(s)
+}
+
+// cursor is the position of the last defect of a page in the order of (created_at, id)
+type cursor struct {
+	createdAt time.Time
+	id        int
+}
+
+func encodeCursor(c cursor) string {
+	v := strconv.FormatInt(c.createdAt.UnixNano(), 10) + "_" + strconv.Itoa(c.id)
+
+	return base64.RawURLEncoding.EncodeToString([]byte(v))
+}
+
+func decodeCursor(s string) (c cursor, err error) {
+	invalid := errors.New("invalid cursor")
+
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return c, invalid
+	}
+
+	items := strings.Split(string(b), "_")
+	if len(items) != 2 {
+		return c, invalid
+	}
+
+	nano, err := strconv.ParseInt(items[0], 10, 64)
+	if err != nil {
+		return c, invalid
+	}
+
+	if c.id, err = strconv.Atoi(items[1]); err != nil || c.id <= 0 {
+		return c, invalid
+	}
+
+	c.createdAt = time.Unix(0, nano)
+
+	return c, nil
+}
+
+// issueTuples is the values of fieldsOfIssue for the IN condition
+func issueTuples(issues []domain.Issue) [][]interface{} {
+	r := make([][]interface{}, len(issues))
+	for i := range issues {
+		r[i] = []interface{}{issues[i].Org, issues[i].Repo, issues[i].Number}
+	}
+
+	return r
+}
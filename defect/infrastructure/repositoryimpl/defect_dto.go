@@ -1,6 +1,7 @@
 package repositoryimpl
 
 import (
+	"encoding/json"
 	"time"
 
 	"github.com/lib/pq"
@@ -9,9 +10,11 @@ import (
 	"github.com/opensourceways/defect-manager/defect/domain/dp"
 )
 
+// defectDO the unique index idx_{table}_issue of (org, repo, number) is created by the migration,
+// its name depends on the configured table, so it is not declared in the tags.
 type defectDO struct {
 	ID               int            `gorm:"column:id;primaryKey;autoIncrement"`
-	Number           string         `gorm:"column:number;index"` // Number is the number of issue
+	Number           string         `gorm:"column:number"` // Number is the number of issue
 	Title            string         `gorm:"column:title"`
 	Org              string         `gorm:"column:org"`
 	Repo             string         `gorm:"column:repo"`
@@ -19,14 +22,18 @@ type defectDO struct {
 	Kernel           string         `gorm:"column:kernel"`
 	Component        string         `gorm:"column:component"`
 	ComponentVersion string         `gorm:"column:component_version"`
+	FixedVersion     string         `gorm:"column:fixed_version"`
 	SystemVersion    string         `gorm:"column:system_version"`
 	Description      string         `gorm:"column:description"`
 	ReferenceURL     string         `gorm:"column:reference_url"`
 	GuidanceURL      string         `gorm:"column:guidance_url"`
 	Influence        string         `gorm:"column:influence"`
 	SeverityLevel    string         `gorm:"column:severity_level"`
+	CVSSVector       string         `gorm:"column:cvss_vector"`
+	CVSSScore        float64        `gorm:"column:cvss_score"`
 	AffectedVersion  pq.StringArray `gorm:"column:affected_version;type:text[];default:'{}'"`
 	ABI              string         `gorm:"column:abi"`
+	Releases         string         `gorm:"column:releases;->"` // Releases is written by SaveReleases only
 	CreatedAt        time.Time      `gorm:"column:created_at;<-:create;index"`
 	UpdatedAt        time.Time      `gorm:"column:updated_at"`
 }
@@ -36,7 +43,7 @@ func (d defectDO) TableName() string {
 }
 
 func (impl defectImpl) toDefectDO(defect *domain.Defect) defectDO {
-	return defectDO{
+	do := defectDO{
 		Number:           defect.Issue.Number,
 		Title:            defect.Issue.Title,
 		Org:              defect.Issue.Org,
@@ -45,6 +52,7 @@ func (impl defectImpl) toDefectDO(defect *domain.Defect) defectDO {
 		Kernel:           defect.Kernel,
 		Component:        defect.Component,
 		ComponentVersion: defect.ComponentVersion,
+		FixedVersion:     defect.FixedVersion,
 		SystemVersion:    defect.SystemVersion.String(),
 		Description:      defect.Description,
 		ReferenceURL:     defect.ReferenceURL.URL(),
@@ -54,6 +62,13 @@ func (impl defectImpl) toDefectDO(defect *domain.Defect) defectDO {
 		AffectedVersion:  toStringArray(defect.AffectedVersion),
 		ABI:              defect.ABI,
 	}
+
+	if defect.CVSS != nil {
+		do.CVSSVector = defect.CVSS.Vector()
+		do.CVSSScore = defect.CVSS.Score()
+	}
+
+	return do
 }
 
 func toStringArray(versions []dp.SystemVersion) pq.StringArray {
@@ -82,16 +97,23 @@ func (d defectDO) toDefect() domain.Defect {
 	severityLevel, _ := dp.NewSeverityLevel(d.SeverityLevel)
 	status, _ := dp.NewIssueStatus(d.Status)
 
+	var cvss dp.CVSS
+	if d.CVSSVector != "" {
+		cvss, _ = dp.NewCVSS(d.CVSSVector)
+	}
+
 	return domain.Defect{
 		Kernel:           d.Kernel,
 		Component:        d.Component,
 		ComponentVersion: d.ComponentVersion,
+		FixedVersion:     d.FixedVersion,
 		SystemVersion:    version,
 		Description:      d.Description,
 		ReferenceURL:     referenceURL,
 		GuidanceURL:      guidanceURL,
 		Influence:        d.Influence,
 		SeverityLevel:    severityLevel,
+		CVSS:             cvss,
 		AffectedVersion:  toSystemVersion(d.AffectedVersion),
 		ABI:              d.ABI,
 		Issue: domain.Issue{
@@ -101,5 +123,58 @@ func (d defectDO) toDefect() domain.Defect {
 			Repo:   d.Repo,
 			Status: status,
 		},
+		Releases:  toReleases(d.Releases),
+		CreatedAt: d.CreatedAt,
+		UpdatedAt: d.UpdatedAt,
+	}
+}
+
+type releaseDO struct {
+	Version   string    `json:"version"`
+	Package   string    `json:"package"`
+	Status    string    `json:"status"`
+	Baseline  string    `json:"baseline,omitempty"`
+	CheckedAt time.Time `json:"checked_at"`
+}
+
+func toReleasesDO(rs []domain.Release) (string, error) {
+	dos := make([]releaseDO, len(rs))
+	for k := range rs {
+		dos[k] = releaseDO{
+			Version:   rs[k].Version.String(),
+			Package:   rs[k].Package,
+			Status:    rs[k].Status,
+			Baseline:  rs[k].Baseline,
+			CheckedAt: rs[k].CheckedAt,
+		}
 	}
+
+	v, err := json.Marshal(dos)
+
+	return string(v), err
+}
+
+func toReleases(s string) []domain.Release {
+	var dos []releaseDO
+	if s == "" || json.Unmarshal([]byte(s), &dos) != nil {
+		return nil
+	}
+
+	rs := make([]domain.Release, 0, len(dos))
+	for _, v := range dos {
+		version, err := dp.NewSystemVersion(v.Version)
+		if err != nil {
+			continue
+		}
+
+		rs = append(rs, domain.Release{
+			Version:   version,
+			Package:   v.Package,
+			Status:    v.Status,
+			Baseline:  v.Baseline,
+			CheckedAt: v.CheckedAt,
+		})
+	}
+
+	return rs
 }
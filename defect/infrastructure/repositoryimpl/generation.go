@@ -0,0 +1,34 @@
+package repositoryimpl
+
+import (
+	"gorm.io/gorm/clause"
+
+	"github.com/opensourceways/defect-manager/defect/domain"
+	"github.com/opensourceways/defect-manager/defect/domain/repository"
+)
+
+type generationImpl struct {
+	db dbimpl
+}
+
+func (impl generationImpl) SaveGeneration(g *domain.Generation) error {
+	do := toGenerationDO(g)
+
+	return impl.db.DB().Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: fieldID}},
+		DoUpdates: clause.AssignmentColumns(generationUpdatableFields),
+	}).Create(&do).Error
+}
+
+func (impl generationImpl) FindGeneration(id string) (domain.Generation, error) {
+	var do generationDO
+	if err := impl.db.GetRecord(&generationDO{ID: id}, &do); err != nil {
+		if impl.db.IsRowNotFound(err) {
+			err = repository.ErrGenerationNotFound
+		}
+
+		return domain.Generation{}, err
+	}
+
+	return do.toGeneration(), nil
+}
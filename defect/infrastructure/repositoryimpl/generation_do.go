@@ -0,0 +1,63 @@
+package repositoryimpl
+
+import (
+	"time"
+
+	"github.com/lib/pq"
+
+	"github.com/opensourceways/defect-manager/defect/domain"
+)
+
+var generationUpdatableFields = []string{
+	fieldStatus, "report", "error", fieldUpdatedAt,
+}
+
+// generationDO Report is null until the generation succeeds
+type generationDO struct {
+	ID        string         `gorm:"column:id;primaryKey"`
+	Issues    pq.StringArray `gorm:"column:issues;type:text[]"`
+	Status    string         `gorm:"column:status"`
+	Report    *string        `gorm:"column:report;type:jsonb"`
+	Error     string         `gorm:"column:error"`
+	CreatedAt time.Time      `gorm:"column:created_at"`
+	UpdatedAt time.Time      `gorm:"column:updated_at"`
+}
+
+func (d generationDO) TableName() string {
+	return generationTableName
+}
+
+func toGenerationDO(g *domain.Generation) generationDO {
+	do := generationDO{
+		ID:        g.ID,
+		Issues:    pq.StringArray(g.Issues),
+		Status:    g.Status,
+		Error:     g.Error,
+		CreatedAt: g.CreatedAt,
+		UpdatedAt: g.UpdatedAt,
+	}
+
+	if g.Report != nil {
+		v := string(g.Report)
+		do.Report = &v
+	}
+
+	return do
+}
+
+func (d *generationDO) toGeneration() domain.Generation {
+	g := domain.Generation{
+		ID:        d.ID,
+		Issues:    []string(d.Issues),
+		Status:    d.Status,
+		Error:     d.Error,
+		CreatedAt: d.CreatedAt,
+		UpdatedAt: d.UpdatedAt,
+	}
+
+	if d.Report != nil {
+		g.Report = []byte(*d.Report)
+	}
+
+	return g
+}
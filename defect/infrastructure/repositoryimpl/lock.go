@@ -0,0 +1,48 @@
+package repositoryimpl
+
+import (
+	"context"
+	"database/sql/driver"
+
+	"github.com/sirupsen/logrus"
+)
+
+// advisoryLock holds the postgres session lock of key on a dedicated connection, it waits
+// for the lock unless try is set. locked is false if it is tried and held by others.
+func advisoryLock(db dbimpl, key int, try bool) (unlock func(), locked bool, err error) {
+	sqlDB, err := db.DB().DB()
+	if err != nil {
+		return nil, false, err
+	}
+
+	ctx := context.Background()
+
+	conn, err := sqlDB.Conn(ctx)
+	if err != nil {
+		return nil, false, err
+	}
+
+	if try {
+		err = conn.QueryRowContext(ctx, "SELECT pg_try_advisory_lock($1)", key).Scan(&locked)
+	} else {
+		_, err = conn.ExecContext(ctx, "SELECT pg_advisory_lock($1)", key)
+		locked = err == nil
+	}
+
+	if err != nil || !locked {
+		_ = conn.Close()
+
+		return nil, false, err
+	}
+
+	return func() {
+		if _, err := conn.ExecContext(ctx, "SELECT pg_advisory_unlock($1)", key); err != nil {
+			logrus.Errorf("release the advisory lock %d error: %s, discard the connection", key, err.Error())
+
+			// the connection still holding the lock must not go back to the pool
+			_ = conn.Raw(func(interface{}) error { return driver.ErrBadConn })
+		}
+
+		_ = conn.Close()
+	}, true, nil
+}
@@ -0,0 +1,57 @@
+package repositoryimpl
+
+import (
+	"gorm.io/gorm/clause"
+
+	"github.com/opensourceways/defect-manager/defect/domain"
+	"github.com/opensourceways/defect-manager/defect/domain/repository"
+)
+
+type notificationImpl struct {
+	db dbimpl
+}
+
+func (impl notificationImpl) SaveNotification(n *domain.Notification) error {
+	do := toNotificationDO(n)
+
+	return impl.db.DB().Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: fieldIdentification}},
+		DoUpdates: clause.AssignmentColumns(notificationUpdatableFields),
+	}).Create(&do).Error
+}
+
+func (impl notificationImpl) FindNotification(identification string) (domain.Notification, error) {
+	var do notificationDO
+	if err := impl.db.GetRecord(&notificationDO{Identification: identification}, &do); err != nil {
+		if impl.db.IsRowNotFound(err) {
+			err = repository.ErrNotificationNotFound
+		}
+
+		return domain.Notification{}, err
+	}
+
+	return do.toNotification(), nil
+}
+
+func (impl notificationImpl) FindNotifications(status string) ([]domain.Notification, error) {
+	query := impl.db.DB().Model(&notificationDO{})
+	if status != "" {
+		query = query.Where(fieldStatus+" = ?", status)
+	}
+
+	var dos []notificationDO
+	if err := query.Order(fieldID + " DESC").Find(&dos).Error; err != nil {
+		return nil, err
+	}
+
+	r := make([]domain.Notification, len(dos))
+	for i := range dos {
+		r[i] = dos[i].toNotification()
+	}
+
+	return r, nil
+}
+
+func (impl notificationImpl) LockSender() (func(), bool, error) {
+	return advisoryLock(impl.db, senderLockKey, true)
+}
@@ -0,0 +1,63 @@
+package repositoryimpl
+
+import (
+	"time"
+
+	"github.com/lib/pq"
+
+	"github.com/opensourceways/defect-manager/defect/domain"
+)
+
+const fieldIdentification = "identification"
+
+var notificationUpdatableFields = []string{
+	fieldStatus, "attempts", "last_error", fieldUpdatedAt,
+}
+
+type notificationDO struct {
+	ID              int            `gorm:"column:id;primaryKey;autoIncrement"`
+	Identification  string         `gorm:"column:identification"`
+	Component       string         `gorm:"column:component"`
+	AffectedVersion pq.StringArray `gorm:"column:affected_version;type:text[]"`
+	Issues          pq.StringArray `gorm:"column:issue_number;type:text[]"`
+	File            string         `gorm:"column:file"`
+	PublishedAt     time.Time      `gorm:"column:published_at"`
+	Status          string         `gorm:"column:status"`
+	Attempts        int            `gorm:"column:attempts"`
+	LastError       string         `gorm:"column:last_error"`
+	UpdatedAt       time.Time      `gorm:"column:updated_at"`
+}
+
+func (d notificationDO) TableName() string {
+	return notificationTableName
+}
+
+func toNotificationDO(n *domain.Notification) notificationDO {
+	return notificationDO{
+		Identification:  n.Identification,
+		Component:       n.Component,
+		AffectedVersion: toStringArray(n.AffectedVersion),
+		Issues:          pq.StringArray(n.Issues),
+		File:            n.File,
+		PublishedAt:     n.PublishedAt,
+		Status:          n.Status,
+		Attempts:        n.Attempts,
+		LastError:       n.LastError,
+		UpdatedAt:       n.UpdatedAt,
+	}
+}
+
+func (d *notificationDO) toNotification() domain.Notification {
+	return domain.Notification{
+		Identification:  d.Identification,
+		Component:       d.Component,
+		AffectedVersion: toSystemVersion(d.AffectedVersion),
+		Issues:          []string(d.Issues),
+		File:            d.File,
+		PublishedAt:     d.PublishedAt,
+		Status:          d.Status,
+		Attempts:        d.Attempts,
+		LastError:       d.LastError,
+		UpdatedAt:       d.UpdatedAt,
+	}
+}
@@ -0,0 +1,91 @@
+package repositoryimpl
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/opensourceways/defect-manager/defect/domain"
+)
+
+type outboxImpl struct {
+	db dbimpl
+}
+
+// saveEvents is called in the transaction which changes the state, so the events are saved only if it commits
+func saveEvents(tx *gorm.DB, events []domain.DomainEvent) error {
+	if len(events) == 0 {
+		return nil
+	}
+
+	dos := make([]outboxDO, len(events))
+	for i := range events {
+		dos[i] = toOutboxDO(&events[i])
+	}
+
+	return tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&dos).Error
+}
+
+func (impl outboxImpl) SaveEvents(events ...domain.DomainEvent) error {
+	return saveEvents(impl.db.DB(), events)
+}
+
+func (impl outboxImpl) FindPendingEvents(limit int) ([]domain.DomainEvent, error) {
+	var dos []outboxDO
+
+	err := impl.db.DB().Model(&outboxDO{}).
+		Where(fieldPublishedAt + " IS NULL AND " + fieldParkedAt + " IS NULL").
+		Order(fieldID).
+		Limit(limit).
+		Find(&dos).Error
+	if err != nil {
+		return nil, err
+	}
+
+	r := make([]domain.DomainEvent, len(dos))
+	for i := range dos {
+		r[i] = dos[i].toDomainEvent()
+	}
+
+	return r, nil
+}
+
+func (impl outboxImpl) MarkPublished(id string, at time.Time) error {
+	return impl.db.DB().Model(&outboxDO{}).
+		Where(fieldEventID+" = ?", id).
+		Updates(map[string]interface{}{
+			fieldPublishedAt: at,
+			"attempts":       gorm.Expr("attempts + 1"),
+			"last_error":     "",
+		}).Error
+}
+
+func (impl outboxImpl) RecordFailure(id string, reason string) error {
+	return impl.db.DB().Model(&outboxDO{}).
+		Where(fieldEventID+" = ?", id).
+		Updates(map[string]interface{}{
+			"attempts":   gorm.Expr("attempts + 1"),
+			"last_error": reason,
+		}).Error
+}
+
+func (impl outboxImpl) ParkEvent(id string, reason string, at time.Time) error {
+	return impl.db.DB().Model(&outboxDO{}).
+		Where(fieldEventID+" = ?", id).
+		Updates(map[string]interface{}{
+			fieldParkedAt: at,
+			"attempts":    gorm.Expr("attempts + 1"),
+			"last_error":  reason,
+		}).Error
+}
+
+func (impl outboxImpl) LockRelay() (func(), bool, error) {
+	return advisoryLock(impl.db, relayLockKey, true)
+}
+
+func (impl outboxImpl) DeletePublished(before time.Time) error {
+	return impl.db.DB().
+		Where(fieldPublishedAt+" < ?", before).
+		Delete(&outboxDO{}).Error
+}
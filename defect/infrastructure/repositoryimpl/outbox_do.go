@@ -0,0 +1,55 @@
+package repositoryimpl
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/opensourceways/defect-manager/defect/domain"
+)
+
+const (
+	fieldEventID     = "event_id"
+	fieldPublishedAt = "published_at"
+	fieldParkedAt    = "parked_at"
+)
+
+type outboxDO struct {
+	ID          int          `gorm:"column:id;primaryKey;autoIncrement"`
+	EventID     string       `gorm:"column:event_id"`
+	Type        string       `gorm:"column:type"`
+	Version     string       `gorm:"column:version"`
+	Key         string       `gorm:"column:key"`
+	Body        string       `gorm:"column:body;type:jsonb"`
+	OccurredAt  time.Time    `gorm:"column:occurred_at"`
+	PublishedAt sql.NullTime `gorm:"column:published_at"`
+	ParkedAt    sql.NullTime `gorm:"column:parked_at"`
+	Attempts    int          `gorm:"column:attempts"`
+	LastError   string       `gorm:"column:last_error"`
+}
+
+func (d outboxDO) TableName() string {
+	return outboxTableName
+}
+
+func toOutboxDO(e *domain.DomainEvent) outboxDO {
+	return outboxDO{
+		EventID:    e.ID,
+		Type:       e.Type,
+		Version:    e.Version,
+		Key:        e.Key,
+		Body:       string(e.Body),
+		OccurredAt: e.OccurredAt,
+	}
+}
+
+func (d *outboxDO) toDomainEvent() domain.DomainEvent {
+	return domain.DomainEvent{
+		ID:         d.EventID,
+		Type:       d.Type,
+		Version:    d.Version,
+		Key:        d.Key,
+		Body:       []byte(d.Body),
+		OccurredAt: d.OccurredAt,
+		Attempts:   d.Attempts,
+	}
+}
@@ -0,0 +1,46 @@
+package repositoryimpl
+
+import (
+	"time"
+
+	"github.com/opensourceways/defect-manager/defect/domain/dp"
+)
+
+const (
+	fieldDefectID = "defect_id"
+	fieldRefID    = "ref_id"
+)
+
+type referenceDO struct {
+	ID        int       `gorm:"column:id;primaryKey;autoIncrement"`
+	DefectID  int       `gorm:"column:defect_id"`
+	Type      string    `gorm:"column:type"`
+	RefID     string    `gorm:"column:ref_id"`
+	CreatedAt time.Time `gorm:"column:created_at;<-:create"`
+}
+
+func (d referenceDO) TableName() string {
+	return referenceTableName
+}
+
+func toReferencesDO(defectID int, refs []dp.Reference) []referenceDO {
+	dos := make([]referenceDO, len(refs))
+	for k, v := range refs {
+		dos[k] = referenceDO{
+			DefectID: defectID,
+			Type:     v.Type().String(),
+			RefID:    v.ID(),
+		}
+	}
+
+	return dos
+}
+
+func (d referenceDO) toReference() (dp.Reference, error) {
+	t, err := dp.NewReferenceType(d.Type)
+	if err != nil {
+		return nil, err
+	}
+
+	return dp.NewReference(t, d.RefID)
+}
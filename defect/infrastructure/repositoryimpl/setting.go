@@ -0,0 +1,61 @@
+package repositoryimpl
+
+import (
+	"hash/fnv"
+	"time"
+
+	"gorm.io/gorm/clause"
+
+	"github.com/opensourceways/defect-manager/defect/domain/repository"
+)
+
+const (
+	fieldName = "name"
+
+	// jobLockKeyPrefix is the high 32 bits of the lock key of a job, the low ones are
+	// the hash of its name, so the keys do not collide with the other advisory locks.
+	jobLockKeyPrefix = 7301194
+)
+
+type settingDO struct {
+	Name      string    `gorm:"column:name;primaryKey"`
+	Value     string    `gorm:"column:value"`
+	UpdatedAt time.Time `gorm:"column:updated_at"`
+}
+
+func (d settingDO) TableName() string {
+	return settingTableName
+}
+
+type settingImpl struct {
+	db dbimpl
+}
+
+func (impl settingImpl) FindSetting(name string) (string, error) {
+	var do settingDO
+	if err := impl.db.GetRecord(&settingDO{Name: name}, &do); err != nil {
+		if impl.db.IsRowNotFound(err) {
+			err = repository.ErrSettingNotFound
+		}
+
+		return "", err
+	}
+
+	return do.Value, nil
+}
+
+func (impl settingImpl) SaveSetting(name, value string) error {
+	do := settingDO{Name: name, Value: value, UpdatedAt: time.Now()}
+
+	return impl.db.DB().Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: fieldName}},
+		DoUpdates: clause.AssignmentColumns([]string{"value", fieldUpdatedAt}),
+	}).Create(&do).Error
+}
+
+func (impl settingImpl) LockJob(name string) (func(), bool, error) {
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(name))
+
+	return advisoryLock(impl.db, int(uint64(jobLockKeyPrefix)<<32|uint64(h.Sum32())), true)
+}
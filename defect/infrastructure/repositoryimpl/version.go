@@ -0,0 +1,80 @@
+package repositoryimpl
+
+import (
+	"github.com/opensourceways/defect-manager/defect/domain"
+	"github.com/opensourceways/defect-manager/defect/domain/dp"
+	"github.com/opensourceways/defect-manager/defect/domain/repository"
+)
+
+const fieldVersion = "version"
+
+var versionUpdatableFields = []string{
+	"branch", "lts", "sp", "ga_date", "eol_date", "retired", fieldUpdatedAt,
+}
+
+type versionImpl struct {
+	db dbimpl
+}
+
+func (impl versionImpl) AddVersion(v *domain.MaintainedVersion) error {
+	do := toVersionDO(v)
+
+	err := impl.db.FirstOrCreate(&versionDO{Version: do.Version}, &do)
+	if err != nil && impl.db.IsRowExists(err) {
+		return repository.ErrVersionExists
+	}
+
+	return err
+}
+
+func (impl versionImpl) SaveVersion(v *domain.MaintainedVersion) error {
+	do := toVersionDO(v)
+
+	result := impl.db.DB().Model(&versionDO{}).
+		Where(fieldVersion+" = ?", do.Version).
+		Select(versionUpdatableFields).
+		Updates(&do)
+	if result.Error != nil {
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return repository.ErrVersionNotFound
+	}
+
+	return nil
+}
+
+func (impl versionImpl) FindVersion(version dp.SystemVersion) (domain.MaintainedVersion, error) {
+	var do versionDO
+	if err := impl.db.GetRecord(&versionDO{Version: version.String()}, &do); err != nil {
+		if impl.db.IsRowNotFound(err) {
+			err = repository.ErrVersionNotFound
+		}
+
+		return domain.MaintainedVersion{}, err
+	}
+
+	return do.toVersion()
+}
+
+func (impl versionImpl) FindVersions() (domain.MaintainedVersions, error) {
+	var dos []versionDO
+
+	err := impl.db.DB().Model(&versionDO{}).
+		Order("ga_date ASC NULLS LAST").
+		Order(fieldVersion + " ASC").
+		Find(&dos).Error
+	if err != nil {
+		return nil, err
+	}
+
+	r := make(domain.MaintainedVersions, len(dos))
+	for i := range dos {
+		if r[i], err = dos[i].toVersion(); err != nil {
+			return nil, err
+		}
+	}
+
+	return r, nil
+}
@@ -0,0 +1,70 @@
+package repositoryimpl
+
+import (
+	"time"
+
+	"github.com/opensourceways/defect-manager/defect/domain"
+	"github.com/opensourceways/defect-manager/defect/domain/dp"
+)
+
+type versionDO struct {
+	ID        int        `gorm:"column:id;primaryKey;autoIncrement"`
+	Version   string     `gorm:"column:version"`
+	Branch    string     `gorm:"column:branch"`
+	LTS       bool       `gorm:"column:lts"`
+	SP        bool       `gorm:"column:sp"`
+	GADate    *time.Time `gorm:"column:ga_date"`
+	EOLDate   *time.Time `gorm:"column:eol_date"`
+	Retired   bool       `gorm:"column:retired"`
+	CreatedAt time.Time  `gorm:"column:created_at;<-:create"`
+	UpdatedAt time.Time  `gorm:"column:updated_at"`
+}
+
+func (d versionDO) TableName() string {
+	return versionTableName
+}
+
+func toVersionDO(v *domain.MaintainedVersion) versionDO {
+	toTime := func(t time.Time) *time.Time {
+		if t.IsZero() {
+			return nil
+		}
+
+		return &t
+	}
+
+	return versionDO{
+		Version:   v.Version.String(),
+		Branch:    v.Branch,
+		LTS:       v.LTS,
+		SP:        v.SP,
+		GADate:    toTime(v.GADate),
+		EOLDate:   toTime(v.EOLDate),
+		Retired:   v.Retired,
+		CreatedAt: v.CreatedAt,
+		UpdatedAt: v.UpdatedAt,
+	}
+}
+
+func (d versionDO) toVersion() (v domain.MaintainedVersion, err error) {
+	if v.Version, err = dp.NewSystemVersion(d.Version); err != nil {
+		return
+	}
+
+	v.Branch = d.Branch
+	v.LTS = d.LTS
+	v.SP = d.SP
+	v.Retired = d.Retired
+	v.CreatedAt = d.CreatedAt
+	v.UpdatedAt = d.UpdatedAt
+
+	if d.GADate != nil {
+		v.GADate = *d.GADate
+	}
+
+	if d.EOLDate != nil {
+		v.EOLDate = *d.EOLDate
+	}
+
+	return
+}
@@ -0,0 +1,24 @@
+package sigimpl
+
+// Config SigsEndpoint lists the sigs as the contents of a directory of gitee,
+// ReposEndpoint lists the repos of the sig, {sig} in it is replaced with the name of sig.
+// Interval is the hours to refresh the repos of sigs.
+type Config struct {
+	SigsEndpoint  string `json:"sigs_endpoint"`
+	ReposEndpoint string `json:"repos_endpoint"`
+	Interval      int    `json:"interval"`
+}
+
+func (c *Config) SetDefault() {
+	if c.SigsEndpoint == "" {
+		c.SigsEndpoint = "https://gitee.com/api/v5/repos/openeuler/community/contents/sig"
+	}
+
+	if c.ReposEndpoint == "" {
+		c.ReposEndpoint = "https://www.openeuler.org/api-dsapi/query/sig/repo/committers?community=openeuler&sig={sig}"
+	}
+
+	if c.Interval <= 0 {
+		c.Interval = 24
+	}
+}
@@ -0,0 +1,164 @@
+package sigimpl
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/opensourceways/server-common-lib/utils"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	sigHolder = "{sig}"
+
+	// retryInterval is the interval to refresh again after a failure,
+	// so the callers don't wait for the refresh on every call.
+	retryInterval = 10 * time.Minute
+)
+
+var instance *sigImpl
+
+func Init(cfg *Config) {
+	instance = &sigImpl{
+		cli: utils.NewHttpClient(3),
+		cfg: cfg,
+	}
+}
+
+func Instance() *sigImpl {
+	return instance
+}
+
+type content struct {
+	Type string `json:"type"`
+	Name string `json:"name"`
+}
+
+type reposResult struct {
+	Data struct {
+		CommitterDetails []struct {
+			Repo string `json:"repo"`
+		} `json:"committerDetails"`
+	} `json:"data"`
+}
+
+type sigImpl struct {
+	cli utils.HttpClient
+	cfg *Config
+
+	lock        sync.Mutex
+	sigOfRepo   map[string]string
+	nextRefresh time.Time
+}
+
+func (impl *sigImpl) SIGOfRepo(repo string) (string, error) {
+	impl.lock.Lock()
+	defer impl.lock.Unlock()
+
+	if now := time.Now(); !now.Before(impl.nextRefresh) {
+		if err := impl.refresh(); err != nil {
+			impl.nextRefresh = now.Add(retryInterval)
+
+			if impl.sigOfRepo == nil {
+				return "", err
+			}
+
+			logrus.Errorf("refresh repos of sigs error %s, use the stale one", err.Error())
+		} else {
+			impl.nextRefresh = now.Add(time.Duration(impl.cfg.Interval) * time.Hour)
+		}
+	}
+
+	return impl.sigOfRepo[repo], nil
+}
+
+func (impl *sigImpl) refresh() error {
+	sigs, err := impl.listSigs()
+	if err != nil {
+		return err
+	}
+
+	r := make(map[string]string)
+	failed := make(map[string]bool)
+	for _, sig := range sigs {
+		// Accessing too often can cause 503 errors
+		time.Sleep(time.Millisecond * 200)
+
+		repos, err := impl.listRepos(sig)
+		if err != nil {
+			logrus.Errorf("list repos of sig %s error %s, keep the previous repos", sig, err.Error())
+
+			failed[sig] = true
+
+			continue
+		}
+
+		for _, repo := range repos {
+			r[repo] = sig
+		}
+	}
+
+	for repo, sig := range impl.sigOfRepo {
+		if _, ok := r[repo]; !ok && failed[sig] {
+			r[repo] = sig
+		}
+	}
+
+	if len(r) == 0 {
+		return errors.New("no repo of sigs is found")
+	}
+
+	impl.sigOfRepo = r
+
+	return nil
+}
+
+func (impl *sigImpl) listSigs() ([]string, error) {
+	var res []content
+	if err := impl.get(impl.cfg.SigsEndpoint, &res); err != nil {
+		return nil, err
+	}
+
+	var sigs []string
+	for _, v := range res {
+		if v.Type == "dir" {
+			sigs = append(sigs, v.Name)
+		}
+	}
+
+	return sigs, nil
+}
+
+func (impl *sigImpl) listRepos(sig string) ([]string, error) {
+	var res reposResult
+	endpoint := strings.ReplaceAll(impl.cfg.ReposEndpoint, sigHolder, url.QueryEscape(sig))
+	if err := impl.get(endpoint, &res); err != nil {
+		return nil, err
+	}
+
+	repos := make([]string, 0, len(res.Data.CommitterDetails))
+	for _, v := range res.Data.CommitterDetails {
+		repos = append(repos, v.Repo)
+	}
+
+	return repos, nil
+}
+
+func (impl *sigImpl) get(endpoint string, result interface{}) error {
+	request, err := http.NewRequest(http.MethodGet, endpoint, nil)
+	if err != nil {
+		return err
+	}
+
+	r, _, err := impl.cli.Download(request)
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(r, result)
+}
@@ -15,8 +15,166 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
+        "/v1/component-mappings": {
+            "get": {
+                "security": [
+                    {
+                        "PrivateToken": []
+                    }
+                ],
+                "description": "list the mappings from component to source package in the table, the ones in config are not included",
+                "consumes": [
+                    "application/json"
+                ],
+                "tags": [
+                    "ComponentMapping"
+                ],
+                "summary": "list component mappings",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/app.ComponentMappingDTO"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "PrivateToken": []
+                    }
+                ],
+                "description": "add the mapping or update its source package, only for admin",
+                "consumes": [
+                    "application/json"
+                ],
+                "tags": [
+                    "ComponentMapping"
+                ],
+                "summary": "save a component mapping",
+                "parameters": [
+                    {
+                        "description": "body of mapping",
+                        "name": "param",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/controller.mappingRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Accepted",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/component-mappings/{component}": {
+            "delete": {
+                "security": [
+                    {
+                        "PrivateToken": []
+                    }
+                ],
+                "description": "delete the mapping of the component in the version, only for admin",
+                "consumes": [
+                    "application/json"
+                ],
+                "tags": [
+                    "ComponentMapping"
+                ],
+                "summary": "delete a component mapping",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "component",
+                        "name": "component",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "the mapping of all the versions if empty",
+                        "name": "system_version",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
         "/v1/defect": {
             "get": {
+                "security": [
+                    {
+                        "PrivateToken": []
+                    }
+                ],
                 "description": "collect information of some defects",
                 "consumes": [
                     "application/json"
@@ -32,6 +190,12 @@ const docTemplate = `{
                         "name": "date",
                         "in": "query",
                         "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "collect defects until the date, inclusive",
+                        "name": "end_date",
+                        "in": "query"
                     }
                 ],
                 "responses": {
@@ -49,13 +213,30 @@ const docTemplate = `{
                         "schema": {
                             "type": "string"
                         }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "string"
+                        }
                     }
                 }
             }
         },
         "/v1/defect/bulletin": {
             "post": {
-                "description": "generate security bulletin for some defects",
+                "security": [
+                    {
+                        "PrivateToken": []
+                    }
+                ],
+                "description": "generate security bulletin for some defects in the background, the issues not in any bulletin\nare reported with the reason, get the report by the id of the returned generation",
                 "consumes": [
                     "application/json"
                 ],
@@ -65,7 +246,7 @@ const docTemplate = `{
                 "summary": "generate security bulletin for some defects",
                 "parameters": [
                     {
-                        "description": "body of some issue number",
+                        "description": "body of some issues",
                         "name": "param",
                         "in": "body",
                         "required": true,
@@ -75,10 +256,10 @@ const docTemplate = `{
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Created",
+                    "202": {
+                        "description": "Accepted",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/app.GenerationDTO"
                         }
                     },
                     "400": {
@@ -86,45 +267,1545 @@ const docTemplate = `{
                         "schema": {
                             "type": "string"
                         }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "type": "string"
+                        }
                     }
                 }
             }
-        }
-    },
-    "definitions": {
-        "app.CollectDefectsDTO": {
-            "type": "object",
-            "properties": {
-                "component": {
-                    "type": "string"
-                },
-                "issue_id": {
-                    "type": "string"
-                },
-                "score": {
-                    "type": "string"
-                },
-                "status": {
-                    "type": "string"
-                },
-                "version": {
-                    "type": "string"
+        },
+        "/v1/defect/bulletin/generations/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "PrivateToken": []
+                    }
+                ],
+                "description": "get the status of the generation and the report when it is done",
+                "consumes": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Defect"
+                ],
+                "summary": "get the generation of bulletins",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "id of generation",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/app.GenerationDTO"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
                 }
             }
         },
-        "controller.bulletinRequest": {
-            "type": "object",
-            "required": [
-                "issue_number"
-            ],
-            "properties": {
-                "issue_number": {
-                    "type": "array",
-                    "items": {
-                        "type": "string"
+        "/v1/defect/bulletin/notifications": {
+            "get": {
+                "security": [
+                    {
+                        "PrivateToken": []
+                    }
+                ],
+                "description": "list the notifications to the downstream after the bulletins are uploaded, latest first",
+                "consumes": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Notification"
+                ],
+                "summary": "list notifications of bulletins",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "pending, succeeded or failed, all if empty",
+                        "name": "status",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/app.NotificationDTO"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "string"
+                        }
                     }
                 }
             }
+        },
+        "/v1/defect/bulletin/notifications/{identification}/retry": {
+            "post": {
+                "security": [
+                    {
+                        "PrivateToken": []
+                    }
+                ],
+                "description": "notify the downstream of the bulletin again, nothing is done if it has succeeded",
+                "consumes": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Notification"
+                ],
+                "summary": "retry a notification",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "identification of bulletin",
+                        "name": "identification",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/app.NotificationDTO"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/defect/bulletin/schedule": {
+            "get": {
+                "security": [
+                    {
+                        "PrivateToken": []
+                    }
+                ],
+                "description": "the jobs with the next run and the summary of the last run",
+                "consumes": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Schedule"
+                ],
+                "summary": "status of the scheduled generation",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/scheduler.StatusDTO"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/defect/bulletin/schedule/pause": {
+            "post": {
+                "security": [
+                    {
+                        "PrivateToken": []
+                    }
+                ],
+                "description": "the runs of all the replicas are skipped until it is resumed, only for admin",
+                "consumes": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Schedule"
+                ],
+                "summary": "pause the scheduled generation",
+                "responses": {
+                    "202": {
+                        "description": "Accepted",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/defect/bulletin/schedule/resume": {
+            "post": {
+                "security": [
+                    {
+                        "PrivateToken": []
+                    }
+                ],
+                "description": "resume the paused scheduled generation, only for admin",
+                "consumes": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Schedule"
+                ],
+                "summary": "resume the scheduled generation",
+                "responses": {
+                    "202": {
+                        "description": "Accepted",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/defects": {
+            "get": {
+                "security": [
+                    {
+                        "PrivateToken": []
+                    }
+                ],
+                "description": "list defects with filters, pagination and sorting",
+                "consumes": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Defect"
+                ],
+                "summary": "list defects",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "org of the issue",
+                        "name": "org",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "repo of the issue",
+                        "name": "repo",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "component",
+                        "name": "component",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "status of the issue",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "severity level",
+                        "name": "severity_level",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "system version",
+                        "name": "system_version",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "affected version",
+                        "name": "affected_version",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "keyword in the description",
+                        "name": "keyword",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "id of cve or cwe, or url of upstream commit or bug",
+                        "name": "reference",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "defects created since the date, format: 2006-01-02",
+                        "name": "begin_date",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "defects created until the date, format: 2006-01-02",
+                        "name": "end_date",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "page num which starts from 1",
+                        "name": "page_num",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "count per page, max is 100",
+                        "name": "count_per_page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "created_at, updated_at, number, component or severity_level",
+                        "name": "sort_by",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "asc or desc",
+                        "name": "direction",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "next_cursor of the previous page, page_num is ignored when it is set",
+                        "name": "cursor",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/app.DefectsDTO"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/defects/{org}/{repo}/{number}": {
+            "get": {
+                "security": [
+                    {
+                        "PrivateToken": []
+                    }
+                ],
+                "description": "get a defect by the org, repo and number of the issue",
+                "consumes": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Defect"
+                ],
+                "summary": "get a defect",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "org of the issue",
+                        "name": "org",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "repo of the issue",
+                        "name": "repo",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "number of the issue",
+                        "name": "number",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/app.DefectDTO"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "PrivateToken": []
+                    }
+                ],
+                "description": "delete a defect, only for admin",
+                "consumes": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Defect"
+                ],
+                "summary": "delete a defect",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "org of the issue",
+                        "name": "org",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "repo of the issue",
+                        "name": "repo",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "number of the issue",
+                        "name": "number",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "security": [
+                    {
+                        "PrivateToken": []
+                    }
+                ],
+                "description": "update some fields of a defect, only for admin",
+                "consumes": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Defect"
+                ],
+                "summary": "update a defect",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "org of the issue",
+                        "name": "org",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "repo of the issue",
+                        "name": "repo",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "number of the issue",
+                        "name": "number",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "fields to update",
+                        "name": "param",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/controller.updateDefectRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Accepted",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/defects/{org}/{repo}/{number}/release": {
+            "post": {
+                "security": [
+                    {
+                        "PrivateToken": []
+                    }
+                ],
+                "description": "check whether the fixed package is released in each affected version,\nthe defect is not included in the bulletin of the version until it is released",
+                "consumes": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Defect"
+                ],
+                "summary": "check the release of a defect",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "org of the issue",
+                        "name": "org",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "repo of the issue",
+                        "name": "repo",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "number of the issue",
+                        "name": "number",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/app.ReleaseDTO"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/versions": {
+            "get": {
+                "security": [
+                    {
+                        "PrivateToken": []
+                    }
+                ],
+                "description": "list all the versions with lifecycle, including the retired ones",
+                "consumes": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Version"
+                ],
+                "summary": "list versions",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/app.VersionDTO"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "PrivateToken": []
+                    }
+                ],
+                "description": "add a maintained version, only for admin",
+                "consumes": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Version"
+                ],
+                "summary": "add a version",
+                "parameters": [
+                    {
+                        "description": "body of version",
+                        "name": "param",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/controller.addVersionRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/versions/{version}": {
+            "patch": {
+                "security": [
+                    {
+                        "PrivateToken": []
+                    }
+                ],
+                "description": "update the lifecycle of a version, only for admin",
+                "consumes": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Version"
+                ],
+                "summary": "update a version",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "name of version",
+                        "name": "version",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "fields to update",
+                        "name": "param",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/controller.updateVersionRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Accepted",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/versions/{version}/retire": {
+            "post": {
+                "security": [
+                    {
+                        "PrivateToken": []
+                    }
+                ],
+                "description": "retire a version, it is not maintained any more, only for admin",
+                "consumes": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Version"
+                ],
+                "summary": "retire a version",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "name of version",
+                        "name": "version",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Accepted",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "app.BulletinDTO": {
+            "type": "object",
+            "properties": {
+                "affected_version": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "component": {
+                    "type": "string"
+                },
+                "identification": {
+                    "type": "string"
+                },
+                "issues": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "notification": {
+                    "type": "string"
+                }
+            }
+        },
+        "app.BulletinsReportDTO": {
+            "type": "object",
+            "properties": {
+                "bulletins": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/app.BulletinDTO"
+                    }
+                },
+                "errors": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "excluded": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/app.ExclusionDTO"
+                    }
+                }
+            }
+        },
+        "app.CollectDefectsDTO": {
+            "type": "object",
+            "properties": {
+                "component": {
+                    "type": "string"
+                },
+                "cvss_vector": {
+                    "type": "string"
+                },
+                "issue_id": {
+                    "type": "string"
+                },
+                "issue_url": {
+                    "type": "string"
+                },
+                "org": {
+                    "type": "string"
+                },
+                "repo": {
+                    "type": "string"
+                },
+                "score": {
+                    "type": "string"
+                },
+                "severity_level": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "title": {
+                    "type": "string"
+                },
+                "version": {
+                    "type": "string"
+                }
+            }
+        },
+        "app.ComponentMappingDTO": {
+            "type": "object",
+            "properties": {
+                "component": {
+                    "type": "string"
+                },
+                "source_package": {
+                    "type": "string"
+                },
+                "system_version": {
+                    "type": "string"
+                }
+            }
+        },
+        "app.DefectDTO": {
+            "type": "object",
+            "properties": {
+                "abi": {
+                    "type": "string"
+                },
+                "affected_version": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "component": {
+                    "type": "string"
+                },
+                "component_version": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "cvss_score": {
+                    "type": "string"
+                },
+                "cvss_vector": {
+                    "type": "string"
+                },
+                "description": {
+                    "type": "string"
+                },
+                "fixed_version": {
+                    "type": "string"
+                },
+                "guidance_url": {
+                    "type": "string"
+                },
+                "influence": {
+                    "type": "string"
+                },
+                "issue_id": {
+                    "type": "string"
+                },
+                "issue_url": {
+                    "type": "string"
+                },
+                "kernel": {
+                    "type": "string"
+                },
+                "org": {
+                    "type": "string"
+                },
+                "reference_url": {
+                    "type": "string"
+                },
+                "references": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/app.ReferenceDTO"
+                    }
+                },
+                "releases": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/app.ReleaseDTO"
+                    }
+                },
+                "repo": {
+                    "type": "string"
+                },
+                "severity_level": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "system_version": {
+                    "type": "string"
+                },
+                "title": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "app.DefectsDTO": {
+            "type": "object",
+            "properties": {
+                "defects": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/app.DefectDTO"
+                    }
+                },
+                "next_cursor": {
+                    "type": "string"
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "app.ExclusionDTO": {
+            "type": "object",
+            "properties": {
+                "affected_version": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "detail": {
+                    "type": "string"
+                },
+                "issue": {
+                    "type": "string"
+                },
+                "partial": {
+                    "type": "boolean"
+                },
+                "reason": {
+                    "type": "string"
+                }
+            }
+        },
+        "app.GenerationDTO": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "error": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "issues": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "report": {
+                    "$ref": "#/definitions/app.BulletinsReportDTO"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "app.NotificationDTO": {
+            "type": "object",
+            "properties": {
+                "affected_version": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "attempts": {
+                    "type": "integer"
+                },
+                "component": {
+                    "type": "string"
+                },
+                "file": {
+                    "type": "string"
+                },
+                "identification": {
+                    "type": "string"
+                },
+                "issues": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "last_error": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "app.ReferenceDTO": {
+            "type": "object",
+            "properties": {
+                "id": {
+                    "type": "string"
+                },
+                "type": {
+                    "type": "string"
+                }
+            }
+        },
+        "app.ReleaseDTO": {
+            "type": "object",
+            "properties": {
+                "baseline": {
+                    "type": "string"
+                },
+                "checked_at": {
+                    "type": "string"
+                },
+                "package": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "version": {
+                    "type": "string"
+                }
+            }
+        },
+        "app.VersionDTO": {
+            "type": "object",
+            "properties": {
+                "branch": {
+                    "type": "string"
+                },
+                "eol_date": {
+                    "type": "string"
+                },
+                "ga_date": {
+                    "type": "string"
+                },
+                "lts": {
+                    "type": "boolean"
+                },
+                "maintained": {
+                    "type": "boolean"
+                },
+                "retired": {
+                    "type": "boolean"
+                },
+                "sp": {
+                    "type": "boolean"
+                },
+                "version": {
+                    "type": "string"
+                }
+            }
+        },
+        "controller.addVersionRequest": {
+            "type": "object",
+            "required": [
+                "version"
+            ],
+            "properties": {
+                "branch": {
+                    "type": "string"
+                },
+                "eol_date": {
+                    "type": "string"
+                },
+                "ga_date": {
+                    "type": "string"
+                },
+                "lts": {
+                    "type": "boolean"
+                },
+                "sp": {
+                    "type": "boolean"
+                },
+                "version": {
+                    "type": "string"
+                }
+            }
+        },
+        "controller.bulletinRequest": {
+            "type": "object",
+            "properties": {
+                "force": {
+                    "type": "boolean"
+                },
+                "grouping": {
+                    "type": "string"
+                },
+                "groups": {
+                    "type": "array",
+                    "items": {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        }
+                    }
+                },
+                "issue_number": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "issues": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "controller.mappingRequest": {
+            "type": "object",
+            "required": [
+                "component",
+                "source_package"
+            ],
+            "properties": {
+                "component": {
+                    "type": "string"
+                },
+                "source_package": {
+                    "type": "string"
+                },
+                "system_version": {
+                    "type": "string"
+                }
+            }
+        },
+        "controller.referenceRequest": {
+            "type": "object",
+            "properties": {
+                "id": {
+                    "type": "string"
+                },
+                "type": {
+                    "type": "string"
+                }
+            }
+        },
+        "controller.updateDefectRequest": {
+            "type": "object",
+            "properties": {
+                "abi": {
+                    "type": "string"
+                },
+                "affected_version": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "component": {
+                    "type": "string"
+                },
+                "component_version": {
+                    "type": "string"
+                },
+                "cvss_vector": {
+                    "type": "string"
+                },
+                "description": {
+                    "type": "string"
+                },
+                "fixed_version": {
+                    "type": "string"
+                },
+                "guidance_url": {
+                    "type": "string"
+                },
+                "influence": {
+                    "type": "string"
+                },
+                "kernel": {
+                    "type": "string"
+                },
+                "reference_url": {
+                    "type": "string"
+                },
+                "references": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/controller.referenceRequest"
+                    }
+                },
+                "severity_level": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "system_version": {
+                    "type": "string"
+                },
+                "title": {
+                    "type": "string"
+                }
+            }
+        },
+        "controller.updateVersionRequest": {
+            "type": "object",
+            "properties": {
+                "branch": {
+                    "type": "string"
+                },
+                "eol_date": {
+                    "type": "string"
+                },
+                "ga_date": {
+                    "type": "string"
+                },
+                "lts": {
+                    "type": "boolean"
+                },
+                "retired": {
+                    "type": "boolean"
+                },
+                "sp": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "scheduler.JobStatusDTO": {
+            "type": "object",
+            "properties": {
+                "cron": {
+                    "type": "string"
+                },
+                "last_run": {
+                    "$ref": "#/definitions/scheduler.RunSummary"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "next_run": {
+                    "type": "string"
+                }
+            }
+        },
+        "scheduler.RunSummary": {
+            "type": "object",
+            "properties": {
+                "begin_time": {
+                    "type": "string"
+                },
+                "bulletins": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/app.BulletinDTO"
+                    }
+                },
+                "collected": {
+                    "type": "integer"
+                },
+                "end_time": {
+                    "type": "string"
+                },
+                "error": {
+                    "type": "string"
+                },
+                "excluded": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/app.ExclusionDTO"
+                    }
+                },
+                "job": {
+                    "type": "string"
+                },
+                "skipped": {
+                    "type": "integer"
+                },
+                "started_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "scheduler.StatusDTO": {
+            "type": "object",
+            "properties": {
+                "enabled": {
+                    "type": "boolean"
+                },
+                "jobs": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/scheduler.JobStatusDTO"
+                    }
+                },
+                "paused": {
+                    "type": "boolean"
+                }
+            }
+        }
+    },
+    "securityDefinitions": {
+        "PrivateToken": {
+            "type": "apiKey",
+            "name": "PRIVATE-TOKEN",
+            "in": "header"
         }
     }
 }`
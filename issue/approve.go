@@ -9,6 +9,8 @@ import (
 	"github.com/opensourceways/server-common-lib/utils"
 	"github.com/sirupsen/logrus"
 	"k8s.io/apimachinery/pkg/util/sets"
+
+	"github.com/opensourceways/defect-manager/metrics"
 )
 
 var committerInstance *committerCache
@@ -55,8 +57,11 @@ func (c *committerCache) isCommitter(pathWithNamespace, user string) bool {
 }
 
 func (c *committerCache) initCommitterCache() {
+	sigs := c.getSig()
+	failed := len(sigs) == 0
+
 	cli := utils.NewHttpClient(3)
-	for _, sig := range c.getSig() {
+	for _, sig := range sigs {
 		// Accessing too often can cause 503 errors
 		time.Sleep(time.Millisecond * 200)
 
@@ -65,17 +70,20 @@ func (c *committerCache) initCommitterCache() {
 		request, err := http.NewRequest(http.MethodGet, url, nil)
 		if err != nil {
 			logrus.Errorf("new request of sig %s err: %s", sig, err.Error())
+			failed = true
 			continue
 		}
 		r, _, err := cli.Download(request)
 		if err != nil {
 			logrus.Errorf("get assigner of sig %s err: %s", sig, err.Error())
+			failed = true
 			continue
 		}
 
 		var res ResCommitter
 		if err = json.Unmarshal(r, &res); err != nil {
 			logrus.Errorf("unmarshal of sig %s err: %s", sig, err.Error())
+			failed = true
 			continue
 		}
 
@@ -85,6 +93,12 @@ func (c *committerCache) initCommitterCache() {
 	}
 
 	c.CacheAt = time.Now().Format("20060102")
+
+	if failed {
+		metrics.CommitterCacheRefreshes.WithLabelValues(metrics.ResultFailure).Inc()
+	} else {
+		metrics.CommitterCacheRefreshes.WithLabelValues(metrics.ResultSuccess).Inc()
+	}
 }
 
 func (c *committerCache) getSig() []string {
@@ -99,6 +113,7 @@ func (c *committerCache) getSig() []string {
 	cli := utils.NewHttpClient(3)
 	var res []ResContent
 	r, _, err := cli.Download(request)
+	observeGitee("GetSigContents", err)
 	if err != nil {
 		logrus.Errorf("get sig of openeuler error: %s", err.Error())
 
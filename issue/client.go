@@ -0,0 +1,55 @@
+package issue
+
+import (
+	sdk "github.com/opensourceways/go-gitee/gitee"
+
+	"github.com/opensourceways/defect-manager/metrics"
+)
+
+// instrumentedClient counts the calls to gitee api and their errors by method
+type instrumentedClient struct {
+	cli iClient
+}
+
+func observeGitee(method string, err error) {
+	metrics.GiteeRequests.WithLabelValues(method).Inc()
+
+	if err != nil {
+		metrics.GiteeRequestErrors.WithLabelValues(method).Inc()
+	}
+}
+
+func (c instrumentedClient) CreateIssueComment(org, repo string, number string, comment string) error {
+	err := c.cli.CreateIssueComment(org, repo, number, comment)
+	observeGitee("CreateIssueComment", err)
+
+	return err
+}
+
+func (c instrumentedClient) ListIssueComments(org, repo, number string) ([]sdk.Note, error) {
+	v, err := c.cli.ListIssueComments(org, repo, number)
+	observeGitee("ListIssueComments", err)
+
+	return v, err
+}
+
+func (c instrumentedClient) CloseIssue(owner, repo string, number string) error {
+	err := c.cli.CloseIssue(owner, repo, number)
+	observeGitee("CloseIssue", err)
+
+	return err
+}
+
+func (c instrumentedClient) ReopenIssue(owner, repo string, number string) error {
+	err := c.cli.ReopenIssue(owner, repo, number)
+	observeGitee("ReopenIssue", err)
+
+	return err
+}
+
+func (c instrumentedClient) GetBot() (sdk.User, error) {
+	v, err := c.cli.GetBot()
+	observeGitee("GetBot", err)
+
+	return v, err
+}
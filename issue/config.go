@@ -1,7 +1,39 @@
 package issue
 
+// Config MaintainVersion is only used to initialize the maintained versions when there is none in database.
 type Config struct {
-	RobotToken      string   `json:"robot_token"      required:"true"`
-	IssueType       string   `json:"issue_type"       required:"true"`
-	MaintainVersion []string `json:"maintain_version" required:"true"`
+	RobotToken      string          `json:"robot_token"      required:"true"`
+	IssueType       string          `json:"issue_type"       required:"true"`
+	MaintainVersion []string        `json:"maintain_version"`
+	BranchMappings  []BranchMapping `json:"branch_mappings"`
+}
+
+// BranchMapping the prs merged to the branch of the repo fix the versions,
+// Repo is org/repo and it applies to all the repos if empty.
+// The branch of a maintained version is always mapped to the version.
+type BranchMapping struct {
+	Repo     string   `json:"repo"`
+	Branch   string   `json:"branch"   required:"true"`
+	Versions []string `json:"versions" required:"true"`
+}
+
+// branchesOf returns the branches mapped to the version in the repo besides the branch of the version
+func (c *Config) branchesOf(repo, version string) []string {
+	var r []string
+	for i := range c.BranchMappings {
+		m := &c.BranchMappings[i]
+		if m.Repo != "" && m.Repo != repo {
+			continue
+		}
+
+		for _, v := range m.Versions {
+			if v == version {
+				r = append(r, m.Branch)
+
+				break
+			}
+		}
+	}
+
+	return r
 }
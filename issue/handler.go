@@ -1,20 +1,27 @@
 package issue
 
 import (
-	"encoding/json"
 	"fmt"
-	"net/http"
 	"strings"
 
 	sdk "github.com/opensourceways/go-gitee/gitee"
 	"github.com/opensourceways/robot-gitee-lib/client"
-	"github.com/opensourceways/server-common-lib/utils"
 	"github.com/sirupsen/logrus"
-	"k8s.io/apimachinery/pkg/util/sets"
 
 	"github.com/opensourceways/defect-manager/defect/app"
 	"github.com/opensourceways/defect-manager/defect/domain"
 	"github.com/opensourceways/defect-manager/defect/domain/dp"
+	"github.com/opensourceways/defect-manager/metrics"
+)
+
+const (
+	// actionStateChange is the action of issue event when the issue is closed or reopened
+	actionStateChange = "state_change"
+
+	approvalAccepted       = "accepted"
+	approvalInvalidComment = "invalid_comment"
+	approvalMissingPR      = "missing_pr"
+	approvalFailed         = "failed"
 )
 
 var Instance *eventHandler
@@ -32,10 +39,10 @@ type iClient interface {
 	GetBot() (sdk.User, error)
 }
 
-func InitEventHandler(c *Config, s app.DefectService) error {
-	cli := client.NewClient(func() []byte {
+func InitEventHandler(c *Config, s app.DefectService, v app.VersionService) error {
+	cli := instrumentedClient{client.NewClient(func() []byte {
 		return []byte(c.RobotToken)
-	})
+	})}
 
 	bot, err := cli.GetBot()
 	if err != nil {
@@ -43,20 +50,22 @@ func InitEventHandler(c *Config, s app.DefectService) error {
 	}
 
 	Instance = &eventHandler{
-		botName: bot.Login,
-		cfg:     c,
-		cli:     cli,
-		service: s,
+		botName:  bot.Login,
+		cfg:      c,
+		cli:      cli,
+		service:  s,
+		versions: v,
 	}
 
 	return nil
 }
 
 type eventHandler struct {
-	botName string
-	cfg     *Config
-	cli     iClient
-	service app.DefectService
+	botName  string
+	cfg      *Config
+	cli      iClient
+	service  app.DefectService
+	versions app.VersionService
 }
 
 func (impl eventHandler) HandleIssueEvent(e *sdk.IssueEvent) error {
@@ -76,11 +85,18 @@ func (impl eventHandler) HandleIssueEvent(e *sdk.IssueEvent) error {
 	}
 }
 
-func (impl eventHandler) handleIssueClosed(e *sdk.IssueEvent) error {
-	exist, err := impl.service.IsDefectExist(&domain.Issue{
+func issueOfEvent(e *sdk.IssueEvent) domain.Issue {
+	return domain.Issue{
 		Number: e.GetIssueNumber(),
 		Org:    e.Project.Namespace,
-	})
+		Repo:   e.Project.Name,
+	}
+}
+
+func (impl eventHandler) handleIssueClosed(e *sdk.IssueEvent) error {
+	issue := issueOfEvent(e)
+
+	exist, err := impl.service.IsDefectExist(&issue)
 	if err != nil {
 		return err
 	}
@@ -95,20 +111,49 @@ func (impl eventHandler) handleIssueClosed(e *sdk.IssueEvent) error {
 
 	logrus.Infof("reopen issue %s %s", e.Project.PathWithNamespace, e.Issue.Number)
 
+	if err = impl.service.RejectDefect(&issue, "the data of defect is not collected"); err != nil {
+		logrus.Errorf("record the rejection of issue %s error: %s", e.Issue.Number, err.Error())
+	}
+
 	return impl.cli.CreateIssueComment(e.Project.Namespace,
 		e.Project.Name, e.Issue.Number, "缺陷数据未收集完成，重新打开issue")
 }
 
 func (impl eventHandler) handleIssueOpen(e *sdk.IssueEvent) error {
-	if _, err := impl.parseIssue(e.Issue.Body); err != nil {
+	if e.Action == actionStateChange {
+		impl.handleIssueReopened(e)
+	}
+
+	issueInfo, err := impl.parseIssue(e.Issue.Body)
+	if err != nil {
+		countParseFailures(err)
+
 		return impl.cli.CreateIssueComment(e.Project.Namespace,
 			e.Project.Name, e.Issue.Number, strings.Replace(err.Error(), ". ", "\n\n", -1),
 		)
 	}
 
+	if msg := impl.checkComponent(issueInfo); msg != "" {
+		return impl.cli.CreateIssueComment(e.Project.Namespace, e.Project.Name, e.Issue.Number, msg)
+	}
+
 	return nil
 }
 
+// handleIssueReopened records the reopening if the defect of issue has been accepted
+func (impl eventHandler) handleIssueReopened(e *sdk.IssueEvent) {
+	issue := issueOfEvent(e)
+
+	exist, err := impl.service.IsDefectExist(&issue)
+	if err != nil || !exist {
+		return
+	}
+
+	if err = impl.service.ReopenDefect(&issue); err != nil {
+		logrus.Errorf("record the reopening of issue %s error: %s", e.Issue.Number, err.Error())
+	}
+}
+
 func (impl eventHandler) HandleNoteEvent(e *sdk.NoteEvent) error {
 	if !e.IsIssue() || e.Issue.TypeName != impl.cfg.IssueType ||
 		e.Issue.State == sdk.StatusClosed || e.Comment.User.Login == impl.botName {
@@ -127,17 +172,26 @@ func (impl eventHandler) HandleNoteEvent(e *sdk.NoteEvent) error {
 		}
 
 		if _, err := impl.parseComment(e.Comment.Body); err != nil {
+			countParseFailures(err)
+
 			return commentIssue(err.Error())
 		}
 
 		return nil
 	}
 
+	// the failures of issue body are counted by the issue event, not by each command
 	issueInfo, err := impl.parseIssue(e.Issue.Body)
 	if err != nil {
 		return commentIssue(strings.Replace(err.Error(), ". ", "\n\n", -1))
 	}
 
+	if strings.Contains(e.Comment.Body, cmdCheck) {
+		if msg := impl.checkComponent(issueInfo); msg != "" {
+			return commentIssue(msg)
+		}
+	}
+
 	comment := impl.approveCmdReplyToComment(e)
 	if comment == "" {
 		return nil
@@ -145,28 +199,100 @@ func (impl eventHandler) HandleNoteEvent(e *sdk.NoteEvent) error {
 
 	commentInfo, err := impl.parseComment(comment)
 	if err != nil {
+		countParseFailures(err)
+		metrics.Approvals.WithLabelValues(approvalInvalidComment).Inc()
+
 		return commentIssue(strings.Replace(err.Error(), ". ", "\n\n", -1))
 	}
 
 	if err = impl.checkRelatedPR(e, commentInfo.AffectedVersion); err != nil {
+		metrics.Approvals.WithLabelValues(approvalMissingPR).Inc()
+
 		return commentIssue(err.Error())
 	}
 
 	if err = impl.cli.CloseIssue(e.Project.Namespace, e.Project.Name, e.Issue.Number); err != nil {
+		metrics.Approvals.WithLabelValues(approvalFailed).Inc()
+
 		return fmt.Errorf("close issue error: %s", err.Error())
 	}
 
 	cmd, err := impl.toCmd(e, issueInfo, commentInfo)
 	if err != nil {
+		metrics.Approvals.WithLabelValues(approvalFailed).Inc()
+
 		return fmt.Errorf("to cmd error: %s", err.Error())
 	}
 
-	err = impl.service.SaveDefects(cmd)
-	if err == nil {
-		return commentIssue("Your issue is accepted, thank you")
+	if err = impl.service.SaveDefects(cmd); err != nil {
+		metrics.Approvals.WithLabelValues(approvalFailed).Inc()
+
+		return err
+	}
+
+	metrics.Approvals.WithLabelValues(approvalAccepted).Inc()
+
+	return commentIssue("Your issue is accepted, thank you" + impl.releaseStatus(&cmd.Issue))
+}
+
+// releaseStatus returns the release states of the fixed package,
+// the bulletin of a version is generated only after the package is released.
+func (impl eventHandler) releaseStatus(issue *domain.Issue) string {
+	releases, err := impl.service.CheckRelease(issue)
+	if err != nil {
+		logrus.Errorf("check release of issue %s error: %s", issue.Number, err.Error())
+
+		return ""
+	}
+
+	if len(releases) == 0 {
+		return ""
+	}
+
+	items := make([]string, len(releases))
+	for i, r := range releases {
+		items[i] = fmt.Sprintf("%s: %s %s", r.Version, r.Status, r.Package)
 	}
 
-	return err
+	return "\n\n修复包发布状态, 发布后才会生成安全公告:\n\n" + strings.Join(items, "\n\n")
+}
+
+// checkComponent returns the message to reply if the component is not found in the product tree
+// or its version is older than the shipped ones
+func (impl eventHandler) checkComponent(issue parseIssueResult) string {
+	version, err := dp.NewSystemVersion(issue.SystemVersion)
+	if err != nil {
+		return ""
+	}
+
+	dto, err := impl.service.CheckComponent(app.CmdToCheckComponent{
+		Component:        issue.Component,
+		ComponentVersion: issue.ComponentVersion,
+		SystemVersion:    version,
+	})
+	if err != nil {
+		logrus.Errorf("check component %s error: %s", issue.Component, err.Error())
+
+		return ""
+	}
+
+	if !dto.Found {
+		msg := fmt.Sprintf("%s %s 在 %s 中不存在", itemName[itemComponents], issue.Component, issue.SystemVersion)
+		if len(dto.Suggestions) > 0 {
+			msg += fmt.Sprintf("，是否是: %s", strings.Join(dto.Suggestions, ", "))
+		}
+
+		return msg
+	}
+
+	if !dto.VersionMatched {
+		return fmt.Sprintf("%s %s 的版本 %s 早于 %s 中发布的版本: %s，请修正为 %s-<版本号>",
+			itemName[itemComponents], issue.Component, issue.ComponentVersion, issue.SystemVersion,
+			strings.Join(dto.ShippedVersions, ", "), issue.Component,
+		)
+	}
+
+	return ""
 }
 
 // the content of the comment of the newest /approve reply to
@@ -217,8 +343,15 @@ func (impl eventHandler) toCmd(e *sdk.NoteEvent, issue parseIssueResult, comment
 		return
 	}
 
-	securityLevel, err := dp.NewSeverityLevel(comment.SeverityLevel)
-	if err != nil {
+	var securityLevel dp.SeverityLevel
+	var cvss dp.CVSS
+	if comment.CVSSVector != "" {
+		if cvss, err = dp.NewCVSS(comment.CVSSVector); err != nil {
+			return
+		}
+
+		securityLevel = cvss.SeverityLevel()
+	} else if securityLevel, err = dp.NewSeverityLevel(comment.SeverityLevel); err != nil {
 		return
 	}
 
@@ -241,8 +374,10 @@ func (impl eventHandler) toCmd(e *sdk.NoteEvent, issue parseIssueResult, comment
 		GuidanceURL:      guidanceUrl,
 		Influence:        comment.Influence,
 		SeverityLevel:    securityLevel,
+		CVSS:             cvss,
 		AffectedVersion:  affectedVersion,
 		ABI:              strings.Join(comment.Abi, ","),
+		References:       dp.ParseReferences(e.Issue.Title + "\n" + e.Issue.Body),
 		Issue: domain.Issue{
 			Title:  e.Issue.Title,
 			Number: e.Issue.Number,
@@ -252,44 +387,3 @@ func (impl eventHandler) toCmd(e *sdk.NoteEvent, issue parseIssueResult, comment
 		},
 	}, nil
 }
-
-func (impl eventHandler) checkRelatedPR(e *sdk.NoteEvent, versions []string) error {
-	endpoint := fmt.Sprintf("https://gitee.com/api/v5/repos/%v/issues/%v/pull_requests?access_token=%s&repo=%s",
-		e.Project.Namespace, e.Issue.Number, impl.cfg.RobotToken, e.Project.Name,
-	)
-	req, err := http.NewRequest(http.MethodGet, endpoint, nil)
-	if err != nil {
-		return err
-	}
-
-	var prs []sdk.PullRequest
-	cli := utils.NewHttpClient(3)
-	bytes, _, err := cli.Download(req)
-	if err != nil {
-		return err
-	}
-
-	if err := json.Unmarshal(bytes, &prs); err != nil {
-		return err
-	}
-
-	mergedVersion := sets.NewString()
-	for _, pr := range prs {
-		if pr.State == sdk.StatusMerged {
-			mergedVersion.Insert(pr.Base.Ref)
-		}
-	}
-
-	var relatedPRNotMerged []string
-	for _, v := range versions {
-		if !mergedVersion.Has(v) {
-			relatedPRNotMerged = append(relatedPRNotMerged, v)
-		}
-	}
-
-	if len(relatedPRNotMerged) != 0 {
-		return fmt.Errorf("受影响分支关联pr未合入: %s", strings.Join(relatedPRNotMerged, ","))
-	}
-
-	return nil
-}
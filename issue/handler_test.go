@@ -3,12 +3,14 @@ package issue
 import (
 	"errors"
 	"testing"
-	"time"
 
 	sdk "github.com/opensourceways/go-gitee/gitee"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 
 	"github.com/opensourceways/defect-manager/defect/app"
 	"github.com/opensourceways/defect-manager/defect/domain"
+	"github.com/opensourceways/defect-manager/defect/domain/dp"
+	"github.com/opensourceways/defect-manager/metrics"
 )
 
 func TestIssueClosed(t *testing.T) {
@@ -34,6 +36,28 @@ func TestIssueClosed(t *testing.T) {
 	}
 }
 
+func TestCountParseFailures(t *testing.T) {
+	h := eventHandler{}
+
+	before := testutil.ToFloat64(metrics.ParseFailures.WithLabelValues(itemInfluence))
+
+	_, err := h.parseComment("")
+	if err == nil {
+		t.Fatal("expect a parse error")
+	}
+
+	// parsing it again within the same event doesn't count
+	if _, err = h.parseComment(""); err == nil {
+		t.Fatal("expect a parse error")
+	}
+
+	countParseFailures(err)
+
+	if v := testutil.ToFloat64(metrics.ParseFailures.WithLabelValues(itemInfluence)) - before; v != 1 {
+		t.Errorf("expect 1 failure, got %v", v)
+	}
+}
+
 type cliTest struct {
 }
 
@@ -68,10 +92,72 @@ func (t serviceTest) SaveDefects(app.CmdToSaveDefect) error {
 	return nil
 }
 
-func (t serviceTest) CollectDefects(time time.Time) ([]app.CollectDefectsDTO, error) {
+func (t serviceTest) CollectDefects(app.CmdToCollectDefects) ([]app.CollectDefectsDTO, error) {
 	return nil, nil
 }
 
-func (t serviceTest) GenerateBulletins([]string) error {
+func (t serviceTest) GenerateBulletins(app.CmdToGenerateBulletins) (app.BulletinsReportDTO, error) {
+	return app.BulletinsReportDTO{}, nil
+}
+
+func (t serviceTest) ListDefects(app.CmdToListDefects) (app.DefectsDTO, error) {
+	return app.DefectsDTO{}, nil
+}
+
+func (t serviceTest) GetDefect(*domain.Issue) (app.DefectDTO, error) {
+	return app.DefectDTO{}, nil
+}
+
+func (t serviceTest) UpdateDefect(app.CmdToUpdateDefect) error {
 	return nil
 }
+
+func (t serviceTest) DeleteDefect(*domain.Issue) error {
+	return nil
+}
+
+func (t serviceTest) CheckRelease(*domain.Issue) ([]app.ReleaseDTO, error) {
+	return nil, nil
+}
+
+func (t serviceTest) CheckComponent(app.CmdToCheckComponent) (app.ComponentCheckDTO, error) {
+	return app.ComponentCheckDTO{Found: true}, nil
+}
+
+func (t serviceTest) RejectDefect(*domain.Issue, string) error {
+	return nil
+}
+
+func (t serviceTest) ReopenDefect(*domain.Issue) error {
+	return nil
+}
+
+type versionsTest struct {
+	app.VersionService
+
+	versions []string
+}
+
+func (t versionsTest) MaintainedVersions() ([]dp.SystemVersion, error) {
+	r := make([]dp.SystemVersion, len(t.versions))
+	for i, v := range t.versions {
+		r[i], _ = dp.NewSystemVersion(v)
+	}
+
+	return r, nil
+}
+
+func TestParseVersion(t *testing.T) {
+	h := eventHandler{versions: versionsTest{
+		versions: []string{"openEuler-22.03-LTS-SP1", "openEuler-24.03-LTS"},
+	}}
+
+	v, err := h.parseVersion("openEuler-22.03-LTS-SP1:是")
+	if err != nil || len(v) != 1 || v[0] != "openEuler-22.03-LTS-SP1" {
+		t.Errorf("a subset of the maintained versions should be accepted, got %v, %v", v, err)
+	}
+
+	if _, err = h.parseVersion("openEuler-22.03-LTS-SP1:是\nopenEuler-20.03-LTS-SP1:否"); err == nil {
+		t.Error("the version not maintained should be rejected")
+	}
+}
@@ -1,6 +1,7 @@
 package issue
 
 import (
+	"errors"
 	"fmt"
 	"regexp"
 	"strings"
@@ -8,6 +9,8 @@ import (
 	"github.com/opensourceways/server-common-lib/utils"
 	"k8s.io/apimachinery/pkg/util/sets"
 
+	"github.com/opensourceways/defect-manager/defect/domain/dp"
+	"github.com/opensourceways/defect-manager/metrics"
 	localutils "github.com/opensourceways/defect-manager/utils"
 )
 
@@ -57,7 +60,7 @@ var (
 		itemReferenceUrl:    regexp.MustCompile(`(缺陷详情参考链接)[:：]\*\*([\s\S]*?)\*\*缺陷分析指导链接`),
 		itemGuidanceUrl:     regexp.MustCompile(`(缺陷分析指导链接)[:：]\*\*([\s\S]*?)$`),
 		itemInfluence:       regexp.MustCompile(`(影响性分析说明)[:：]([\s\S]*?)缺陷严重等级`),
-		itemSeverityLevel:   regexp.MustCompile(`(缺陷严重等级)[:：]\(Critical/High/Moderate/Low\)([\s\S]*?)受影响版本排查`),
+		itemSeverityLevel:   regexp.MustCompile(`(缺陷严重等级)[:：]\(Critical/High/Moderate/Low[^)]*\)([\s\S]*?)受影响版本排查`),
 		itemAffectedVersion: regexp.MustCompile(`(受影响版本排查)\(受影响/不受影响\)[:：]([\s\S]*?)abi变化`),
 		itemAbi:             regexp.MustCompile(`(abi变化)\(受影响/不受影响\)[:：]([\s\S]*?)$`),
 	}
@@ -106,9 +109,11 @@ type parseIssueResult struct {
 	GuidanceUrl      string
 }
 
+// parseCommentResult the severity level is given by either SeverityLevel or CVSSVector
 type parseCommentResult struct {
 	Influence       string
 	SeverityLevel   string
+	CVSSVector      string
 	AffectedVersion []string
 	Abi             []string
 }
@@ -172,13 +177,17 @@ func (impl eventHandler) parseComment(body string) (parseCommentResult, error) {
 	}
 
 	if v, ok := result[itemSeverityLevel]; ok {
-		ret.SeverityLevel = v
+		if severityLevelMap[v] {
+			ret.SeverityLevel = v
+		} else {
+			ret.CVSSVector = v
+		}
 	}
 
 	if v, ok := result[itemAffectedVersion]; ok {
 		affectedVersion, err := impl.parseVersion(v)
 		if err != nil {
-			return parseCommentResult{}, err
+			return parseCommentResult{}, &parseError{items: []string{itemAffectedVersion}, err: err}
 		}
 
 		ret.AffectedVersion = affectedVersion
@@ -187,7 +196,7 @@ func (impl eventHandler) parseComment(body string) (parseCommentResult, error) {
 	if v, ok := result[itemAbi]; ok {
 		abi, err := impl.parseVersion(v)
 		if err != nil {
-			return parseCommentResult{}, err
+			return parseCommentResult{}, &parseError{items: []string{itemAbi}, err: err}
 		}
 
 		ret.Abi = abi
@@ -196,24 +205,51 @@ func (impl eventHandler) parseComment(body string) (parseCommentResult, error) {
 	return ret, nil
 }
 
+// parseError records the items which fail to be parsed
+type parseError struct {
+	items []string
+	err   error
+}
+
+func (e *parseError) Error() string {
+	return e.err.Error()
+}
+
+// countParseFailures counts the failed items of err, it is called once for each event
+func countParseFailures(err error) {
+	var pe *parseError
+	if !errors.As(err, &pe) {
+		return
+	}
+
+	for _, item := range sets.NewString(pe.items...).UnsortedList() {
+		metrics.ParseFailures.WithLabelValues(item).Inc()
+	}
+}
+
 func (impl eventHandler) parse(items []string, body string) (map[string]string, error) {
 	mr := utils.NewMultiErrors()
+	var failed []string
+	fail := func(item, msg string) {
+		failed = append(failed, item)
+		mr.Add(msg)
+	}
 	genErr := func(item, value string) string {
-		return fmt.Sprintf("%s %s 错误", item, value)
+		return fmt.Sprintf("%s %s 错误", itemName[item], value)
 	}
 
 	parseResult := make(map[string]string)
 	for _, item := range items {
 		match := regexpOfItems[item].FindAllStringSubmatch(body, -1)
 		if len(match) < 1 || len(match[regMatchResult]) < 3 {
-			mr.Add(fmt.Sprintf("%s 解析失败", itemName[item]))
+			fail(item, fmt.Sprintf("%s 解析失败", itemName[item]))
 			continue
 		}
 
 		matchItem := match[regMatchResult][regMatchItem]
 		trimItemInfo := localutils.TrimString(matchItem)
 		if trimItemInfo == "" {
-			mr.Add(fmt.Sprintf("%s 不允许为空", itemName[item]))
+			fail(item, fmt.Sprintf("%s 不允许为空", itemName[item]))
 			continue
 		}
 
@@ -225,23 +261,47 @@ func (impl eventHandler) parse(items []string, body string) (map[string]string,
 
 		switch item {
 		case itemSeverityLevel:
-			if _, exist := severityLevelMap[parseResult[item]]; !exist {
-				mr.Add(genErr(itemName[itemSystemVersion], parseResult[item]))
+			if !severityLevelMap[parseResult[item]] {
+				if _, err := dp.NewCVSS(parseResult[item]); err != nil {
+					fail(item, genErr(item, parseResult[item])+", "+err.Error())
+				}
 			}
 		case itemSystemVersion:
-			maintainVersion := sets.NewString(impl.cfg.MaintainVersion...)
-			if !maintainVersion.Has(parseResult[item]) {
-				mr.Add(genErr(itemName[itemSystemVersion], parseResult[item]))
+			maintainVersion, err := impl.maintainedVersions()
+			if err != nil {
+				return nil, err
+			}
+
+			if !sets.NewString(maintainVersion...).Has(parseResult[item]) {
+				fail(item, genErr(item, parseResult[item]))
 			}
 		case itemComponents:
 			split := strings.Split(parseResult[item], "-")
 			if len(split) < 2 {
-				mr.Add(genErr(itemName[itemComponents], parseResult[item]))
+				fail(item, genErr(item, parseResult[item]))
 			}
 		}
 	}
 
-	return parseResult, mr.Err()
+	if err := mr.Err(); err != nil {
+		return parseResult, &parseError{items: failed, err: err}
+	}
+
+	return parseResult, nil
+}
+
+func (impl eventHandler) maintainedVersions() ([]string, error) {
+	vs, err := impl.versions.MaintainedVersions()
+	if err != nil {
+		return nil, fmt.Errorf("get maintained versions error: %s", err.Error())
+	}
+
+	r := make([]string, len(vs))
+	for i, v := range vs {
+		r[i] = v.String()
+	}
+
+	return r, nil
 }
 
 func (impl eventHandler) parseVersion(s string) ([]string, error) {
@@ -261,10 +321,16 @@ func (impl eventHandler) parseVersion(s string) ([]string, error) {
 		}
 	}
 
-	av := sets.NewString(allVersion...)
-	if !av.HasAll(impl.cfg.MaintainVersion...) {
-		return nil, fmt.Errorf("受影响版本排查/abi变化与当前维护版本不一致，当前维护版本:\n%s",
-			strings.Join(impl.cfg.MaintainVersion, "\n"),
+	maintainVersion, err := impl.maintainedVersions()
+	if err != nil {
+		return nil, err
+	}
+
+	// any subset of the maintained versions is accepted, so that adding a version
+	// doesn't break the issues in progress, only the unknown or retired ones are rejected.
+	if unknown := sets.NewString(allVersion...).Difference(sets.NewString(maintainVersion...)); unknown.Len() > 0 {
+		return nil, fmt.Errorf("受影响版本排查/abi变化包含非维护版本:\n%s\n当前维护版本:\n%s",
+			strings.Join(unknown.List(), "\n"), strings.Join(maintainVersion, "\n"),
 		)
 	}
 
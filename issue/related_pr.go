@@ -0,0 +1,140 @@
+package issue
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	sdk "github.com/opensourceways/go-gitee/gitee"
+	"github.com/opensourceways/server-common-lib/utils"
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+// relatedPRsOfVersion is the related prs whose base branches are mapped to the version
+type relatedPRsOfVersion struct {
+	version   string
+	merged    []string
+	notMerged []string
+}
+
+func (r *relatedPRsOfVersion) isFixed() bool {
+	return len(r.merged) > 0
+}
+
+func (r *relatedPRsOfVersion) String() string {
+	if len(r.merged) == 0 && len(r.notMerged) == 0 {
+		return fmt.Sprintf("%s: 未找到关联pr", r.version)
+	}
+
+	s := r.version + ":"
+	if len(r.merged) > 0 {
+		s += " 已合入 " + strings.Join(r.merged, ", ")
+	}
+
+	if len(r.notMerged) > 0 {
+		s += " 未合入 " + strings.Join(r.notMerged, ", ")
+	}
+
+	return s
+}
+
+func (impl eventHandler) checkRelatedPR(e *sdk.NoteEvent, versions []string) error {
+	prs, err := impl.listRelatedPR(e)
+	if err != nil {
+		return err
+	}
+
+	branches, err := impl.versionBranches()
+	if err != nil {
+		return err
+	}
+
+	report := relatedPRReport(versions, prs, func(version string) []string {
+		r := impl.cfg.branchesOf(e.Project.PathWithNamespace, version)
+		if b, ok := branches[version]; ok {
+			r = append(r, b)
+		}
+
+		return r
+	})
+
+	fixed := true
+	items := make([]string, len(report))
+	for i := range report {
+		items[i] = report[i].String()
+		fixed = fixed && report[i].isFixed()
+	}
+
+	if !fixed {
+		return fmt.Errorf("受影响版本关联pr未合入:\n\n%s", strings.Join(items, "\n\n"))
+	}
+
+	return nil
+}
+
+func (impl eventHandler) listRelatedPR(e *sdk.NoteEvent) ([]sdk.PullRequest, error) {
+	endpoint := fmt.Sprintf("https://gitee.com/api/v5/repos/%v/issues/%v/pull_requests?access_token=%s&repo=%s",
+		e.Project.Namespace, e.Issue.Number, impl.cfg.RobotToken, e.Project.Name,
+	)
+	req, err := http.NewRequest(http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	cli := utils.NewHttpClient(3)
+	bytes, _, err := cli.Download(req)
+	observeGitee("ListIssuePullRequests", err)
+	if err != nil {
+		return nil, err
+	}
+
+	var prs []sdk.PullRequest
+	if err := json.Unmarshal(bytes, &prs); err != nil {
+		return nil, err
+	}
+
+	return prs, nil
+}
+
+// versionBranches returns the branch of each maintained version
+func (impl eventHandler) versionBranches() (map[string]string, error) {
+	vs, err := impl.versions.ListVersions()
+	if err != nil {
+		return nil, err
+	}
+
+	r := make(map[string]string, len(vs))
+	for _, v := range vs {
+		r[v.Version] = v.Branch
+	}
+
+	return r, nil
+}
+
+func relatedPRReport(
+	versions []string, prs []sdk.PullRequest, branchesOf func(string) []string,
+) []relatedPRsOfVersion {
+	r := make([]relatedPRsOfVersion, len(versions))
+	for i, version := range versions {
+		item := relatedPRsOfVersion{version: version}
+		branches := sets.NewString(branchesOf(version)...)
+
+		for _, pr := range prs {
+			if pr.Base == nil || !branches.Has(pr.Base.Ref) {
+				continue
+			}
+
+			name := fmt.Sprintf("#%d(%s)", pr.Number, pr.Base.Ref)
+			if pr.State == sdk.StatusMerged {
+				item.merged = append(item.merged, name)
+			} else {
+				item.notMerged = append(item.notMerged, name)
+			}
+		}
+
+		r[i] = item
+	}
+
+	return r
+}
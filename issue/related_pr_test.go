@@ -0,0 +1,44 @@
+package issue
+
+import (
+	"testing"
+
+	sdk "github.com/opensourceways/go-gitee/gitee"
+)
+
+func TestRelatedPRReport(t *testing.T) {
+	cfg := Config{
+		BranchMappings: []BranchMapping{
+			{Repo: "src-openeuler/zbar", Branch: "master", Versions: []string{"openEuler-24.03-LTS"}},
+			{Branch: "openEuler-22.03-LTS-Next", Versions: []string{"openEuler-22.03-LTS", "openEuler-22.03-LTS-SP1"}},
+		},
+	}
+
+	prs := []sdk.PullRequest{
+		{Number: 1, State: sdk.StatusMerged, Base: &sdk.BranchHook{Ref: "openEuler-22.03-LTS-Next"}},
+		{Number: 2, State: "open", Base: &sdk.BranchHook{Ref: "openEuler-22.03-LTS-SP1"}},
+		{Number: 3, State: sdk.StatusMerged, Base: &sdk.BranchHook{Ref: "master"}},
+	}
+
+	versions := []string{"openEuler-22.03-LTS-SP1", "openEuler-24.03-LTS", "openEuler-20.03-LTS-SP4"}
+
+	report := relatedPRReport(versions, prs, func(v string) []string {
+		return append(cfg.branchesOf("src-openeuler/zbar", v), v)
+	})
+
+	want := []string{
+		"openEuler-22.03-LTS-SP1: 已合入 #1(openEuler-22.03-LTS-Next) 未合入 #2(openEuler-22.03-LTS-SP1)",
+		"openEuler-24.03-LTS: 已合入 #3(master)",
+		"openEuler-20.03-LTS-SP4: 未找到关联pr",
+	}
+
+	for i := range report {
+		if got := report[i].String(); got != want[i] {
+			t.Errorf("got %s, want %s", got, want[i])
+		}
+	}
+
+	if !report[0].isFixed() || !report[1].isFixed() || report[2].isFixed() {
+		t.Errorf("unexpected fixed state")
+	}
+}
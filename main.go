@@ -14,18 +14,27 @@ import (
 	swaggerfiles "github.com/swaggo/files"
 	ginSwagger "github.com/swaggo/gin-swagger"
 
+	authapp "github.com/opensourceways/defect-manager/auth/app"
+	authcontroller "github.com/opensourceways/defect-manager/auth/controller"
+	"github.com/opensourceways/defect-manager/auth/infrastructure/oidcimpl"
+	authrepositoryimpl "github.com/opensourceways/defect-manager/auth/infrastructure/repositoryimpl"
 	"github.com/opensourceways/defect-manager/config"
 	"github.com/opensourceways/defect-manager/defect/app"
 	"github.com/opensourceways/defect-manager/defect/controller"
-	"github.com/opensourceways/defect-manager/defect/domain/dp"
 	"github.com/opensourceways/defect-manager/defect/infrastructure/backendimpl"
 	"github.com/opensourceways/defect-manager/defect/infrastructure/bulletinimpl"
+	"github.com/opensourceways/defect-manager/defect/infrastructure/notifierimpl"
 	"github.com/opensourceways/defect-manager/defect/infrastructure/obsimpl"
 	"github.com/opensourceways/defect-manager/defect/infrastructure/producttreeimpl"
+	"github.com/opensourceways/defect-manager/defect/infrastructure/publisherimpl"
 	"github.com/opensourceways/defect-manager/defect/infrastructure/repositoryimpl"
+	"github.com/opensourceways/defect-manager/defect/infrastructure/sigimpl"
 	"github.com/opensourceways/defect-manager/docs"
 	"github.com/opensourceways/defect-manager/issue"
 	messageserver "github.com/opensourceways/defect-manager/message-server"
+	"github.com/opensourceways/defect-manager/metrics"
+	"github.com/opensourceways/defect-manager/scheduler"
+	"github.com/opensourceways/defect-manager/utils"
 )
 
 type options struct {
@@ -51,10 +60,25 @@ func gatherOptions(fs *flag.FlagSet, args ...string) options {
 	return o
 }
 
+// @securityDefinitions.apikey PrivateToken
+// @in header
+// @name PRIVATE-TOKEN
 func main() {
 	logrusutil.ComponentInit("defect-manager")
 	log := logrus.NewEntry(logrus.StandardLogger())
 
+	if len(os.Args) > 1 && os.Args[1] == cmdToken {
+		runTokenCmd(os.Args[2:])
+
+		return
+	}
+
+	if len(os.Args) > 1 && os.Args[1] == cmdMigrate {
+		runMigrateCmd(os.Args[2:])
+
+		return
+	}
+
 	o := gatherOptions(
 		flag.NewFlagSet(os.Args[0], flag.ExitOnError),
 		os.Args[1:]...,
@@ -84,18 +108,29 @@ func main() {
 		return
 	}
 
-	if err = obsimpl.Init(&cfg.Obs); err != nil {
+	if err = obsimpl.Init(&cfg.Obs, utils.SystemClock{}); err != nil {
 		logrus.Errorf("init obs failed, err:%s", err.Error())
 
 		return
 	}
 
-	if err = repositoryimpl.Init(&cfg.Config); err != nil {
-		logrus.Errorf("init repository failed, err:%s", err.Error())
+	m, err := newMigrator(cfg)
+	if err != nil {
+		logrus.Errorf("load migrations failed, err:%s", err.Error())
 
 		return
 	}
 
+	if err = m.Prepare(); err != nil {
+		logrus.Errorf("migrate db failed, err:%s", err.Error())
+
+		return
+	}
+
+	repositoryimpl.Init(&cfg.Config)
+
+	authrepositoryimpl.Init(&cfg.Auth)
+
 	// kafka
 	if err = kafka.Init(&cfg.Kafka, log, nil, cfg.MessageServer.GroupName, false); err != nil {
 		logrus.Errorf("init kafka failed, err:%s", err.Error())
@@ -105,29 +140,89 @@ func main() {
 
 	defer kafka.Exit()
 
-	dp.Init(cfg.Issue.MaintainVersion)
+	versions := app.NewVersionService(repositoryimpl.VersionInstance())
+	if err = versions.InitVersions(cfg.Issue.MaintainVersion); err != nil {
+		logrus.Errorf("init maintained versions failed, err:%s", err.Error())
+
+		return
+	}
+
+	backendimpl.Init(&cfg.Backend, utils.SystemClock{})
 
-	backendimpl.Init(&cfg.Backend)
+	// the notifications are retried by the worker, so the backend of notifier does not retry
+	noRetries := 0
+	notifierBackend := cfg.Backend
+	notifierBackend.MaxRetries = &noRetries
+
+	notifierimpl.Init(&cfg.Notification, backendimpl.New(&notifierBackend, utils.SystemClock{}))
 
 	bulletinimpl.Init(&cfg.Bulletin)
 
 	producttreeimpl.Init(&cfg.ProductTree)
 
+	sigimpl.Init(&cfg.SIG)
+
+	publisherimpl.Init(&cfg.Event)
+
+	oidcimpl.Init(&cfg.OIDC)
+
 	issue.InitCommitterInstance()
 
-	run(cfg, o)
+	run(cfg, o, versions)
 }
 
-func run(cfg *config.Config, o options) {
-	service := app.NewDefectService(
-		repositoryimpl.Instance(),
-		producttreeimpl.Instance(),
-		bulletinimpl.Instance(),
-		backendimpl.Instance(),
-		obsimpl.Instance(),
+func run(cfg *config.Config, o options, versions app.VersionService) {
+	clock := utils.SystemClock{}
+
+	notices := app.NewNotificationService(
+		repositoryimpl.NotificationInstance(),
+		notifierimpl.Instance(),
+		cfg.Notification.MaxAttempts,
+		cfg.Notification.RetryDelay(),
+		notifierimpl.Subscriptions(),
+		notifierimpl.Channels(),
+		sigimpl.Instance(),
+	)
+
+	notices.Start()
+
+	defer notices.Stop()
+
+	events := app.NewEventOutbox(
+		repositoryimpl.OutboxInstance(),
+		publisherimpl.Instance(),
+		cfg.Event.BatchSize,
+		cfg.Event.MaxAttempts,
+		cfg.Event.PublishInterval(),
+		cfg.Event.RetentionPeriod(),
 	)
 
-	if err := issue.InitEventHandler(&cfg.Issue, service); err != nil {
+	events.Start()
+
+	defer events.Stop()
+
+	service := app.NewDefectService(app.DefectServiceOptions{
+		Repo:        repositoryimpl.Instance(),
+		Mapping:     repositoryimpl.ComponentMappingInstance(),
+		Versions:    repositoryimpl.VersionInstance(),
+		Bulletins:   repositoryimpl.BulletinInstance(),
+		ProductTree: producttreeimpl.Instance(),
+		Bulletin:    bulletinimpl.Instance(),
+		Backend:     backendimpl.Instance(),
+		OBS:         obsimpl.Instance(),
+		SIG:         sigimpl.Instance(),
+		Notices:     notices,
+		Events:      events,
+		Clock:       clock,
+	})
+
+	generations := app.NewGenerationService(repositoryimpl.GenerationInstance(), service, clock)
+
+	generations.Start()
+
+	defer generations.Stop()
+
+	if err := issue.InitEventHandler(&cfg.Issue, service, versions); err != nil {
 		logrus.Errorf("init event handler failed, err:%s", err.Error())
 
 		return
@@ -140,23 +235,35 @@ func run(cfg *config.Config, o options) {
 		return
 	}
 
+	scheduler.Init(&cfg.Scheduler, service, repositoryimpl.SettingInstance())
+	scheduler.Instance().Start()
+
+	defer scheduler.Instance().Stop()
+
 	// run http server
 	server2.StartWebServer(o.service.Port, o.service.GracePeriod, func(engine *gin.Engine) {
 		docs.SwaggerInfo.BasePath = "/api"
 		docs.SwaggerInfo.Title = "Software Package"
-		docs.SwaggerInfo.Description = "set header: 'PRIVATE-TOKEN=xxx'"
+		docs.SwaggerInfo.Description = "set header: 'PRIVATE-TOKEN=xxx' or 'Authorization=Bearer xxx'"
 
-		v1 := engine.Group(docs.SwaggerInfo.BasePath)
-		controller.AddRouteForDefectController(
-			v1, app.NewDefectService(
-				repositoryimpl.Instance(),
-				producttreeimpl.Instance(),
-				bulletinimpl.Instance(),
-				backendimpl.Instance(),
-				obsimpl.Instance(),
+		auth := authcontroller.NewAuthMiddleware(
+			authapp.NewAuthService(
+				authrepositoryimpl.TokenInstance(),
+				authrepositoryimpl.AuditInstance(),
+				oidcimpl.Instance(),
 			),
 		)
+
+		v1 := engine.Group(docs.SwaggerInfo.BasePath)
+		controller.AddRouteForDefectController(v1, service, generations, auth)
+		controller.AddRouteForVersionController(v1, versions, auth)
+		controller.AddRouteForComponentMappingController(
+			v1, app.NewComponentMappingService(repositoryimpl.ComponentMappingInstance()), auth,
+		)
+		controller.AddRouteForNotificationController(v1, notices, auth)
+		controller.AddRouteForScheduleController(v1, scheduler.Instance(), auth)
 		engine.UseRawPath = true
 		engine.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerfiles.Handler))
+		engine.GET("/metrics", gin.WrapH(metrics.Handler()))
 	})
 }
@@ -7,9 +7,15 @@ import (
 	sdk "github.com/opensourceways/go-gitee/gitee"
 
 	"github.com/opensourceways/defect-manager/issue"
+	"github.com/opensourceways/defect-manager/metrics"
 )
 
 const (
+	eventTypeIssue   = "Issue"
+	eventTypeNote    = "Note"
+	eventTypeInvalid = "invalid"
+	eventTypeOther   = "other"
+
 	msgHeaderUUID      = "X-Gitee-Timestamp"
 	msgHeaderUserAgent = "User-Agent"
 	msgHeaderEventType = "X-Gitee-Event"
@@ -23,9 +29,13 @@ type giteeEventHandler struct {
 func (msg *giteeEventHandler) handle(payload []byte, header map[string]string) error {
 	eventType, err := msg.parseRequest(header)
 	if err != nil {
+		metrics.EventsReceived.WithLabelValues(eventTypeInvalid).Inc()
+
 		return fmt.Errorf("invalid msg, err:%s", err.Error())
 	}
 
+	metrics.EventsReceived.WithLabelValues(eventTypeLabel(eventType)).Inc()
+
 	switch eventType {
 	case sdk.EventTypeIssue:
 		e, err := sdk.ConvertToIssueEvent(payload)
@@ -48,6 +58,18 @@ func (msg *giteeEventHandler) handle(payload []byte, header map[string]string) e
 	}
 }
 
+// eventTypeLabel keeps the label bounded, the header is set by the sender
+func eventTypeLabel(eventType string) string {
+	switch eventType {
+	case sdk.EventTypeIssue:
+		return eventTypeIssue
+	case sdk.EventTypeNote:
+		return eventTypeNote
+	default:
+		return eventTypeOther
+	}
+}
+
 func (msg *giteeEventHandler) parseRequest(header map[string]string) (
 	eventType string, err error,
 ) {
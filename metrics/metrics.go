@@ -0,0 +1,109 @@
+package metrics
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "defect_manager"
+
+const (
+	ResultSuccess = "success"
+	ResultFailure = "failure"
+)
+
+// DefBuckets is the buckets in seconds for the durations of remote calls
+var DefBuckets = []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10, 30, 60}
+
+var (
+	// EventsReceived type is Issue, Note, other or invalid if the message is rejected
+	EventsReceived = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "events_received_total",
+		Help:      "The number of gitee events received.",
+	}, []string{"type"})
+
+	// ParseFailures field is the item of issue or comment which fails to be parsed,
+	// it is counted once for each event.
+	ParseFailures = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "parse_failures_total",
+		Help:      "The number of items of issue or comment which fail to be parsed.",
+	}, []string{"field"})
+
+	// Approvals result is accepted or why the approval is refused
+	Approvals = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "approvals_total",
+		Help:      "The number of approvals of defect by committers.",
+	}, []string{"result"})
+
+	GiteeRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "gitee_requests_total",
+		Help:      "The number of requests to gitee api.",
+	}, []string{"method"})
+
+	GiteeRequestErrors = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "gitee_request_errors_total",
+		Help:      "The number of failed requests to gitee api.",
+	}, []string{"method"})
+
+	// CommitterCacheRefreshes result is failure if the committers of any sig are not refreshed
+	CommitterCacheRefreshes = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "committer_cache_refreshes_total",
+		Help:      "The number of refreshes of the committer cache.",
+	}, []string{"result"})
+
+	ProductTreeFetchDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "product_tree_fetch_duration_seconds",
+		Help:      "The duration of fetching the rpms of a version for the product tree.",
+		Buckets:   DefBuckets,
+	}, []string{"source", "result"})
+
+	BulletinsGenerated = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "bulletins_generated_total",
+		Help:      "The number of bulletins generated and uploaded.",
+	})
+
+	// BulletinsFailed reason is the step where the bulletin failed
+	BulletinsFailed = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "bulletins_failed_total",
+		Help:      "The number of bulletins which failed to be generated.",
+	}, []string{"reason"})
+
+	OBSUploadDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "obs_upload_duration_seconds",
+		Help:      "The duration of uploading files to obs.",
+		Buckets:   DefBuckets,
+	}, []string{"result"})
+)
+
+// Handler serves the metrics of the default registry
+func Handler() http.Handler {
+	return promhttp.Handler()
+}
+
+// Result returns the value of result label by err
+func Result(err error) string {
+	if err != nil {
+		return ResultFailure
+	}
+
+	return ResultSuccess
+}
+
+// Since returns the seconds elapsed since start
+func Since(start time.Time) float64 {
+	return time.Since(start).Seconds()
+}
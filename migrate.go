@@ -0,0 +1,138 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+
+	postgres "github.com/opensourceways/server-common-lib/postgre"
+	"github.com/sirupsen/logrus"
+
+	"github.com/opensourceways/defect-manager/config"
+	"github.com/opensourceways/defect-manager/migration"
+)
+
+const (
+	cmdMigrate       = "migrate"
+	cmdMigrateUp     = "up"
+	cmdMigrateDown   = "down"
+	cmdMigrateStatus = "status"
+)
+
+type migrateOptions struct {
+	configFile string
+	steps      int
+}
+
+func (o *migrateOptions) Validate(action string) error {
+	if o.configFile == "" {
+		return errors.New("missing config-file")
+	}
+
+	if action == cmdMigrateDown && o.steps <= 0 {
+		return errors.New("steps must be positive")
+	}
+
+	return nil
+}
+
+func newMigrator(cfg *config.Config) (*migration.Migrator, error) {
+	return migration.NewMigrator(
+		postgres.NewDBTable("").DB(),
+		&cfg.Migration,
+		migration.Tables{
+			Defect:           cfg.Table.Defect,
+			ComponentMapping: cfg.Table.ComponentMapping,
+			Version:          cfg.Table.Version,
+			Reference:        cfg.Table.Reference,
+			Bulletin:         cfg.Table.Bulletin,
+			Notification:     cfg.Table.Notification,
+			Outbox:           cfg.Table.Outbox,
+			Setting:          cfg.Table.Setting,
+			Generation:       cfg.Table.Generation,
+			Token:            cfg.Auth.Table.Token,
+			Audit:            cfg.Auth.Table.Audit,
+		},
+	)
+}
+
+// runMigrateCmd manages the schema of database, usage:
+// migrate up --config-file=xxx [--steps=n]
+// migrate down --config-file=xxx [--steps=n]
+// migrate status --config-file=xxx
+func runMigrateCmd(args []string) {
+	if len(args) == 0 ||
+		(args[0] != cmdMigrateUp && args[0] != cmdMigrateDown && args[0] != cmdMigrateStatus) {
+		logrus.Errorf("usage: migrate %s|%s|%s [options]", cmdMigrateUp, cmdMigrateDown, cmdMigrateStatus)
+
+		return
+	}
+
+	action := args[0]
+
+	var o migrateOptions
+	fs := flag.NewFlagSet(cmdMigrate, flag.ExitOnError)
+	fs.StringVar(&o.configFile, "config-file", "", "path to config file.")
+	fs.IntVar(&o.steps, "steps", 0, "number of migrations to apply or roll back, up applies all if 0, down defaults to 1.")
+	_ = fs.Parse(args[1:])
+
+	if action == cmdMigrateDown && o.steps == 0 {
+		o.steps = 1
+	}
+
+	if err := o.Validate(action); err != nil {
+		logrus.Errorf("invalid options, err:%s", err.Error())
+
+		return
+	}
+
+	cfg, err := config.LoadConfig(o.configFile)
+	if err != nil {
+		logrus.Errorf("load config, err:%s", err.Error())
+
+		return
+	}
+
+	if err = postgres.Init(&cfg.Postgres); err != nil {
+		logrus.Errorf("init db failed, err:%s", err.Error())
+
+		return
+	}
+
+	m, err := newMigrator(cfg)
+	if err != nil {
+		logrus.Errorf("load migrations failed, err:%s", err.Error())
+
+		return
+	}
+
+	var done []migration.Status
+
+	switch action {
+	case cmdMigrateUp:
+		done, err = m.Up(o.steps)
+
+	case cmdMigrateDown:
+		done, err = m.Down(o.steps)
+
+	case cmdMigrateStatus:
+		var items []migration.Status
+		items, err = m.Status()
+		for _, v := range items {
+			state := "pending"
+			if v.IsApplied() {
+				state = "applied at " + v.AppliedAt.Format("2006-01-02 15:04:05")
+			}
+
+			fmt.Printf("%04d_%s\t%s\n", v.Version, v.Name, state)
+		}
+	}
+
+	for _, v := range done {
+		fmt.Printf("%s %04d_%s\n", action, v.Version, v.Name)
+	}
+
+	if err != nil {
+		logrus.Errorf("migrate %s failed, err:%s", action, err.Error())
+	}
+}
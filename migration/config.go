@@ -0,0 +1,27 @@
+package migration
+
+type Config struct {
+	Table string `json:"table"`
+}
+
+func (c *Config) SetDefault() {
+	if c.Table == "" {
+		c.Table = "schema_migrations"
+	}
+}
+
+// Tables are the names of the tables managed by the migrations,
+// they are referenced in the sql files as {{.Defect}} and so on.
+type Tables struct {
+	Defect           string
+	ComponentMapping string
+	Version          string
+	Reference        string
+	Bulletin         string
+	Notification     string
+	Outbox           string
+	Setting          string
+	Generation       string
+	Token            string
+	Audit            string
+}
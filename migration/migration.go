@@ -0,0 +1,339 @@
+package migration
+
+import (
+	"bytes"
+	"embed"
+	"errors"
+	"fmt"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"text/template"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+// lockKey is the key of postgres advisory lock which serializes
+// the migrations run by the replicas started at the same time.
+const lockKey = 7301190
+
+const (
+	directionUp   = "up"
+	directionDown = "down"
+)
+
+//go:embed sql/*.sql
+var sqlFiles embed.FS
+
+var fileNameRe = regexp.MustCompile(`^(\d+)_(\w+)\.(up|down)\.sql$`)
+
+var ErrSchemaNewer = errors.New("the schema of database is newer than the binary")
+
+type migration struct {
+	version int
+	name    string
+	up      string
+	down    string
+}
+
+type migrationDO struct {
+	Version   int       `gorm:"column:version"`
+	Name      string    `gorm:"column:name"`
+	AppliedAt time.Time `gorm:"column:applied_at"`
+}
+
+// Status is the state of a migration, AppliedAt is zero if it is pending.
+type Status struct {
+	Version   int
+	Name      string
+	AppliedAt time.Time
+}
+
+func (s Status) IsApplied() bool {
+	return !s.AppliedAt.IsZero()
+}
+
+type Migrator struct {
+	db         *gorm.DB
+	table      string
+	migrations []migration
+}
+
+func NewMigrator(db *gorm.DB, cfg *Config, tables Tables) (*Migrator, error) {
+	ms, err := loadMigrations(tables)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Migrator{
+		db:         db,
+		table:      cfg.Table,
+		migrations: ms,
+	}, nil
+}
+
+// Latest is the version of the newest migration shipped with the binary.
+func (m *Migrator) Latest() int {
+	if len(m.migrations) == 0 {
+		return 0
+	}
+
+	return m.migrations[len(m.migrations)-1].version
+}
+
+// Prepare is called on startup, it refuses to run against a newer schema
+// and applies the pending migrations.
+func (m *Migrator) Prepare() error {
+	applied, err := m.Up(0)
+	for _, v := range applied {
+		logrus.Infof("applied migration %d_%s", v.Version, v.Name)
+	}
+
+	return err
+}
+
+// Check returns ErrSchemaNewer if the database has been migrated by a newer binary.
+func (m *Migrator) Check() error {
+	if err := m.createTable(); err != nil {
+		return err
+	}
+
+	applied, err := m.applied()
+	if err != nil {
+		return err
+	}
+
+	return m.checkApplied(applied)
+}
+
+func (m *Migrator) Status() ([]Status, error) {
+	if err := m.createTable(); err != nil {
+		return nil, err
+	}
+
+	applied, err := m.applied()
+	if err != nil {
+		return nil, err
+	}
+
+	r := make([]Status, 0, len(m.migrations))
+	for _, v := range m.migrations {
+		s := Status{Version: v.version, Name: v.name}
+		if do, ok := applied[v.version]; ok {
+			s.AppliedAt = do.AppliedAt
+		}
+
+		r = append(r, s)
+	}
+
+	return r, m.checkApplied(applied)
+}
+
+// Up applies at most steps pending migrations in order, all of them if steps <= 0.
+func (m *Migrator) Up(steps int) ([]Status, error) {
+	if err := m.Check(); err != nil {
+		return nil, err
+	}
+
+	var r []Status
+	for i := range m.migrations {
+		if steps > 0 && len(r) >= steps {
+			break
+		}
+
+		item := &m.migrations[i]
+
+		done, err := m.run(item, directionUp)
+		if err != nil {
+			return r, fmt.Errorf("migrate up %d_%s, err:%s", item.version, item.name, err.Error())
+		}
+
+		if done {
+			r = append(r, Status{Version: item.version, Name: item.name, AppliedAt: time.Now()})
+		}
+	}
+
+	return r, nil
+}
+
+// Down rolls back the latest steps applied migrations.
+func (m *Migrator) Down(steps int) ([]Status, error) {
+	if steps <= 0 {
+		return nil, errors.New("steps must be positive")
+	}
+
+	if err := m.Check(); err != nil {
+		return nil, err
+	}
+
+	var r []Status
+	for i := len(m.migrations) - 1; i >= 0 && len(r) < steps; i-- {
+		item := &m.migrations[i]
+
+		done, err := m.run(item, directionDown)
+		if err != nil {
+			return r, fmt.Errorf("migrate down %d_%s, err:%s", item.version, item.name, err.Error())
+		}
+
+		if done {
+			r = append(r, Status{Version: item.version, Name: item.name})
+		}
+	}
+
+	return r, nil
+}
+
+// run executes the migration in a transaction, it returns false
+// if the migration is skipped because it is already in the expected state.
+func (m *Migrator) run(item *migration, direction string) (done bool, err error) {
+	err = m.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("SELECT pg_advisory_xact_lock(?)", lockKey).Error; err != nil {
+			return err
+		}
+
+		var n int64
+		if err := tx.Table(m.table).Where("version = ?", item.version).Count(&n).Error; err != nil {
+			return err
+		}
+
+		if (direction == directionUp) == (n > 0) {
+			return nil
+		}
+
+		if direction == directionUp {
+			if err := tx.Exec(item.up).Error; err != nil {
+				return err
+			}
+
+			do := migrationDO{Version: item.version, Name: item.name, AppliedAt: time.Now()}
+			if err := tx.Table(m.table).Create(&do).Error; err != nil {
+				return err
+			}
+		} else {
+			if err := tx.Exec(item.down).Error; err != nil {
+				return err
+			}
+
+			err := tx.Table(m.table).Where("version = ?", item.version).Delete(&migrationDO{}).Error
+			if err != nil {
+				return err
+			}
+		}
+
+		done = true
+
+		return nil
+	})
+
+	return
+}
+
+func (m *Migrator) createTable() error {
+	return m.db.Exec(fmt.Sprintf(
+		`CREATE TABLE IF NOT EXISTS %s (
+			version    integer PRIMARY KEY,
+			name       text NOT NULL,
+			applied_at timestamptz NOT NULL
+		)`, m.table,
+	)).Error
+}
+
+func (m *Migrator) applied() (map[int]migrationDO, error) {
+	var dos []migrationDO
+	if err := m.db.Table(m.table).Order("version").Find(&dos).Error; err != nil {
+		return nil, err
+	}
+
+	r := make(map[int]migrationDO, len(dos))
+	for _, v := range dos {
+		r[v.Version] = v
+	}
+
+	return r, nil
+}
+
+func (m *Migrator) checkApplied(applied map[int]migrationDO) error {
+	known := make(map[int]bool, len(m.migrations))
+	for _, v := range m.migrations {
+		known[v.version] = true
+	}
+
+	for _, v := range applied {
+		if !known[v.Version] {
+			return fmt.Errorf(
+				"%w, migration %d_%s is unknown, the latest of binary is %d",
+				ErrSchemaNewer, v.Version, v.Name, m.Latest(),
+			)
+		}
+	}
+
+	return nil
+}
+
+func loadMigrations(tables Tables) ([]migration, error) {
+	entries, err := sqlFiles.ReadDir("sql")
+	if err != nil {
+		return nil, err
+	}
+
+	ms := map[int]*migration{}
+	for _, e := range entries {
+		items := fileNameRe.FindStringSubmatch(e.Name())
+		if items == nil {
+			return nil, fmt.Errorf("invalid migration file name: %s", e.Name())
+		}
+
+		version, _ := strconv.Atoi(items[1])
+
+		item, ok := ms[version]
+		if !ok {
+			item = &migration{version: version, name: items[2]}
+			ms[version] = item
+		} else if item.name != items[2] {
+			return nil, fmt.Errorf("duplicate migration version: %d", version)
+		}
+
+		content, err := renderFile(path.Join("sql", e.Name()), tables)
+		if err != nil {
+			return nil, err
+		}
+
+		if items[3] == directionUp {
+			item.up = content
+		} else {
+			item.down = content
+		}
+	}
+
+	r := make([]migration, 0, len(ms))
+	for _, v := range ms {
+		if v.up == "" || v.down == "" {
+			return nil, fmt.Errorf("migration %d_%s must have both up and down", v.version, v.name)
+		}
+
+		r = append(r, *v)
+	}
+
+	sort.Slice(r, func(i, j int) bool {
+		return r[i].version < r[j].version
+	})
+
+	return r, nil
+}
+
+func renderFile(name string, tables Tables) (string, error) {
+	tmpl, err := template.ParseFS(sqlFiles, name)
+	if err != nil {
+		return "", err
+	}
+
+	buf := new(bytes.Buffer)
+	if err = tmpl.Execute(buf, tables); err != nil {
+		return "", err
+	}
+
+	return buf.String(), nil
+}
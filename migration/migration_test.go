@@ -0,0 +1,31 @@
+package migration
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestLoadMigrations(t *testing.T) {
+	ms, err := loadMigrations(Tables{
+		Defect: "defect", ComponentMapping: "mapping", Version: "version", Reference: "reference",
+		Bulletin: "bulletin", Notification: "notification", Outbox: "outbox", Setting: "setting", Generation: "generation",
+		Token: "token", Audit: "audit",
+	})
+	if err != nil {
+		t.Fatalf("load migrations failed, err:%s", err.Error())
+	}
+
+	for i, v := range ms {
+		if v.version != i+1 {
+			t.Errorf("migration %d_%s is out of order", v.version, v.name)
+		}
+
+		if strings.Contains(v.up, "{{") || strings.Contains(v.down, "{{") {
+			t.Errorf("migration %d_%s is not rendered", v.version, v.name)
+		}
+	}
+
+	if !strings.Contains(ms[0].up, "CREATE TABLE IF NOT EXISTS defect (") {
+		t.Errorf("unexpected sql: %s", ms[0].up)
+	}
+}
@@ -0,0 +1,24 @@
+CREATE TABLE IF NOT EXISTS {{.Defect}} (
+    id                bigserial PRIMARY KEY,
+    number            text,
+    title             text,
+    org               text,
+    repo              text,
+    status            text,
+    kernel            text,
+    component         text,
+    component_version text,
+    system_version    text,
+    description       text,
+    reference_url     text,
+    guidance_url      text,
+    influence         text,
+    severity_level    text,
+    affected_version  text[] DEFAULT '{}',
+    abi               text,
+    created_at        timestamptz,
+    updated_at        timestamptz
+);
+
+CREATE INDEX IF NOT EXISTS idx_{{.Defect}}_number ON {{.Defect}} (number);
+CREATE INDEX IF NOT EXISTS idx_{{.Defect}}_created_at ON {{.Defect}} (created_at);
@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS idx_{{.Defect}}_issue;
+
+-- the duplicates are restored, so it is the state before the up migration
+INSERT INTO {{.Defect}} SELECT * FROM {{.Defect}}_duplicate ON CONFLICT (id) DO NOTHING;
+
+DROP TABLE IF EXISTS {{.Defect}}_duplicate;
@@ -0,0 +1,16 @@
+-- the duplicates of an issue were saved by concurrent events before the unique index existed,
+-- the latest updated one is kept and the others are backed up for checking.
+CREATE TABLE IF NOT EXISTS {{.Defect}}_duplicate AS SELECT * FROM {{.Defect}} WITH NO DATA;
+
+INSERT INTO {{.Defect}}_duplicate
+SELECT * FROM {{.Defect}} WHERE id IN (
+    SELECT id FROM (
+        SELECT id, ROW_NUMBER() OVER (
+            PARTITION BY org, repo, number ORDER BY updated_at DESC NULLS LAST, id DESC
+        ) AS rn FROM {{.Defect}}
+    ) t WHERE rn > 1
+);
+
+DELETE FROM {{.Defect}} WHERE id IN (SELECT id FROM {{.Defect}}_duplicate);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_{{.Defect}}_issue ON {{.Defect}} (org, repo, number);
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS {{.Audit}};
+DROP TABLE IF EXISTS {{.Token}};
@@ -0,0 +1,27 @@
+CREATE TABLE IF NOT EXISTS {{.Token}} (
+    id         bigserial PRIMARY KEY,
+    name       text,
+    owner      text,
+    hash       text,
+    scopes     text[] DEFAULT '{}',
+    expires_at timestamptz,
+    revoked    boolean,
+    created_at timestamptz,
+    updated_at timestamptz
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_{{.Token}}_name ON {{.Token}} (name);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_{{.Token}}_hash ON {{.Token}} (hash);
+
+CREATE TABLE IF NOT EXISTS {{.Audit}} (
+    id         bigserial PRIMARY KEY,
+    operator   text,
+    source     text,
+    action     text,
+    target     text,
+    created_at timestamptz
+);
+
+CREATE INDEX IF NOT EXISTS idx_{{.Audit}}_operator ON {{.Audit}} (operator);
+CREATE INDEX IF NOT EXISTS idx_{{.Audit}}_action ON {{.Audit}} (action);
+CREATE INDEX IF NOT EXISTS idx_{{.Audit}}_created_at ON {{.Audit}} (created_at);
@@ -0,0 +1,12 @@
+-- system_version is empty if the mapping applies to all the versions
+CREATE TABLE IF NOT EXISTS {{.ComponentMapping}} (
+    id             bigserial PRIMARY KEY,
+    component      text NOT NULL,
+    system_version text NOT NULL DEFAULT '',
+    source_package text NOT NULL,
+    created_at     timestamptz,
+    updated_at     timestamptz
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_{{.ComponentMapping}}_component
+    ON {{.ComponentMapping}} (lower(component), system_version);
@@ -0,0 +1,14 @@
+CREATE TABLE IF NOT EXISTS {{.Version}} (
+    id         bigserial PRIMARY KEY,
+    version    text NOT NULL,
+    branch     text NOT NULL DEFAULT '',
+    lts        boolean NOT NULL DEFAULT false,
+    sp         boolean NOT NULL DEFAULT false,
+    ga_date    timestamptz,
+    eol_date   timestamptz,
+    retired    boolean NOT NULL DEFAULT false,
+    created_at timestamptz,
+    updated_at timestamptz
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_{{.Version}}_version ON {{.Version}} (version);
@@ -0,0 +1,2 @@
+ALTER TABLE {{.Defect}} DROP COLUMN IF EXISTS releases;
+ALTER TABLE {{.Defect}} DROP COLUMN IF EXISTS fixed_version;
@@ -0,0 +1,2 @@
+ALTER TABLE {{.Defect}} ADD COLUMN IF NOT EXISTS fixed_version text NOT NULL DEFAULT '';
+ALTER TABLE {{.Defect}} ADD COLUMN IF NOT EXISTS releases jsonb NOT NULL DEFAULT '[]';
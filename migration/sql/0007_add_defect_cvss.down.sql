@@ -0,0 +1,2 @@
+ALTER TABLE {{.Defect}} DROP COLUMN IF EXISTS cvss_score;
+ALTER TABLE {{.Defect}} DROP COLUMN IF EXISTS cvss_vector;
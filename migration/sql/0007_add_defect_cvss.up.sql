@@ -0,0 +1,2 @@
+ALTER TABLE {{.Defect}} ADD COLUMN IF NOT EXISTS cvss_vector text NOT NULL DEFAULT '';
+ALTER TABLE {{.Defect}} ADD COLUMN IF NOT EXISTS cvss_score numeric(3, 1) NOT NULL DEFAULT 0;
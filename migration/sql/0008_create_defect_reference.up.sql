@@ -0,0 +1,10 @@
+CREATE TABLE IF NOT EXISTS {{.Reference}} (
+    id         bigserial PRIMARY KEY,
+    defect_id  bigint NOT NULL REFERENCES {{.Defect}} (id) ON DELETE CASCADE,
+    type       text NOT NULL,
+    ref_id     text NOT NULL,
+    created_at timestamptz
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_{{.Reference}}_defect ON {{.Reference}} (defect_id, type, ref_id);
+CREATE INDEX IF NOT EXISTS idx_{{.Reference}}_ref_id ON {{.Reference}} (lower(ref_id));
@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS {{.Bulletin}} (
+    id             bigserial PRIMARY KEY,
+    identification text NOT NULL,
+    number         text NOT NULL,
+    org            text NOT NULL,
+    repo           text NOT NULL,
+    component      text NOT NULL,
+    published_at   timestamptz NOT NULL
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_{{.Bulletin}}_member ON {{.Bulletin}} (identification, org, repo, number);
+CREATE INDEX IF NOT EXISTS idx_{{.Bulletin}}_issue ON {{.Bulletin}} (org, repo, number);
@@ -0,0 +1,16 @@
+CREATE TABLE IF NOT EXISTS {{.Notification}} (
+    id               bigserial PRIMARY KEY,
+    identification   text NOT NULL,
+    component        text NOT NULL,
+    affected_version text[] NOT NULL DEFAULT '{}',
+    issue_number     text[] NOT NULL DEFAULT '{}',
+    file             text NOT NULL,
+    published_at     timestamptz NOT NULL,
+    status           text NOT NULL,
+    attempts         integer NOT NULL DEFAULT 0,
+    last_error       text NOT NULL DEFAULT '',
+    updated_at       timestamptz NOT NULL
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_{{.Notification}}_identification ON {{.Notification}} (identification);
+CREATE INDEX IF NOT EXISTS idx_{{.Notification}}_status ON {{.Notification}} (status);
@@ -0,0 +1,15 @@
+CREATE TABLE IF NOT EXISTS {{.Outbox}} (
+    id           bigserial PRIMARY KEY,
+    event_id     text NOT NULL,
+    type         text NOT NULL,
+    version      text NOT NULL,
+    key          text NOT NULL,
+    body         jsonb NOT NULL,
+    occurred_at  timestamptz NOT NULL,
+    published_at timestamptz,
+    attempts     integer NOT NULL DEFAULT 0,
+    last_error   text NOT NULL DEFAULT ''
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_{{.Outbox}}_event_id ON {{.Outbox}} (event_id);
+CREATE INDEX IF NOT EXISTS idx_{{.Outbox}}_pending ON {{.Outbox}} (id) WHERE published_at IS NULL;
@@ -0,0 +1,5 @@
+CREATE TABLE IF NOT EXISTS {{.Setting}} (
+    name       text PRIMARY KEY,
+    value      text NOT NULL,
+    updated_at timestamptz NOT NULL
+);
@@ -0,0 +1,9 @@
+CREATE TABLE IF NOT EXISTS {{.Generation}} (
+    id         text PRIMARY KEY,
+    issues     text[] NOT NULL DEFAULT '{}',
+    status     text NOT NULL,
+    report     jsonb,
+    error      text NOT NULL DEFAULT '',
+    created_at timestamptz NOT NULL,
+    updated_at timestamptz NOT NULL
+);
@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_{{.Outbox}}_pending;
+CREATE INDEX IF NOT EXISTS idx_{{.Outbox}}_pending ON {{.Outbox}} (id) WHERE published_at IS NULL;
+
+ALTER TABLE {{.Outbox}} DROP COLUMN IF EXISTS parked_at;
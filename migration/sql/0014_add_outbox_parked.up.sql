@@ -0,0 +1,4 @@
+ALTER TABLE {{.Outbox}} ADD COLUMN IF NOT EXISTS parked_at timestamptz;
+
+DROP INDEX IF EXISTS idx_{{.Outbox}}_pending;
+CREATE INDEX IF NOT EXISTS idx_{{.Outbox}}_pending ON {{.Outbox}} (id) WHERE published_at IS NULL AND parked_at IS NULL;
@@ -0,0 +1,103 @@
+package scheduler
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/opensourceways/defect-manager/defect/domain"
+	"github.com/opensourceways/defect-manager/defect/domain/dp"
+	"github.com/opensourceways/defect-manager/defect/infrastructure/notifierimpl"
+	"github.com/opensourceways/defect-manager/utils"
+)
+
+// Config the bulletins are generated automatically only if Enabled, the jobs can also be paused at runtime.
+// Timezone is the location of the cron expressions, the summary of each run is posted to Summary if its url is set.
+type Config struct {
+	Enabled  bool                 `json:"enabled"`
+	Timezone string               `json:"timezone"`
+	Jobs     []Job                `json:"jobs"`
+	Summary  notifierimpl.Webhook `json:"summary"`
+}
+
+// Job collects the unpublished defects recorded in the Window days before the HoldBack hours,
+// the defects recorded in the latest HoldBack hours are left for review.
+// The defects are kept only if their severity level is in Severity and their component is in Components
+// and not in ExcludedComponents, the empty list means no limit.
+type Job struct {
+	Name               string   `json:"name"                required:"true"`
+	Cron               string   `json:"cron"                required:"true"`
+	Window             int      `json:"window"`
+	HoldBack           int      `json:"hold_back"`
+	Grouping           string   `json:"grouping"`
+	Severity           []string `json:"severity"`
+	Components         []string `json:"components"`
+	ExcludedComponents []string `json:"excluded_components"`
+}
+
+func (c *Config) SetDefault() {
+	if c.Timezone == "" {
+		c.Timezone = "UTC"
+	}
+
+	for i := range c.Jobs {
+		c.Jobs[i].setDefault()
+	}
+
+	c.Summary.SetDefault()
+}
+
+func (c *Config) Validate() error {
+	if _, err := time.LoadLocation(c.Timezone); err != nil {
+		return err
+	}
+
+	names := make(map[string]bool)
+	for i := range c.Jobs {
+		j := &c.Jobs[i]
+		if names[j.Name] {
+			return fmt.Errorf("duplicate job %s", j.Name)
+		}
+
+		names[j.Name] = true
+
+		if err := j.validate(); err != nil {
+			return fmt.Errorf("job %s, %s", j.Name, err.Error())
+		}
+	}
+
+	return nil
+}
+
+func (j *Job) setDefault() {
+	if j.Window <= 0 {
+		j.Window = 30
+	}
+
+	if j.HoldBack < 0 {
+		j.HoldBack = 0
+	}
+
+	if j.Grouping == "" {
+		j.Grouping = domain.GroupingAuto
+	}
+}
+
+func (j *Job) validate() error {
+	if _, err := utils.ParseCron(j.Cron); err != nil {
+		return err
+	}
+
+	// the groups can't be specified in advance
+	if !domain.IsValidGrouping(j.Grouping) || j.Grouping == domain.GroupingManual {
+		return errors.New("invalid grouping " + j.Grouping)
+	}
+
+	for _, v := range j.Severity {
+		if _, err := dp.NewSeverityLevel(v); err != nil {
+			return fmt.Errorf("%s: %s", err.Error(), v)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,369 @@
+package scheduler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	"github.com/opensourceways/defect-manager/defect/app"
+	"github.com/opensourceways/defect-manager/defect/domain"
+	"github.com/opensourceways/defect-manager/defect/domain/repository"
+	"github.com/opensourceways/defect-manager/defect/infrastructure/notifierimpl"
+	"github.com/opensourceways/defect-manager/utils"
+)
+
+// settingPaused is the name of the setting which keeps the kill switch,
+// so it is shared by the replicas and survives the restarts.
+const settingPaused = "scheduler_paused"
+
+var instance *Scheduler
+
+func Init(cfg *Config, s app.DefectService, settings repository.SettingRepository) {
+	loc, err := time.LoadLocation(cfg.Timezone)
+	if err != nil {
+		loc = time.UTC
+	}
+
+	instance = newScheduler(cfg, s, settings, loc)
+}
+
+func Instance() *Scheduler {
+	return instance
+}
+
+// RunSummary is what a run of job produced, Skipped is the count of defects filtered out by the policies
+type RunSummary struct {
+	Job       string             `json:"job"`
+	StartedAt string             `json:"started_at"`
+	BeginTime string             `json:"begin_time"`
+	EndTime   string             `json:"end_time"`
+	Collected int                `json:"collected"`
+	Skipped   int                `json:"skipped"`
+	Bulletins []app.BulletinDTO  `json:"bulletins"`
+	Excluded  []app.ExclusionDTO `json:"excluded"`
+	Error     string             `json:"error"`
+}
+
+// isEmpty the issues which are already published are not reported again, they are published
+// by an earlier run or another replica which ran the same tick after this one finished.
+func (r *RunSummary) isEmpty() bool {
+	if r.Error != "" || len(r.Bulletins) > 0 {
+		return false
+	}
+
+	for i := range r.Excluded {
+		if r.Excluded[i].Reason != domain.ExclusionPublished {
+			return false
+		}
+	}
+
+	return true
+}
+
+type StatusDTO struct {
+	Enabled bool           `json:"enabled"`
+	Paused  bool           `json:"paused"`
+	Jobs    []JobStatusDTO `json:"jobs"`
+}
+
+// JobStatusDTO NextRun is empty if the scheduler is not running
+type JobStatusDTO struct {
+	Name    string      `json:"name"`
+	Cron    string      `json:"cron"`
+	NextRun string      `json:"next_run"`
+	LastRun *RunSummary `json:"last_run,omitempty"`
+}
+
+type job struct {
+	cfg  *Job
+	cron utils.Cron
+
+	// they are guarded by the lock of scheduler
+	next    time.Time
+	lastRun *RunSummary
+}
+
+// filter returns the issues of defects which match the policies of job
+func (j *job) filter(ds []app.CollectDefectsDTO) []domain.Issue {
+	severity := sets.NewString(j.cfg.Severity...)
+	components := sets.NewString(j.cfg.Components...)
+	excluded := sets.NewString(j.cfg.ExcludedComponents...)
+
+	var r []domain.Issue
+	for i := range ds {
+		d := &ds[i]
+
+		if severity.Len() > 0 && !severity.Has(d.SeverityLevel) {
+			continue
+		}
+
+		if components.Len() > 0 && !components.Has(d.Component) {
+			continue
+		}
+
+		if excluded.Has(d.Component) {
+			continue
+		}
+
+		r = append(r, domain.Issue{Org: d.Org, Repo: d.Repo, Number: d.Number})
+	}
+
+	return r
+}
+
+// Scheduler generates the bulletins periodically, each job runs in its own goroutine,
+// a run is skipped if it is paused when the time comes.
+type Scheduler struct {
+	cfg      *Config
+	service  app.DefectService
+	settings repository.SettingRepository
+	loc      *time.Location
+	jobs     []*job
+	clock    utils.Clock
+	cli      *http.Client
+
+	lock sync.Mutex
+
+	stop chan struct{}
+	wg   sync.WaitGroup
+}
+
+func newScheduler(
+	cfg *Config, s app.DefectService, settings repository.SettingRepository, loc *time.Location,
+) *Scheduler {
+	sch := &Scheduler{
+		cfg:      cfg,
+		service:  s,
+		settings: settings,
+		loc:      loc,
+		clock:    utils.SystemClock{},
+		cli:      notifierimpl.NewWebhookClient(&cfg.Summary),
+	}
+
+	for i := range cfg.Jobs {
+		// it has been validated
+		c, _ := utils.ParseCron(cfg.Jobs[i].Cron)
+
+		sch.jobs = append(sch.jobs, &job{cfg: &cfg.Jobs[i], cron: c})
+	}
+
+	return sch
+}
+
+// Start does nothing if it is disabled
+func (s *Scheduler) Start() {
+	if !s.cfg.Enabled || s.stop != nil {
+		return
+	}
+
+	s.stop = make(chan struct{})
+
+	for _, j := range s.jobs {
+		s.wg.Add(1)
+
+		go func(j *job) {
+			defer s.wg.Done()
+
+			s.loop(j)
+		}(j)
+	}
+}
+
+// Stop waits for the running jobs to finish
+func (s *Scheduler) Stop() {
+	if s.stop == nil {
+		return
+	}
+
+	close(s.stop)
+	s.wg.Wait()
+}
+
+// Pause is the kill switch of all the replicas, the runs are skipped until Resume
+func (s *Scheduler) Pause() error {
+	return s.settings.SaveSetting(settingPaused, strconv.FormatBool(true))
+}
+
+func (s *Scheduler) Resume() error {
+	return s.settings.SaveSetting(settingPaused, strconv.FormatBool(false))
+}
+
+func (s *Scheduler) isPaused() (bool, error) {
+	v, err := s.settings.FindSetting(settingPaused)
+	if err != nil {
+		if errors.Is(err, repository.ErrSettingNotFound) {
+			return false, nil
+		}
+
+		return false, err
+	}
+
+	return strconv.ParseBool(v)
+}
+
+func (s *Scheduler) Status() (StatusDTO, error) {
+	paused, err := s.isPaused()
+	if err != nil {
+		return StatusDTO{}, err
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	dto := StatusDTO{
+		Enabled: s.cfg.Enabled,
+		Paused:  paused,
+		Jobs:    make([]JobStatusDTO, len(s.jobs)),
+	}
+
+	for i, j := range s.jobs {
+		dto.Jobs[i] = JobStatusDTO{
+			Name:    j.cfg.Name,
+			Cron:    j.cfg.Cron,
+			LastRun: j.lastRun,
+		}
+
+		if !j.next.IsZero() {
+			dto.Jobs[i].NextRun = j.next.Format(time.RFC3339)
+		}
+	}
+
+	return dto, nil
+}
+
+func (s *Scheduler) loop(j *job) {
+	for {
+		now := s.clock.Now().In(s.loc)
+
+		next := j.cron.Next(now)
+		if next.IsZero() {
+			logrus.Errorf("job %s will never run, cron: %s", j.cfg.Name, j.cfg.Cron)
+
+			return
+		}
+
+		s.lock.Lock()
+		j.next = next
+		s.lock.Unlock()
+
+		timer := time.NewTimer(next.Sub(now))
+
+		select {
+		case <-s.stop:
+			timer.Stop()
+
+			return
+
+		case <-timer.C:
+		}
+
+		s.tick(j, next)
+	}
+}
+
+// tick runs the job at the time if it is not paused. Only one replica runs a job at a time,
+// the others skip the tick.
+func (s *Scheduler) tick(j *job, at time.Time) {
+	// the run is skipped if the switch is unknown, it is safer than generating unexpectedly
+	if paused, err := s.isPaused(); err != nil || paused {
+		if err != nil {
+			logrus.Errorf("job %s, get the switch error: %s, skip the run", j.cfg.Name, err.Error())
+		} else {
+			logrus.Infof("job %s is paused, skip the run at %s", j.cfg.Name, at.Format(time.RFC3339))
+		}
+
+		return
+	}
+
+	unlock, locked, err := s.settings.LockJob(j.cfg.Name)
+	if err != nil {
+		logrus.Errorf("job %s, lock the job error: %s, skip the run", j.cfg.Name, err.Error())
+
+		return
+	}
+
+	if !locked {
+		logrus.Infof("job %s is run by another replica, skip the run at %s", j.cfg.Name, at.Format(time.RFC3339))
+
+		return
+	}
+
+	defer unlock()
+
+	summary := s.run(j, at)
+
+	s.lock.Lock()
+	j.lastRun = &summary
+	s.lock.Unlock()
+
+	s.notify(&summary)
+}
+
+// run collects the defects in the window ending at the hold-back time before at, and generates the bulletins
+func (s *Scheduler) run(j *job, at time.Time) RunSummary {
+	end := at.Add(-time.Duration(j.cfg.HoldBack) * time.Hour)
+	begin := end.AddDate(0, 0, -j.cfg.Window)
+
+	summary := RunSummary{
+		Job:       j.cfg.Name,
+		StartedAt: s.clock.Now().Format(time.RFC3339),
+		BeginTime: begin.Format(time.RFC3339),
+		EndTime:   end.Format(time.RFC3339),
+	}
+
+	defects, err := s.service.CollectDefects(app.CmdToCollectDefects{BeginTime: begin, EndTime: end})
+	if err != nil {
+		logrus.Errorf("job %s, collect defects error: %s", j.cfg.Name, err.Error())
+
+		summary.Error = err.Error()
+
+		return summary
+	}
+
+	issues := j.filter(defects)
+
+	summary.Collected = len(defects)
+	summary.Skipped = len(defects) - len(issues)
+
+	if len(issues) == 0 {
+		return summary
+	}
+
+	report, err := s.service.GenerateBulletins(app.CmdToGenerateBulletins{
+		Issues:   issues,
+		Grouping: j.cfg.Grouping,
+	})
+	if err != nil {
+		logrus.Errorf("job %s, generate bulletins of %d issues error: %s", j.cfg.Name, len(issues), err.Error())
+
+		summary.Error = err.Error()
+
+		return summary
+	}
+
+	summary.Bulletins = report.Bulletins
+	summary.Excluded = report.Excluded
+
+	return summary
+}
+
+// notify posts the summary if it is not empty
+func (s *Scheduler) notify(summary *RunSummary) {
+	logrus.Infof(
+		"job %s produced %d bulletins, %d issues are excluded, %d defects are skipped",
+		summary.Job, len(summary.Bulletins), len(summary.Excluded), summary.Skipped,
+	)
+
+	if s.cfg.Summary.URL == "" || summary.isEmpty() {
+		return
+	}
+
+	if err := notifierimpl.PostJSON(s.cli, &s.cfg.Summary, summary); err != nil {
+		logrus.Errorf("job %s, post the summary error: %s", summary.Job, err.Error())
+	}
+}
@@ -0,0 +1,228 @@
+package scheduler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/opensourceways/defect-manager/defect/app"
+	"github.com/opensourceways/defect-manager/defect/domain"
+	"github.com/opensourceways/defect-manager/defect/domain/repository"
+)
+
+type fakeService struct {
+	app.DefectService
+
+	defects   []app.CollectDefectsDTO
+	collected app.CmdToCollectDefects
+	generated []string
+}
+
+func (s *fakeService) CollectDefects(cmd app.CmdToCollectDefects) ([]app.CollectDefectsDTO, error) {
+	s.collected = cmd
+
+	return s.defects, nil
+}
+
+func (s *fakeService) GenerateBulletins(cmd app.CmdToGenerateBulletins) (app.BulletinsReportDTO, error) {
+	for i := range cmd.Issues {
+		s.generated = append(s.generated, cmd.Issues[i].Key())
+	}
+
+	return app.BulletinsReportDTO{
+		Bulletins: []app.BulletinDTO{{Identification: "cvrf-openEuler-BA-2023-1001", Issues: s.generated}},
+	}, nil
+}
+
+// fakeSettings is the settings shared by the replicas
+type fakeSettings map[string]string
+
+func (s fakeSettings) FindSetting(name string) (string, error) {
+	if v, ok := s[name]; ok {
+		return v, nil
+	}
+
+	return "", repository.ErrSettingNotFound
+}
+
+func (s fakeSettings) SaveSetting(name, value string) error {
+	s[name] = value
+
+	return nil
+}
+
+// LockJob the lock is kept in the settings, so it is shared by the replicas too
+func (s fakeSettings) LockJob(name string) (func(), bool, error) {
+	key := "lock/" + name
+	if _, ok := s[key]; ok {
+		return nil, false, nil
+	}
+
+	s[key] = ""
+
+	return func() { delete(s, key) }, true, nil
+}
+
+func TestRun(t *testing.T) {
+	var posted RunSummary
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+
+			return
+		}
+
+		_ = json.NewDecoder(r.Body).Decode(&posted)
+	}))
+	defer server.Close()
+
+	cfg := &Config{
+		Enabled: true,
+		Jobs: []Job{{
+			Name:               "daily",
+			Cron:               "0 2 * * *",
+			Window:             7,
+			HoldBack:           24,
+			Severity:           []string{"Critical", "High"},
+			ExcludedComponents: []string{"kernel"},
+		}},
+	}
+	cfg.Summary.URL = server.URL
+	cfg.Summary.Token = "secret"
+	cfg.SetDefault()
+
+	if err := cfg.Validate(); err != nil {
+		t.Fatal(err)
+	}
+
+	service := &fakeService{defects: []app.CollectDefectsDTO{
+		{Number: "I1", Org: "src-openeuler", Repo: "zbar", Component: "zbar", SeverityLevel: "High"},
+		{Number: "I2", Org: "src-openeuler", Repo: "curl", Component: "curl", SeverityLevel: "Low"},
+		{Number: "I3", Org: "src-openeuler", Repo: "kernel", Component: "kernel", SeverityLevel: "Critical"},
+		{Number: "I1", Org: "src-openeuler", Repo: "vim", Component: "vim", SeverityLevel: "Critical"},
+	}}
+
+	s := newScheduler(cfg, service, fakeSettings{}, time.UTC)
+
+	at := time.Date(2023, 10, 16, 2, 0, 0, 0, time.UTC)
+	summary := s.run(s.jobs[0], at)
+	s.notify(&summary)
+
+	// the defects recorded in the latest day are held back
+	if !service.collected.EndTime.Equal(at.Add(-24*time.Hour)) ||
+		!service.collected.BeginTime.Equal(at.AddDate(0, 0, -8)) {
+		t.Errorf("unexpected window: %v", service.collected)
+	}
+
+	// the issues of the same number in different repos are both generated
+	if got := strings.Join(service.generated, ","); got != "src-openeuler/zbar/I1,src-openeuler/vim/I1" {
+		t.Errorf("generated %s", got)
+	}
+
+	if summary.Collected != 4 || summary.Skipped != 2 || len(summary.Bulletins) != 1 {
+		t.Errorf("unexpected summary: %v", summary)
+	}
+
+	if posted.Job != "daily" || len(posted.Bulletins) != 1 {
+		t.Errorf("unexpected posted summary: %v", posted)
+	}
+}
+
+func TestInvalidJob(t *testing.T) {
+	jobs := []Job{
+		{Name: "a", Cron: "0 2 * *"},
+		{Name: "b", Cron: "@daily", Grouping: "manual"},
+		{Name: "c", Cron: "@daily", Severity: []string{"Urgent"}},
+	}
+
+	for i := range jobs {
+		cfg := &Config{Jobs: jobs[i : i+1]}
+		cfg.SetDefault()
+
+		if err := cfg.Validate(); err == nil {
+			t.Errorf("job %s: expect error", jobs[i].Name)
+		}
+	}
+}
+
+func TestPauseOfReplicas(t *testing.T) {
+	cfg := &Config{Enabled: true}
+	cfg.SetDefault()
+
+	settings := fakeSettings{}
+	a := newScheduler(cfg, &fakeService{}, settings, time.UTC)
+	b := newScheduler(cfg, &fakeService{}, settings, time.UTC)
+
+	if paused, err := b.isPaused(); err != nil || paused {
+		t.Fatalf("it is not paused by default, got %v, %v", paused, err)
+	}
+
+	if err := a.Pause(); err != nil {
+		t.Fatal(err)
+	}
+
+	if v, err := b.Status(); err != nil || !v.Paused {
+		t.Errorf("the replica must be paused, got %v, %v", v, err)
+	}
+
+	if err := b.Resume(); err != nil {
+		t.Fatal(err)
+	}
+
+	if paused, _ := a.isPaused(); paused {
+		t.Error("the replica must be resumed")
+	}
+}
+
+func TestTickOfReplicas(t *testing.T) {
+	cfg := &Config{Enabled: true, Jobs: []Job{{Name: "daily", Cron: "@daily"}}}
+	cfg.SetDefault()
+
+	settings := fakeSettings{}
+	service := &fakeService{defects: []app.CollectDefectsDTO{
+		{Number: "I1", Org: "src-openeuler", Repo: "zbar", Component: "zbar", SeverityLevel: "High"},
+	}}
+	s := newScheduler(cfg, service, settings, time.UTC)
+
+	// another replica is running the job
+	unlock, locked, _ := settings.LockJob("daily")
+	if !locked {
+		t.Fatal("the job must be locked")
+	}
+
+	at := time.Date(2023, 10, 16, 0, 0, 0, 0, time.UTC)
+	s.tick(s.jobs[0], at)
+
+	if len(service.generated) != 0 || s.jobs[0].lastRun != nil {
+		t.Fatalf("the tick must be skipped, generated %v", service.generated)
+	}
+
+	unlock()
+	s.tick(s.jobs[0], at)
+
+	if len(service.generated) != 1 || s.jobs[0].lastRun == nil {
+		t.Errorf("the job must run after the lock is released, generated %v", service.generated)
+	}
+}
+
+func TestSummaryOfPublished(t *testing.T) {
+	summary := RunSummary{Excluded: []app.ExclusionDTO{
+		{Issue: "src-openeuler/zbar/I1", Reason: domain.ExclusionPublished},
+	}}
+
+	// the issues are published by the replica which ran the tick before
+	if !summary.isEmpty() {
+		t.Error("the summary of the published issues must be empty")
+	}
+
+	summary.Excluded = append(summary.Excluded, app.ExclusionDTO{
+		Issue: "src-openeuler/zbar/I2", Reason: domain.ExclusionNotReleased,
+	})
+
+	if summary.isEmpty() {
+		t.Error("the summary of the unreleased issues must not be empty")
+	}
+}
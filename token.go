@@ -0,0 +1,143 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"strings"
+
+	postgres "github.com/opensourceways/server-common-lib/postgre"
+	"github.com/sirupsen/logrus"
+
+	authapp "github.com/opensourceways/defect-manager/auth/app"
+	"github.com/opensourceways/defect-manager/auth/domain/dp"
+	authrepositoryimpl "github.com/opensourceways/defect-manager/auth/infrastructure/repositoryimpl"
+	"github.com/opensourceways/defect-manager/config"
+)
+
+const (
+	cmdToken       = "token"
+	cmdTokenCreate = "create"
+	cmdTokenRevoke = "revoke"
+)
+
+type tokenOptions struct {
+	configFile string
+	name       string
+	owner      string
+	scopes     string
+	validDays  int
+}
+
+func (o *tokenOptions) Validate(action string) error {
+	if o.configFile == "" || o.name == "" {
+		return errors.New("missing config-file or name")
+	}
+
+	if action == cmdTokenCreate && (o.owner == "" || o.scopes == "") {
+		return errors.New("missing owner or scopes")
+	}
+
+	return nil
+}
+
+func (o *tokenOptions) toScopes() ([]dp.Scope, error) {
+	var scopes []dp.Scope
+	for _, v := range strings.Split(o.scopes, ",") {
+		s, err := dp.NewScope(strings.TrimSpace(v))
+		if err != nil {
+			return nil, fmt.Errorf("%s: %s", err.Error(), v)
+		}
+
+		scopes = append(scopes, s)
+	}
+
+	return scopes, nil
+}
+
+// runTokenCmd manages the static api tokens, usage:
+// token create --config-file=xxx --name=xxx --owner=xxx --scopes=read,generate [--valid-days=n]
+// token revoke --config-file=xxx --name=xxx
+func runTokenCmd(args []string) {
+	if len(args) == 0 || (args[0] != cmdTokenCreate && args[0] != cmdTokenRevoke) {
+		logrus.Errorf("usage: token %s|%s [options]", cmdTokenCreate, cmdTokenRevoke)
+
+		return
+	}
+
+	action := args[0]
+
+	var o tokenOptions
+	fs := flag.NewFlagSet(cmdToken, flag.ExitOnError)
+	fs.StringVar(&o.configFile, "config-file", "", "path to config file.")
+	fs.StringVar(&o.name, "name", "", "name of the token.")
+	fs.StringVar(&o.owner, "owner", "", "owner of the token, recorded in audit log.")
+	fs.StringVar(&o.scopes, "scopes", "", "scopes of the token, separated by comma.")
+	fs.IntVar(&o.validDays, "valid-days", 0, "valid days of the token, 0 means never expire.")
+	_ = fs.Parse(args[1:])
+
+	if err := o.Validate(action); err != nil {
+		logrus.Errorf("invalid options, err:%s", err.Error())
+
+		return
+	}
+
+	cfg, err := config.LoadConfig(o.configFile)
+	if err != nil {
+		logrus.Errorf("load config, err:%s", err.Error())
+
+		return
+	}
+
+	if err = postgres.Init(&cfg.Postgres); err != nil {
+		logrus.Errorf("init db failed, err:%s", err.Error())
+
+		return
+	}
+
+	m, err := newMigrator(cfg)
+	if err == nil {
+		err = m.Check()
+	}
+	if err != nil {
+		logrus.Errorf("check db schema failed, err:%s", err.Error())
+
+		return
+	}
+
+	authrepositoryimpl.Init(&cfg.Auth)
+
+	service := authapp.NewAuthService(
+		authrepositoryimpl.TokenInstance(), authrepositoryimpl.AuditInstance(), nil,
+	)
+
+	if action == cmdTokenRevoke {
+		if err = service.RevokeToken(o.name); err != nil {
+			logrus.Errorf("revoke token %s failed, err:%s", o.name, err.Error())
+		}
+
+		return
+	}
+
+	scopes, err := o.toScopes()
+	if err != nil {
+		logrus.Errorf("invalid scopes, err:%s", err.Error())
+
+		return
+	}
+
+	dto, err := service.CreateToken(authapp.CmdToCreateToken{
+		Name:      o.name,
+		Owner:     o.owner,
+		Scopes:    scopes,
+		ValidDays: o.validDays,
+	})
+	if err != nil {
+		logrus.Errorf("create token %s failed, err:%s", o.name, err.Error())
+
+		return
+	}
+
+	// the raw token is only shown once, only its hash is stored
+	fmt.Println(dto.Token)
+}
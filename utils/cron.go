@@ -0,0 +1,219 @@
+package utils
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var cronDescriptors = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+var (
+	monthNames = map[string]int{
+		"jan": 1, "feb": 2, "mar": 3, "apr": 4, "may": 5, "jun": 6,
+		"jul": 7, "aug": 8, "sep": 9, "oct": 10, "nov": 11, "dec": 12,
+	}
+
+	weekdayNames = map[string]int{
+		"sun": 0, "mon": 1, "tue": 2, "wed": 3, "thu": 4, "fri": 5, "sat": 6,
+	}
+)
+
+type cronField struct {
+	name  string
+	min   int
+	max   int
+	names map[string]int
+}
+
+var cronFields = [5]cronField{
+	{name: "minute", min: 0, max: 59},
+	{name: "hour", min: 0, max: 23},
+	{name: "day of month", min: 1, max: 31},
+	{name: "month", min: 1, max: 12, names: monthNames},
+	// 7 is also sunday
+	{name: "day of week", min: 0, max: 7, names: weekdayNames},
+}
+
+// Cron is a standard cron expression of 5 fields: minute, hour, day of month, month and day of week.
+// A field supports *, lists, ranges, steps and the names of months and weekdays.
+// The day matches if either day field matches when both of them are restricted, like the crontab.
+type Cron struct {
+	expr   string
+	fields [5]uint64
+	// the day of month or week is *
+	anyDOM bool
+	anyDOW bool
+}
+
+func ParseCron(expr string) (Cron, error) {
+	spec := strings.TrimSpace(expr)
+	if v, ok := cronDescriptors[strings.ToLower(spec)]; ok {
+		spec = v
+	}
+
+	items := strings.Fields(spec)
+	if len(items) != len(cronFields) {
+		return Cron{}, fmt.Errorf("invalid cron %q, expect 5 fields", expr)
+	}
+
+	c := Cron{expr: expr}
+	for i, item := range items {
+		bits, err := parseCronField(item, &cronFields[i])
+		if err != nil {
+			return Cron{}, fmt.Errorf("invalid cron %q, %s", expr, err.Error())
+		}
+
+		c.fields[i] = bits
+	}
+
+	// sunday is 0
+	if c.fields[4]&(1<<7) != 0 {
+		c.fields[4] |= 1
+	}
+
+	c.anyDOM = items[2] == "*"
+	c.anyDOW = items[4] == "*"
+
+	return c, nil
+}
+
+func (c Cron) String() string {
+	return c.expr
+}
+
+// Next returns the first time matched after t in the location of t,
+// it returns the zero time if there is none in 5 years, such as 0 0 30 2 *.
+func (c Cron) Next(t time.Time) time.Time {
+	loc := t.Location()
+	t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), 0, 0, loc).Add(time.Minute)
+
+	limit := t.Year() + 5
+
+	for t.Year() <= limit {
+		if !c.has(3, int(t.Month())) {
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, loc)
+
+			continue
+		}
+
+		if !c.isDayMatched(t) {
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, loc)
+
+			continue
+		}
+
+		if !c.has(1, t.Hour()) {
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, loc)
+
+			continue
+		}
+
+		if !c.has(0, t.Minute()) {
+			t = t.Add(time.Minute)
+
+			continue
+		}
+
+		return t
+	}
+
+	return time.Time{}
+}
+
+func (c Cron) has(field, v int) bool {
+	return c.fields[field]&(1<<uint(v)) != 0
+}
+
+func (c Cron) isDayMatched(t time.Time) bool {
+	dom := c.has(2, t.Day())
+	dow := c.has(4, int(t.Weekday()))
+
+	if c.anyDOM || c.anyDOW {
+		return dom && dow
+	}
+
+	return dom || dow
+}
+
+func parseCronField(s string, f *cronField) (uint64, error) {
+	var bits uint64
+	for _, part := range strings.Split(s, ",") {
+		b, err := parseCronPart(part, f)
+		if err != nil {
+			return 0, err
+		}
+
+		bits |= b
+	}
+
+	return bits, nil
+}
+
+// parseCronPart parses *, a, a-b, */n, a/n and a-b/n
+func parseCronPart(s string, f *cronField) (uint64, error) {
+	rng, step := s, 1
+	if i := strings.Index(s, "/"); i >= 0 {
+		n, err := strconv.Atoi(s[i+1:])
+		if err != nil || n <= 0 {
+			return 0, fmt.Errorf("invalid step of %s: %s", f.name, s)
+		}
+
+		rng, step = s[:i], n
+	}
+
+	begin, end := f.min, f.max
+	if rng != "*" {
+		var err error
+		if i := strings.Index(rng, "-"); i >= 0 {
+			if begin, err = f.value(rng[:i]); err != nil {
+				return 0, err
+			}
+
+			if end, err = f.value(rng[i+1:]); err != nil {
+				return 0, err
+			}
+		} else {
+			if begin, err = f.value(rng); err != nil {
+				return 0, err
+			}
+
+			// a/n means from a to the max
+			if step == 1 {
+				end = begin
+			}
+		}
+	}
+
+	if begin > end {
+		return 0, fmt.Errorf("invalid range of %s: %s", f.name, s)
+	}
+
+	var bits uint64
+	for v := begin; v <= end; v += step {
+		bits |= 1 << uint(v)
+	}
+
+	return bits, nil
+}
+
+func (f *cronField) value(s string) (int, error) {
+	if v, ok := f.names[strings.ToLower(s)]; ok {
+		return v, nil
+	}
+
+	v, err := strconv.Atoi(s)
+	if err != nil || v < f.min || v > f.max {
+		return 0, fmt.Errorf("invalid %s: %s", f.name, s)
+	}
+
+	return v, nil
+}
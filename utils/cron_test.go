@@ -0,0 +1,55 @@
+package utils
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCronNext(t *testing.T) {
+	// it is a monday
+	from := time.Date(2023, 10, 16, 10, 30, 20, 0, time.UTC)
+
+	cases := []struct {
+		expr string
+		want string
+	}{
+		{"* * * * *", "2023-10-16 10:31"},
+		{"*/15 * * * *", "2023-10-16 10:45"},
+		{"0 2 * * *", "2023-10-17 02:00"},
+		{"@daily", "2023-10-17 00:00"},
+		{"30 10 * * *", "2023-10-17 10:30"},
+		{"0 9-17/4 * * mon-fri", "2023-10-16 13:00"},
+		{"0 0 * * 7", "2023-10-22 00:00"},
+		{"0 0 1 jan *", "2024-01-01 00:00"},
+		{"0 0 29 2 *", "2024-02-29 00:00"},
+		// either of the day fields matches
+		{"0 0 1 * fri", "2023-10-20 00:00"},
+		{"0 12 17,20 * *", "2023-10-17 12:00"},
+	}
+
+	for _, c := range cases {
+		cron, err := ParseCron(c.expr)
+		if err != nil {
+			t.Fatalf("%s: %s", c.expr, err.Error())
+		}
+
+		if got := cron.Next(from).Format("2006-01-02 15:04"); got != c.want {
+			t.Errorf("%s: got %s, want %s", c.expr, got, c.want)
+		}
+	}
+
+	if cron, _ := ParseCron("0 0 30 2 *"); !cron.Next(from).IsZero() {
+		t.Error("expect no time for 30th of February")
+	}
+}
+
+func TestParseCronError(t *testing.T) {
+	for _, expr := range []string{
+		"", "* * * *", "60 * * * *", "* 24 * * *", "0 0 0 * *",
+		"*/0 * * * *", "5-1 * * * *", "0 0 * foo *", "@every 1h",
+	} {
+		if _, err := ParseCron(expr); err == nil {
+			t.Errorf("%q: expect error", expr)
+		}
+	}
+}
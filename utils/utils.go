@@ -31,3 +31,14 @@ func Date() string {
 func Year() int {
 	return time.Now().Year()
 }
+
+// Clock is the source of the current time, it is replaced with a fixed one to get reproducible output
+type Clock interface {
+	Now() time.Time
+}
+
+type SystemClock struct{}
+
+func (c SystemClock) Now() time.Time {
+	return time.Now()
+}